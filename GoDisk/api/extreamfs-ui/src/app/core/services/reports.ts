@@ -212,6 +212,11 @@ export interface JournalRow {
   path: string;
   content: string;
   date: string; 
+  uid?: number;
+  gid?: number;
+  user?: string;
+  group?: string;
+  status: 'ok' | 'error' | '';
 }
 
 
@@ -0,0 +1,345 @@
+// clientgen genera pkg/client/client_gen.go a partir del documento OpenAPI
+// que imprime `godisk -openapi`: un struct por esquema y un método por
+// operación.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Items      *schema            `json:"items"`
+	Properties map[string]*schema `json:"properties"`
+	Required   []string           `json:"required"`
+	Additional *schema            `json:"additionalProperties"`
+}
+
+type media struct {
+	Schema *schema `json:"schema"`
+}
+
+type param struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type operation struct {
+	OperationID string  `json:"operationId"`
+	Summary     string  `json:"summary"`
+	Parameters  []param `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]media `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]media `json:"content"`
+	} `json:"responses"`
+}
+
+type document struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+func main() {
+	in := flag.String("in", "openapi.json", "documento OpenAPI")
+	out := flag.String("out", "client_gen.go", "archivo Go a generar")
+	pkg := flag.String("pkg", "client", "paquete del archivo generado")
+	flag.Parse()
+
+	raw, err := os.ReadFile(*in)
+	if err != nil {
+		fail(err)
+	}
+	var doc document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		fail(fmt.Errorf("clientgen: %s: %w", *in, err))
+	}
+
+	g := &gen{imports: map[string]bool{"context": true}}
+	g.types(doc)
+	g.operations(doc)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by clientgen from %s; DO NOT EDIT.\n\npackage %s\n\nimport (\n", *in, *pkg)
+	var imps []string
+	for imp := range g.imports {
+		imps = append(imps, imp)
+	}
+	sort.Strings(imps)
+	for _, imp := range imps {
+		fmt.Fprintf(&src, "\t%q\n", imp)
+	}
+	src.WriteString(")\n")
+	src.Write(g.body.Bytes())
+
+	fmtd, err := format.Source(src.Bytes())
+	if err != nil {
+		fail(fmt.Errorf("clientgen: código generado inválido: %w", err))
+	}
+	if err := os.WriteFile(*out, fmtd, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+type gen struct {
+	body    bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *gen) p(format string, a ...any) { fmt.Fprintf(&g.body, format, a...) }
+
+func (g *gen) types(doc document) {
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		s := doc.Components.Schemas[name]
+		req := map[string]bool{}
+		for _, r := range s.Required {
+			req[r] = true
+		}
+		g.p("\ntype %s struct {\n", name)
+		for _, prop := range sortedKeys(s.Properties) {
+			tag := prop
+			if !req[prop] {
+				tag += ",omitempty"
+			}
+			g.p("\t%s %s `json:%q`\n", goName(prop), g.goType(s.Properties[prop], req[prop]), tag)
+		}
+		g.p("}\n")
+	}
+}
+
+func (g *gen) goType(s *schema, required bool) string {
+	if s == nil {
+		g.imports["encoding/json"] = true
+		return "json.RawMessage"
+	}
+	if s.Ref != "" {
+		name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+		if !required {
+			return "*" + name
+		}
+		return name
+	}
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "byte":
+			return "[]byte"
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items, true)
+	case "object":
+		if s.Additional != nil {
+			return "map[string]" + g.goType(s.Additional, true)
+		}
+	}
+	g.imports["encoding/json"] = true
+	return "json.RawMessage"
+}
+
+var pathParam = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func (g *gen) operations(doc document) {
+	for _, path := range sortedKeys(doc.Paths) {
+		for _, m := range methods {
+			if op := doc.Paths[path][m]; op != nil {
+				g.operation(path, strings.ToUpper(m), op)
+			}
+		}
+	}
+}
+
+func (g *gen) operation(path, method string, op *operation) {
+	name := goName(op.OperationID)
+
+	var args, query []string
+	var qparams []param
+	for _, prm := range op.Parameters {
+		switch prm.In {
+		case "path":
+			args = append(args, prm.Name+" string")
+		case "query":
+			qparams = append(qparams, prm)
+		}
+	}
+	if len(qparams) > 0 {
+		g.p("\n// %sQuery son los parámetros de query de %s.\ntype %sQuery struct {\n", name, name, name)
+		for _, prm := range qparams {
+			comment := ""
+			if prm.Description != "" {
+				comment = " // " + prm.Description
+			}
+			g.p("\t%s %s%s\n", goName(prm.Name), g.goType(prm.Schema, true), comment)
+			query = append(query, prm.Name)
+		}
+		g.p("}\n")
+		args = append(args, "q "+name+"Query")
+	}
+
+	bodyExpr, ctype := "nil", `""`
+	if op.RequestBody != nil {
+		for ct, md := range op.RequestBody.Content {
+			if ct == "application/json" {
+				args = append(args, "body "+g.goType(md.Schema, true))
+				bodyExpr, ctype = "rd", `"application/json"`
+			} else {
+				g.imports["io"] = true
+				args = append(args, "body io.Reader", "contentType string")
+				bodyExpr, ctype = "body", "contentType"
+			}
+		}
+	}
+
+	ret, streaming := "", false
+	for code, res := range op.Responses {
+		if code == "default" {
+			continue
+		}
+		for ct, md := range res.Content {
+			switch {
+			case ct == "text/event-stream":
+				g.imports["io"] = true
+				ret, streaming = "io.ReadCloser", true
+			case ct == "application/json" && md.Schema != nil:
+				ret = g.goType(md.Schema, true)
+			default:
+				ret = "[]byte"
+			}
+		}
+	}
+	if ret == "" {
+		ret = "[]byte"
+	}
+
+	g.p("\n// %s: %s %s\n", name, method, path)
+	if op.Summary != "" {
+		g.p("// %s.\n", strings.TrimSuffix(op.Summary, "."))
+	}
+	g.p("func (c *Client) %s(ctx context.Context", name)
+	for _, a := range args {
+		g.p(", %s", a)
+	}
+	g.p(") (%s, error) {\n", ret)
+	if !streaming {
+		g.p("\tvar out %s\n", ret)
+	}
+
+	if bodyExpr == "rd" {
+		g.p("\trd, err := jsonBody(body)\n\tif err != nil {\n\t\treturn out, err\n\t}\n")
+	}
+	qExpr := "nil"
+	if len(query) > 0 {
+		g.imports["net/url"] = true
+		qExpr = "v"
+		g.p("\tv := url.Values{}\n")
+		for _, prm := range qparams {
+			field := "q." + goName(prm.Name)
+			switch g.goType(prm.Schema, true) {
+			case "string":
+				g.p("\tif %s != \"\" {\n\t\tv.Set(%q, %s)\n\t}\n", field, prm.Name, field)
+			case "bool":
+				g.p("\tif %s {\n\t\tv.Set(%q, \"true\")\n\t}\n", field, prm.Name)
+			default:
+				g.imports["fmt"] = true
+				g.p("\tif %s != 0 {\n\t\tv.Set(%q, fmt.Sprint(%s))\n\t}\n", field, prm.Name, field)
+			}
+		}
+	}
+
+	// La ruta se arma escapando cada comodín.
+	names := pathParam.FindAllStringSubmatch(path, -1)
+	var segs []string
+	for i, part := range pathParam.Split(path, -1) {
+		if part != "" {
+			segs = append(segs, fmt.Sprintf("%q", part))
+		}
+		if i < len(names) {
+			g.imports["net/url"] = true
+			segs = append(segs, "url.PathEscape("+names[i][1]+")")
+		}
+	}
+
+	if streaming {
+		g.p("\treturn c.stream(ctx, %q, %s, %s)\n}\n", method, strings.Join(segs, " + "), qExpr)
+		return
+	}
+	decl := ":="
+	if bodyExpr == "rd" {
+		decl = "=" // err ya viene de jsonBody
+	}
+	g.p("\terr %s c.do(ctx, %q, %s, %s, %s, %s, &out)\n\treturn out, err\n}\n",
+		decl, method, strings.Join(segs, " + "), qExpr, bodyExpr, ctype)
+}
+
+// goName convierte un nombre JSON o un operationId en un identificador Go
+// exportado (id -> ID, diskPath -> DiskPath, bm_inode -> BmInode).
+func goName(s string) string {
+	var b strings.Builder
+	up := true
+	for _, r := range s {
+		if r == '_' || r == '-' || r == '.' {
+			up = true
+			continue
+		}
+		if up {
+			r = unicode.ToUpper(r)
+			up = false
+		}
+		b.WriteRune(r)
+	}
+	n := b.String()
+	for _, ini := range []string{"Id", "Ok", "Uid", "Gid", "Url", "Json", "Html"} {
+		if n == ini {
+			return strings.ToUpper(ini)
+		}
+		if strings.HasSuffix(n, ini) {
+			return strings.TrimSuffix(n, ini) + strings.ToUpper(ini)
+		}
+	}
+	return n
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,255 @@
+package apidoc
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// El documento se arma a partir de la misma tabla con la que runHTTP
+// registra las rutas, así que no puede quedar una ruta sin describir. Los
+// esquemas salen de los tipos Go por reflexión (etiquetas json).
+
+// Op describe una ruta de la API.
+type Op struct {
+	Method  string
+	Path    string // con comodines {x} como en http.ServeMux
+	ID      string // operationId; también es el nombre del método del cliente
+	Tag     string
+	Summary string
+	Query   []Param
+	Body    any    // valor cero del tipo del cuerpo JSON (nil = sin cuerpo)
+	BodyRaw string // tipo de contenido de un cuerpo que no es JSON
+	Resp    any    // valor cero del tipo de respuesta JSON
+	RespRaw string // tipo de contenido de una respuesta que no es JSON
+	Status  int    // código de éxito (200 si es 0)
+}
+
+// Param es un parámetro de query.
+type Param struct {
+	Name     string
+	Desc     string
+	Type     string // string (defecto) | integer | boolean
+	Required bool
+}
+
+var pathParam = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Spec devuelve el documento OpenAPI 3 de ops. errResp es el valor cero del
+// sobre de error que comparten todas las respuestas fallidas.
+func Spec(title, version string, ops []Op, errResp any) map[string]any {
+	g := &gen{schemas: map[string]any{}, names: map[reflect.Type]string{}}
+	errRef := g.schema(reflect.TypeOf(errResp))
+
+	paths := map[string]any{}
+	for _, op := range ops {
+		item, _ := paths[op.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = g.operation(op, errRef)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": title, "version": version},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+		},
+	}
+}
+
+func (g *gen) operation(op Op, errRef map[string]any) map[string]any {
+	var params []any
+	for _, m := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+		params = append(params, map[string]any{
+			"name": m[1], "in": "path", "required": true,
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, p := range op.Query {
+		typ := p.Type
+		if typ == "" {
+			typ = "string"
+		}
+		q := map[string]any{
+			"name": p.Name, "in": "query", "required": p.Required,
+			"schema": map[string]any{"type": typ},
+		}
+		if p.Desc != "" {
+			q["description"] = p.Desc
+		}
+		params = append(params, q)
+	}
+
+	out := map[string]any{
+		"operationId": op.ID,
+		"summary":     op.Summary,
+	}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+	switch {
+	case op.Body != nil:
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Body))}},
+		}
+	case op.BodyRaw != "":
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{op.BodyRaw: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok := map[string]any{"description": http.StatusText(status)}
+	switch {
+	case op.Resp != nil:
+		ok["content"] = map[string]any{"application/json": map[string]any{"schema": g.schema(reflect.TypeOf(op.Resp))}}
+	case op.RespRaw == "application/json":
+		ok["content"] = map[string]any{op.RespRaw: map[string]any{"schema": map[string]any{"type": "object"}}}
+	case op.RespRaw != "":
+		ok["content"] = map[string]any{op.RespRaw: map[string]any{"schema": map[string]any{"type": "string"}}}
+	}
+	out["responses"] = map[string]any{
+		strconv.Itoa(status): ok,
+		"default": map[string]any{
+			"description": "Error",
+			"content":     map[string]any{"application/json": map[string]any{"schema": errRef}},
+		},
+	}
+	return out
+}
+
+type gen struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *gen) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := g.name(t)
+		if _, done := g.schemas[name]; !done {
+			g.schemas[name] = map[string]any{} // corta la recursión
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+func (g *gen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	g.fields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out
+}
+
+// fields recorre los campos como lo hace encoding/json, incluyendo los
+// embebidos sin etiqueta.
+func (g *gen) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// name elige el nombre del esquema: el del tipo con la primera letra en
+// mayúscula, y con el paquete delante si ya lo usa otro tipo.
+func (g *gen) name(t reflect.Type) string {
+	if n, ok := g.names[t]; ok {
+		return n
+	}
+	n := upperFirst(t.Name())
+	for other, on := range g.names {
+		if on == n && other != t {
+			pkg := t.PkgPath()
+			n = upperFirst(pkg[strings.LastIndex(pkg, "/")+1:]) + n
+			break
+		}
+	}
+	g.names[t] = n
+	return n
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
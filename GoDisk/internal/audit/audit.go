@@ -0,0 +1,224 @@
+// Package audit guarda un registro append-only (JSON por línea) de cada
+// comando ejecutado y cada llamada a la API, sin importar el sistema de
+// archivos de la partición.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/catalog"
+)
+
+// Orígenes de un registro.
+const (
+	SourceCmd  = "cmd"  // línea de comando (CLI o /api/exec)
+	SourceHTTP = "http" // petición a la API
+)
+
+type Record struct {
+	Time       time.Time `json:"time"`
+	Source     string    `json:"source"`
+	User       string    `json:"user,omitempty"`
+	ID         string    `json:"id,omitempty"`
+	Command    string    `json:"command"`
+	Args       []string  `json:"args,omitempty"`
+	OK         bool      `json:"ok"`
+	Status     int       `json:"status,omitempty"` // sólo HTTP
+	Error      string    `json:"error,omitempty"`
+	DurationMS float64   `json:"durationMs"`
+}
+
+const redacted = "***"
+
+var (
+	mu        sync.Mutex
+	cachePath string
+)
+
+// Path es el archivo del registro: $GODISK_AUDIT, o audit.log junto al
+// catálogo de discos (~/.godisk por defecto).
+func Path() string {
+	if cachePath != "" {
+		return cachePath
+	}
+	if p := strings.TrimSpace(os.Getenv("GODISK_AUDIT")); p != "" {
+		cachePath = p
+	} else {
+		cachePath = filepath.Join(filepath.Dir(catalog.CurrentPath()), "audit.log")
+	}
+	return cachePath
+}
+
+// Append agrega rec al final del registro.
+func Append(rec Record) error {
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	p := Path()
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("audit: escribiendo %s: %w", p, err)
+	}
+	return nil
+}
+
+// sensitive dice si una bandera o parámetro lleva una contraseña.
+func sensitive(key string) bool {
+	key = strings.ToLower(strings.TrimLeft(key, "-"))
+	return key == "pwd" || strings.Contains(key, "pass")
+}
+
+// RedactArgs copia args (ya normalizados, "-clave=valor") ocultando los
+// valores de contraseñas y frases, también en la forma "-pass valor".
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		out[i] = a
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+		key, _, hasVal := strings.Cut(a, "=")
+		if !sensitive(key) {
+			continue
+		}
+		if hasVal {
+			out[i] = key + "=" + redacted
+		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			out[i] = redacted
+		}
+	}
+	return out
+}
+
+// RedactQuery devuelve los parámetros de q como "k=v", ordenados por clave
+// y con los valores sensibles ocultos.
+func RedactQuery(q url.Values) []string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			if sensitive(k) {
+				v = redacted
+			}
+			out = append(out, k+"="+v)
+		}
+	}
+	return out
+}
+
+// Filter selecciona registros; los campos vacíos no filtran.
+type Filter struct {
+	User    string
+	ID      string
+	Command string // prefijo, sin distinguir mayúsculas
+	Source  string
+	Status  string // ok | error
+	Since   time.Time
+	Until   time.Time
+	Limit   int // últimos N (0 = todos)
+}
+
+func (f Filter) match(r Record) bool {
+	switch {
+	case f.User != "" && !strings.EqualFold(f.User, r.User),
+		f.ID != "" && !strings.EqualFold(f.ID, r.ID),
+		f.Command != "" && !strings.HasPrefix(strings.ToLower(r.Command), strings.ToLower(f.Command)),
+		f.Source != "" && f.Source != r.Source,
+		f.Status == "ok" && !r.OK,
+		f.Status == "error" && r.OK,
+		!f.Since.IsZero() && r.Time.Before(f.Since),
+		!f.Until.IsZero() && r.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// Query lee el registro y devuelve, en orden cronológico, los registros
+// que cumplen f. Las líneas corruptas se saltan.
+func Query(f Filter) ([]Record, error) {
+	switch f.Status {
+	case "", "ok", "error":
+	default:
+		return nil, fmt.Errorf("audit: status debe ser ok|error, no %q", f.Status)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	file, err := os.Open(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	defer file.Close()
+
+	out := []Record{}
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var r Record
+		if json.Unmarshal(sc.Bytes(), &r) != nil {
+			continue
+		}
+		if f.match(r) {
+			out = append(out, r)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("audit: leyendo %s: %w", Path(), err)
+	}
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[len(out)-f.Limit:]
+	}
+	return out, nil
+}
+
+// ParseTime acepta RFC 3339 o una fecha local "2006-01-02" con hora
+// opcional ("2006-01-02 15:04[:05]", también con T). "" es el tiempo cero
+// (sin filtro). La usan audit -since/-until y recovery -until.
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("fecha inválida %q (RFC 3339 o AAAA-MM-DD [hh:mm[:ss]])", s)
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+// audit -since/-until y recovery -until aceptan los mismos formatos.
+func TestParseTime(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"", time.Time{}, true},
+		{"2025-10-01T12:00:00Z", time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC), true},
+		{"2025-10-01T12:00:00-06:00", time.Date(2025, 10, 1, 18, 0, 0, 0, time.UTC), true},
+		{"2025-10-01T12:00:30", time.Date(2025, 10, 1, 12, 0, 30, 0, time.Local), true},
+		{" 2025-10-01 12:00:30 ", time.Date(2025, 10, 1, 12, 0, 30, 0, time.Local), true},
+		{"2025-10-01T12:00", time.Date(2025, 10, 1, 12, 0, 0, 0, time.Local), true},
+		{"2025-10-01 12:00", time.Date(2025, 10, 1, 12, 0, 0, 0, time.Local), true},
+		{"2025-10-01", time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local), true},
+		{"01/10/2025", time.Time{}, false},
+		{"ayer", time.Time{}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got, err := ParseTime(c.in)
+			if (err == nil) != c.ok {
+				t.Fatalf("ParseTime(%q) err = %v", c.in, err)
+			}
+			if c.ok && !got.Equal(c.want) {
+				t.Fatalf("ParseTime(%q) = %v, se esperaba %v", c.in, got, c.want)
+			}
+		})
+	}
+}
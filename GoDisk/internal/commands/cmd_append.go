@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/usersvc"
+)
+
+func CmdAppend(reg *mount.Registry, argv []string) int {
+	cmd := flag.NewFlagSet("append", flag.ContinueOnError)
+	path := cmd.String("path", "", "Ruta absoluta en EXT2/EXT3 (ej. /docs/nota.txt)")
+	cont := cmd.String("cont", "", "Texto literal o ruta de archivo del SO")
+	text := cmd.String("text", "", "Texto literal; nunca se lee del SO (excluye -cont)")
+	if err := cmd.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*path) == "" || (*cont == "") == (*text == "") {
+		fmt.Println("uso: append -path=/ruta/archivo -cont=\"texto\"|/ruta/host | -text=\"texto\"")
+		return 2
+	}
+	var err error
+	if *text != "" {
+		err = usersvc.AppendText(reg, *path, *text)
+	} else {
+		err = usersvc.Append(reg, *path, *cont)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	fmt.Printf("append: agregado a %s\n", *path)
+	return 0
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/audit"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/usersvc"
+)
+
+func CmdAudit(argv []string) int {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	user := fs.String("user", "", "Solo registros de este usuario")
+	id := fs.String("id", "", "Solo registros de esta partición montada")
+	cmdName := fs.String("cmd", "", "Prefijo del comando (p. ej. mk, \"POST /api/fs\")")
+	source := fs.String("source", "", "Origen: cmd|http")
+	status := fs.String("status", "", "Resultado: ok|error")
+	since := fs.String("since", "", "Desde (RFC 3339 o AAAA-MM-DD [hh:mm])")
+	until := fs.String("until", "", "Hasta (RFC 3339 o AAAA-MM-DD [hh:mm])")
+	limit := fs.Int("limit", 50, "Últimos N registros (0 = todos)")
+	if err := fs.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+
+	f := audit.Filter{
+		User:    strings.TrimSpace(*user),
+		ID:      strings.TrimSpace(*id),
+		Command: strings.TrimSpace(*cmdName),
+		Source:  strings.ToLower(strings.TrimSpace(*source)),
+		Status:  strings.ToLower(strings.TrimSpace(*status)),
+		Limit:   *limit,
+	}
+	var err error
+	if f.Since, err = audit.ParseTime(*since); err != nil {
+		fmt.Println("Error: audit: -since:", err)
+		return 1
+	}
+	if f.Until, err = audit.ParseTime(*until); err != nil {
+		fmt.Println("Error: audit: -until:", err)
+		return 1
+	}
+
+	recs, err := usersvc.Audit(f)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if len(recs) == 0 {
+		fmt.Println("(sin registros)")
+		return 0
+	}
+	for _, r := range recs {
+		res := "ok"
+		if !r.OK {
+			res = "ERROR"
+		}
+		fmt.Printf("%s  %-4s  %-8s  %-6s  %-5s  %8.1fms  %s",
+			r.Time.Local().Format("2006-01-02 15:04:05"), r.Source, dash(r.User), dash(r.ID), res, r.DurationMS, r.Command)
+		if len(r.Args) > 0 {
+			fmt.Print(" ", strings.Join(r.Args, " "))
+		}
+		if r.Error != "" {
+			fmt.Printf("  -> %s", r.Error)
+		}
+		fmt.Println()
+	}
+	return 0
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/usersvc"
+)
+
+func CmdChattr(reg *mount.Registry, argv []string) int {
+	cmd := flag.NewFlagSet("chattr", flag.ContinueOnError)
+	path := cmd.String("path", "", "Ruta absoluta del archivo en EXT2/EXT3")
+	compress := cmd.Bool("compress", true, "Guardar comprimido (-compress=false para descomprimir)")
+	if err := cmd.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*path) == "" {
+		fmt.Println("uso: chattr -path=/ruta/archivo [-compress|-compress=false]")
+		return 2
+	}
+	if err := usersvc.Chattr(reg, *path, *compress); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	estado := "comprimido"
+	if !*compress {
+		estado = "sin comprimir"
+	}
+	fmt.Printf("chattr: %s queda %s\n", *path, estado)
+	return 0
+}
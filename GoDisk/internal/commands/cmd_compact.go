@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+)
+
+// CmdCompact perfora huecos en los tramos en cero del disco para que el
+// archivo vuelva a ocupar en el host sólo lo que tiene datos.
+func CmdCompact(argv []string) int {
+	fs := flag.NewFlagSet("compact", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	path := fs.String("path", "", "Ruta del disco (.mia)")
+
+	if err := fs.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*path) == "" {
+		fmt.Println("Error: compact: -path es obligatorio")
+		return 1
+	}
+	if _, err := os.Stat(*path); err != nil {
+		fmt.Printf("Error: compact: disco no existe: %s\n", *path)
+		return 1
+	}
+
+	res, err := diskio.Compact(*path)
+	if err != nil {
+		fmt.Println("Error: compact:", err)
+		return 1
+	}
+	fmt.Printf("compact: %s — lógico %d bytes, reservado %d -> %d bytes (perforados %d)\n",
+		*path, res.LogicalBytes, res.Before, res.After, res.Punched)
+	return 0
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+func CmdDefrag(reg *mount.Registry, argv []string) int {
+	fs := flag.NewFlagSet("defrag", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	id := fs.String("id", "", "ID montado (generado por mount)")
+	path := fs.String("path", "", "Carpeta o archivo a desfragmentar (por defecto '/')")
+
+	if err := fs.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*id) == "" {
+		fmt.Println("uso: defrag -id=<ID> [-path=/ruta]")
+		return 2
+	}
+	if p := strings.TrimSpace(*path); p != "" && !strings.HasPrefix(p, "/") {
+		fmt.Println("defrag: -path inválido (debe ser absoluto)")
+		return 2
+	}
+
+	rep, err := ext3.Defrag(reg, *id, *path)
+	if rep.Resumed {
+		fmt.Printf("defrag: reanudando %s desde el inodo %d\n", rep.Root, rep.FromIno+1)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+
+	fmt.Printf("defrag: completado en %s (%s)\n", *id, rep.Root)
+	fmt.Printf("Nodos: %d | bloques movidos: %d | bloques liberados: %d\n", rep.Nodes, rep.Moved, rep.Freed)
+	if len(rep.NoSpace) > 0 {
+		fmt.Println("Sin espacio contiguo (quedaron igual):")
+		for _, p := range rep.NoSpace {
+			fmt.Println("-", p)
+		}
+	}
+	return 0
+}
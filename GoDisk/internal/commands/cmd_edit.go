@@ -13,15 +13,22 @@ func CmdEdit(reg *mount.Registry, argv []string) int {
 	cmd := flag.NewFlagSet("edit", flag.ContinueOnError)
 	path := cmd.String("path", "", "Ruta absoluta en EXT2/EXT3 (ej. /docs/nota.txt)")
 	cont := cmd.String("cont", "", "Texto literal o ruta de archivo del SO")
+	text := cmd.String("text", "", "Texto literal; nunca se lee del SO (excluye -cont)")
 	if err := cmd.Parse(argv); err != nil {
 		fmt.Println("Error:", err)
 		return 1
 	}
-	if strings.TrimSpace(*path) == "" {
-		fmt.Println("uso: edit -path=/ruta/archivo [-cont=\"texto\"|/ruta/host]")
+	if strings.TrimSpace(*path) == "" || (*cont != "" && *text != "") {
+		fmt.Println("uso: edit -path=/ruta/archivo [-cont=\"texto\"|/ruta/host | -text=\"texto\"]")
 		return 2
 	}
-	if err := usersvc.Edit(reg, *path, *cont); err != nil {
+	var err error
+	if *text != "" {
+		err = usersvc.EditText(reg, *path, *text)
+	} else {
+		err = usersvc.Edit(reg, *path, *cont)
+	}
+	if err != nil {
 		fmt.Println("Error:", err)
 		return 1
 	}
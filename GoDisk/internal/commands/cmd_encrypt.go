@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+)
+
+// ExecuteEncrypt cifra el disco en su lugar (ver diskio.EncryptDisk); las
+// particiones montadas siguen funcionando porque la clave queda registrada.
+func ExecuteEncrypt(path, pass string) error {
+	if strings.TrimSpace(pass) == "" {
+		return errors.New("encrypt: -passphrase es obligatorio")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("encrypt: disco no existe: %s", path)
+	}
+	if err := diskio.EncryptDisk(path, pass); err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+	return nil
+}
+
+// UnlockDisk registra la frase de un disco cifrado antes de usarlo; sin
+// frase no hace nada.
+func UnlockDisk(op, path, pass string) error {
+	if pass == "" {
+		return nil
+	}
+	if err := diskio.Unlock(path, pass); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func CmdEncrypt(argv []string) int {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	path := fs.String("path", "", "Ruta del disco (.mia)")
+	pass := fs.String("passphrase", "", "Frase para derivar la clave")
+
+	if err := fs.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*path) == "" {
+		fmt.Println("uso: encrypt -path=<disco.mia> -passphrase=<frase>")
+		return 2
+	}
+	if err := ExecuteEncrypt(*path, *pass); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	fmt.Println("encrypt: disco cifrado:", *path)
+	return 0
+}
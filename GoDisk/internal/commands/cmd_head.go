@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/usersvc"
+)
+
+// CmdHead y CmdTail muestran los primeros / últimos -n bytes de un archivo
+// leyendo sólo los bloques que hacen falta.
+func CmdHead(reg *mount.Registry, argv []string) int {
+	return cmdHeadTail(reg, "head", usersvc.Head, argv)
+}
+
+func CmdTail(reg *mount.Registry, argv []string) int {
+	return cmdHeadTail(reg, "tail", usersvc.Tail, argv)
+}
+
+func cmdHeadTail(reg *mount.Registry, name string, read func(*mount.Registry, string, int) ([]byte, error), argv []string) int {
+	cmd := flag.NewFlagSet(name, flag.ContinueOnError)
+	path := cmd.String("path", "", "Ruta absoluta del archivo en EXT2/EXT3")
+	n := cmd.Int("n", 64, "Cantidad de bytes")
+	if err := cmd.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*path) == "" {
+		fmt.Printf("uso: %s -path=/ruta/archivo [-n=bytes]\n", name)
+		return 2
+	}
+	out, err := read(reg, *path, *n)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
@@ -15,10 +15,21 @@ func CmdJournaling(reg *mount.Registry, argv []string) int {
 	fs.SetOutput(io.Discard)
 	id := fs.String("id", "", "ID de partición montada (opcional; si omites y tienes sesión activa, se usa esa partición)")
 
+	export := fs.String("export", "", "Archivo del SO donde escribir el journal como script de comandos (.smia)")
+
 	if err := fs.Parse(argv); err != nil {
 		fmt.Println("Error:", err)
 		return 1
 	}
+	if dest := strings.TrimSpace(*export); dest != "" {
+		n, err := usersvc.JournalingExport(reg, strings.TrimSpace(*id), dest)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return 1
+		}
+		fmt.Printf("journaling: %d entradas exportadas a %s\n", n, dest)
+		return 0
+	}
 	jsonStr, err := usersvc.JournalingJSON(reg, strings.TrimSpace(*id))
 	if err != nil {
 		fmt.Println("Error:", err)
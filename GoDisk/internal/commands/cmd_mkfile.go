@@ -17,6 +17,7 @@ func CmdMkfile(reg *mount.Registry, argv []string) int {
 	sizeU := cmd.Uint("size", 0, "Tamaño (bytes, no negativos) si no se usa -cont")
 	cont := cmd.String("cont", "", "Ruta de archivo de texto en el SO; tiene prioridad sobre -size")
 	force := cmd.Bool("force", false, "Sobrescribir si el archivo ya existe (sin preguntar)")
+	compress := cmd.Bool("compress", false, "Guardar el contenido comprimido (DEFLATE)")
 
 	if err := cmd.Parse(argv); err != nil {
 		fmt.Println("Error:", err)
@@ -24,13 +25,13 @@ func CmdMkfile(reg *mount.Registry, argv []string) int {
 	}
 
 	if strings.TrimSpace(*path) == "" {
-		fmt.Println("uso: mkfile -path=/ruta/archivo [-r] [-size=N] [-cont=/ruta/host] [-force]")
+		fmt.Println("uso: mkfile -path=/ruta/archivo [-r] [-size=N] [-cont=/ruta/host] [-force] [-compress]")
 		return 2
 	}
 
 	size := int(*sizeU)
 
-	if err := usersvc.Mkfile(reg, *path, *recursive, size, *cont, *force); err != nil {
+	if err := usersvc.Mkfile(reg, *path, *recursive, size, *cont, *force, *compress); err != nil {
 		fmt.Println("Error:", err)
 		return 1
 	}
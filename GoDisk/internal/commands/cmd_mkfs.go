@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
@@ -10,34 +12,80 @@ import (
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
-func CmdMkfs(reg *mount.Registry, argv []string) int {
-	cmd := flag.NewFlagSet("mkfs", flag.ExitOnError)
-	id := cmd.String("id", "", "ID montado (generado por mount)")
-	typ := cmd.String("type", "full", "Tipo de formateo (full)")
-	fstype := cmd.String("fs", "ext2", "Sistema de archivos: ext2|ext3")
-	cmd.Parse(argv)
+// MkfsRequest es un formateo ya validado. Lo arman ParseMkfs (consola) y
+// NewMkfsRequest (API) y lo ejecuta Mkfs.
+type MkfsRequest struct {
+	ID   string
+	FS   string // "ext2" | "ext3"
+	Opts ext2.MkfsOptions
+}
 
-	if *id == "" {
-		fmt.Println("Error: -id es obligatorio.")
-		return 2
+// NewMkfsRequest valida fs y bitmap (vacíos = ext2 y byte).
+func NewMkfsRequest(id, fs, bitmap string, backup, checksum, cow bool) (MkfsRequest, error) {
+	req := MkfsRequest{
+		ID:   strings.TrimSpace(id),
+		Opts: ext2.MkfsOptions{BackupSuperblocks: backup, Checksums: checksum, Refcounts: cow},
 	}
-	if strings.ToLower(*typ) != "full" {
-		fmt.Println("Aviso: solo -type=full.")
+	if req.ID == "" {
+		return req, errors.New("mkfs: -id es obligatorio")
 	}
-
-	switch strings.ToLower(*fstype) {
+	switch strings.ToLower(strings.TrimSpace(fs)) {
+	case "ext2", "":
+		req.FS = "ext2"
 	case "ext3":
-		if err := ext3.NewFormatter(reg).MkfsFull(*id); err != nil {
-			fmt.Println("Error:", err)
-			return 1
-		}
-		fmt.Println("mkfs: formateo EXT3 completado en", *id)
+		req.FS = "ext3"
+	default:
+		return req, errors.New("mkfs: fs debe ser ext2|ext3")
+	}
+	switch strings.ToLower(strings.TrimSpace(bitmap)) {
+	case "byte", "":
+	case "packed", "bit", "bits":
+		req.Opts.PackedBitmaps = true
 	default:
-		if err := ext2.NewFormatter(reg).MkfsFull(*id); err != nil {
-			fmt.Println("Error:", err)
-			return 1
-		}
-		fmt.Println("mkfs: formateo EXT2 completado en", *id)
+		return req, errors.New("mkfs: bitmap debe ser byte|packed")
+	}
+	return req, nil
+}
+
+// ParseMkfs lee los flags de la línea mkfs.
+func ParseMkfs(argv []string) (MkfsRequest, error) {
+	fs := flag.NewFlagSet("mkfs", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	id := fs.String("id", "", "ID de partición montada (p.ej. 39A1)")
+	typ := fs.String("type", "full", "Tipo de formateo (solo 'full')")
+	fstype := fs.String("fs", "ext2", "Sistema de archivos: ext2|ext3 (default ext2)")
+	bitmap := fs.String("bitmap", "byte", "Formato de bitmaps en disco: byte|packed")
+	backup := fs.Bool("backup", true, "Guarda copias de respaldo del superbloque")
+	checksum := fs.Bool("checksum", false, "Guarda CRC32C de cada inodo y bloque")
+	cow := fs.Bool("cow", true, "copy comparte bloques (copy-on-write con referencias por bloque)")
+	if err := fs.Parse(argv); err != nil {
+		return MkfsRequest{}, err
+	}
+	if strings.ToLower(strings.TrimSpace(*typ)) != "full" {
+		fmt.Println("Aviso: solo se implementa -type=full; se usará full.")
+	}
+	return NewMkfsRequest(*id, *fstype, *bitmap, *backup, *checksum, *cow)
+}
+
+// Mkfs formatea req.ID con el sistema de archivos pedido.
+func Mkfs(reg *mount.Registry, req MkfsRequest) error {
+	if req.FS == "ext3" {
+		return ext3.NewFormatter(reg).MkfsWith(req.ID, req.Opts)
+	}
+	return ext2.NewFormatter(reg).MkfsWith(req.ID, req.Opts)
+}
+
+func CmdMkfs(reg *mount.Registry, argv []string) int {
+	req, err := ParseMkfs(argv)
+	if err != nil {
+		fmt.Println("Error:", err)
+		fmt.Println("uso: mkfs -id=<ID> [-type=full] [-fs=ext2|ext3] [-bitmap=byte|packed] [-backup=true|false] [-checksum] [-cow=true|false]")
+		return 1
+	}
+	if err := Mkfs(reg, req); err != nil {
+		fmt.Println("Error:", err)
+		return 1
 	}
+	fmt.Printf("mkfs: formateo %s completado en %s\n", strings.ToUpper(req.FS), req.ID)
 	return 0
 }
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/audit"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
@@ -15,25 +16,54 @@ func CmdRecovery(reg *mount.Registry, argv []string) int {
 	fs := flag.NewFlagSet("recovery", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	id := fs.String("id", "", "ID montado (generado por mount)")
+	src := fs.String("src", "", "ID EXT3 cuyo journal se re-aplica sobre -dest")
+	dest := fs.String("dest", "", "ID EXT2/EXT3 destino (no se re-formatea)")
+	until := fs.String("until", "", "Re-aplica solo entradas hasta esta fecha (2006-01-02T15:04:05, hora local, o RFC 3339)")
+	upto := fs.Int("upto", 0, "Re-aplica solo entradas con JCount <= upto")
+	dryRun := fs.Bool("dry-run", false, "Solo reporta lo que se re-aplicaría; no toca el disco")
 
 	if err := fs.Parse(argv); err != nil {
 		fmt.Println("Error:", err)
 		return 1
 	}
-	if strings.TrimSpace(*id) == "" {
-		fmt.Println("uso: recovery -id=<ID>")
+	*id, *src, *dest = strings.TrimSpace(*id), strings.TrimSpace(*src), strings.TrimSpace(*dest)
+	cross := *src != "" || *dest != ""
+	if cross == (*id != "") || (cross && (*src == "" || *dest == "")) {
+		fmt.Println("uso: recovery -id=<ID> | -src=<ID> -dest=<ID> [-until=<fecha>] [-upto=<JCount>] [-dry-run]")
 		return 2
 	}
+	if *upto < 0 {
+		fmt.Println("Error: recovery: -upto no puede ser negativo")
+		return 1
+	}
+	opts := ext3.RecoverOptions{UpTo: int32(*upto), DryRun: *dryRun}
+	var err error
+	if opts.Until, err = audit.ParseTime(*until); err != nil {
+		fmt.Println("Error: recovery: -until:", err)
+		return 1
+	}
 
-	rep, err := ext3.RecoverWithReport(reg, *id)
+	var rep ext3.ReplayReport
+	if cross {
+		rep, err = ext3.ReplayOnto(reg, *src, *dest, opts)
+	} else {
+		rep, err = ext3.RecoverWithOptions(reg, *id, opts)
+	}
 	if err != nil {
 		fmt.Println("Error:", err)
 		return 1
 	}
 
-	fmt.Println("recovery: completado")
+	if rep.DryRun {
+		fmt.Println("recovery: simulación (-dry-run); el disco no se modificó")
+	} else {
+		fmt.Println("recovery: completado")
+	}
 	fmt.Printf("Procesadas: %d | aplicadas: %d | omitidas: %d | errores: %d\n",
 		rep.Total, rep.Applied, rep.Skipped, rep.Failed)
+	if rep.Excluded > 0 {
+		fmt.Printf("Descartadas por el corte: %d\n", rep.Excluded)
+	}
 
 	// Imprimir ByOp ordenado por clave
 	if len(rep.ByOp) > 0 {
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/rescue"
+)
+
+// CmdRescue busca superbloques y EBRs en un disco con el MBR dañado y
+// muestra la tabla que se podría reconstruir; con -apply la escribe
+// (si la tabla actual se lee bien, además hace falta -force).
+// Devuelve la ruta del disco si se aplicó (para registrarlo en el catálogo).
+func CmdRescue(argv []string) (string, int) {
+	fs := flag.NewFlagSet("rescue", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	path := fs.String("path", "", "Ruta del disco (.mia)")
+	apply := fs.Bool("apply", false, "Escribe la tabla propuesta")
+	force := fs.Bool("force", false, "Con -apply, reemplaza también una tabla que se lee bien")
+	pass := fs.String("passphrase", "", "Frase del disco si está cifrado")
+
+	if err := fs.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return "", 1
+	}
+	if strings.TrimSpace(*path) == "" {
+		fmt.Println("Error: rescue: -path es obligatorio")
+		return "", 1
+	}
+
+	if err := UnlockDisk("rescue", *path, *pass); err != nil {
+		fmt.Println("Error:", err)
+		return "", 1
+	}
+	p, err := rescue.Scan(*path)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return "", 1
+	}
+
+	if p.TableOK {
+		fmt.Printf("rescue: la tabla actual (%s) se lee bien\n", p.Table)
+	} else {
+		fmt.Printf("rescue: tabla actual dañada: %s\n", p.TableErr)
+	}
+	fmt.Printf("rescue: %d hallazgo(s) en %s (%d bytes)\n", len(p.Found), p.DiskPath, p.DiskSize)
+	for _, f := range p.Found {
+		fmt.Printf("  %-5s @%-10d %10d bytes  %s %s\n", f.Kind, f.Offset, f.Size, f.Name, f.Detail)
+	}
+	for _, w := range p.Warnings {
+		fmt.Println("  WARN:", w)
+	}
+	if len(p.Parts) == 0 {
+		fmt.Println("rescue: no hay nada que reconstruir")
+		return "", 1
+	}
+
+	fmt.Printf("Tabla propuesta (%s):\n", strings.ToUpper(p.Table))
+	for _, pt := range p.Parts {
+		fmt.Printf("  %c %-16s inicio=%-10d tamaño=%-10d %s\n", pt.Type, pt.Name, pt.Start, pt.Size, pt.FS)
+	}
+
+	if !*apply {
+		fmt.Println("rescue: nada escrito; repetir con -apply para escribir la tabla")
+		return "", 0
+	}
+	if err := rescue.Apply(p, *force); err != nil {
+		fmt.Println("Error: rescue:", err)
+		return "", 1
+	}
+	fmt.Println("rescue: tabla escrita")
+	return p.DiskPath, 0
+}
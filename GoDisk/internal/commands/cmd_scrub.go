@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+func CmdScrub(reg *mount.Registry, argv []string) int {
+	fs := flag.NewFlagSet("scrub", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	id := fs.String("id", "", "ID montado (generado por mount)")
+
+	if err := fs.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*id) == "" {
+		fmt.Println("uso: scrub -id=<ID>")
+		return 2
+	}
+
+	rep, err := ext2.Scrub(reg, *id)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	fmt.Printf("scrub: %s verificado (inodos: %d | bloques: %d)\n", *id, rep.Inodes, rep.Blocks)
+	if len(rep.Issues) == 0 {
+		fmt.Println("Sin errores de checksum.")
+		return 0
+	}
+	fmt.Printf("%d estructura(s) corrupta(s):\n", len(rep.Issues))
+	for _, is := range rep.Issues {
+		owner := is.Path
+		if owner == "" {
+			owner = "(sin ruta)"
+		}
+		fmt.Printf("- %s: %s %d (checksum %08x, calculado %08x)\n", owner, is.Kind, is.Index, is.Stored, is.Computed)
+	}
+	return 1
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// CmdSync escribe la caché de páginas y hace fsync; sin -id aplica a todos
+// los discos abiertos.
+func CmdSync(reg *mount.Registry, argv []string) int {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	id := fs.String("id", "", "ID montado (opcional)")
+
+	if err := fs.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+
+	if strings.TrimSpace(*id) == "" {
+		if err := diskio.SyncAll(); err != nil {
+			fmt.Println("Error: sync:", err)
+			return 1
+		}
+		fmt.Println("sync: cachés escritas en todos los discos")
+		return 0
+	}
+
+	mp, ok := reg.GetByID(*id)
+	if !ok {
+		fmt.Printf("Error: sync: id %s no está montado\n", *id)
+		return 1
+	}
+	if err := diskio.Sync(mp.DiskPath); err != nil {
+		fmt.Println("Error: sync:", err)
+		return 1
+	}
+	fmt.Printf("sync: caché escrita en %s (%s)\n", *id, mp.DiskPath)
+	return 0
+}
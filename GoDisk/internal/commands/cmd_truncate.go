@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/usersvc"
+)
+
+func CmdTruncate(reg *mount.Registry, argv []string) int {
+	cmd := flag.NewFlagSet("truncate", flag.ContinueOnError)
+	path := cmd.String("path", "", "Ruta absoluta en EXT2/EXT3 (ej. /docs/nota.txt)")
+	size := cmd.Int64("size", -1, "Tamaño final en bytes")
+	if err := cmd.Parse(argv); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if strings.TrimSpace(*path) == "" || *size < 0 {
+		fmt.Println("uso: truncate -path=/ruta/archivo -size=N")
+		return 2
+	}
+	if err := usersvc.Truncate(reg, *path, *size); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	fmt.Printf("truncate: %s queda en %d bytes\n", *path, *size)
+	return 0
+}
@@ -9,10 +9,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
 )
 
-func ExecuteMkdisk(size int, unit, fit, path string) error {
+// ExecuteMkdisk crea el disco; table elige la tabla de particiones: "mbr"
+// (por defecto) o "gpt" (MBR protector + GPT con copia de respaldo).
+func ExecuteMkdisk(size int, unit, fit, path, table string) error {
 	u := strings.ToUpper(strings.TrimSpace(unit))
 	var diskSize int64
 	switch u {
@@ -40,6 +43,15 @@ func ExecuteMkdisk(size int, unit, fit, path string) error {
 		return fmt.Errorf("valor '%s' no válido para -fit (use BF, FF o WF)", fit)
 	}
 
+	table = strings.ToLower(strings.TrimSpace(table))
+	switch table {
+	case "", "mbr":
+		table = "mbr"
+	case "gpt":
+	default:
+		return fmt.Errorf("valor '%s' no válido para -table (use mbr o gpt)", table)
+	}
+
 	path = strings.TrimSpace(path)
 	if path == "" {
 		return fmt.Errorf("el parámetro -path es obligatorio")
@@ -54,6 +66,15 @@ func ExecuteMkdisk(size int, unit, fit, path string) error {
 
 	rand.Seed(time.Now().UnixNano())
 	mbr := structs.NewMBR(diskSize, fitByte, rand.Int63())
+	var gpt diskio.GPT
+	if table == "gpt" {
+		g, err := diskio.NewGPT(diskSize)
+		if err != nil {
+			return err
+		}
+		gpt = g
+		diskio.ProtectiveMBR(&mbr)
+	}
 
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("crear directorios: %w", err)
@@ -63,12 +84,16 @@ func ExecuteMkdisk(size int, unit, fit, path string) error {
 	if err != nil {
 		return fmt.Errorf("crear archivo: %w", err)
 	}
+	// Un descriptor cacheado de un archivo previo con la misma ruta ya no sirve.
+	diskio.CloseHandle(path)
 	cleanup := func(e error) error {
 		_ = fh.Close()
 		_ = os.Remove(path)
 		return e
 	}
 
+	// Truncate deja el archivo disperso: el host sólo reserva los bloques que
+	// se escriben (MBR, tablas y lo que use el sistema de archivos).
 	if err := fh.Truncate(diskSize); err != nil {
 		return cleanup(fmt.Errorf("ajustar tamaño: %w", err))
 	}
@@ -83,5 +108,14 @@ func ExecuteMkdisk(size int, unit, fit, path string) error {
 	if err := fh.Close(); err != nil {
 		return fmt.Errorf("cerrando archivo: %w", err)
 	}
+
+	if table == "gpt" {
+		if err := diskio.WriteGPT(path, gpt); err != nil {
+			diskio.CloseHandle(path)
+			_ = os.Remove(path)
+			return err
+		}
+		return diskio.Flush(path)
+	}
 	return nil
 }
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
 )
 
 // ExecuteRmdisk elimina el archivo .mia de forma no interactiva.
@@ -18,6 +20,11 @@ func ExecuteRmdisk(path string) bool {
 		return false
 	}
 	ap := filepath.Clean(path)
+	if !strings.HasSuffix(strings.ToLower(ap), ".mia") {
+		fmt.Printf("rmdisk: %q no es un disco .mia\n", ap)
+		return false
+	}
+	diskio.CloseHandle(ap)
 
 	if err := os.Remove(ap); err != nil {
 		if os.IsNotExist(err) {
@@ -0,0 +1,265 @@
+package diskio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Caché de páginas write-back por disco. Todas las lecturas/escrituras de
+// ext2, ext3 y reports pasan por aquí; las páginas sucias se escriben al
+// archivo con Flush (al terminar cada comando) o Sync (además hace fsync).
+//
+// Quien escriba el .mia por fuera de diskio (fdisk, mkdisk) debe llamar a
+// Invalidate antes y después para no mezclar datos viejos.
+
+const (
+	pageSize     = 4096
+	maxDiskPages = 4096 // 16 MiB por disco; al excederse se vacía la caché
+)
+
+type page struct {
+	data  []byte
+	n     int // bytes válidos (menor que pageSize solo en la última página)
+	dirty bool
+}
+
+type pageCache struct {
+	mu    sync.Mutex
+	pages map[int64]*page
+	crypt *diskCrypt // nil si el disco no está cifrado (ver crypt.go)
+}
+
+func newPageCache() *pageCache {
+	return &pageCache{pages: make(map[int64]*page)}
+}
+
+// get devuelve la página idx; si fill, la carga desde el archivo.
+func (c *pageCache) get(f *os.File, idx int64, fill bool) (*page, error) {
+	if p, ok := c.pages[idx]; ok {
+		return p, nil
+	}
+	p := &page{data: make([]byte, pageSize)}
+	if fill {
+		got, err := f.ReadAt(p.data, idx*pageSize)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		p.n = got
+		if c.crypt != nil {
+			c.crypt.decrypt(idx*pageSize, p.data[:got])
+		}
+	}
+	c.pages[idx] = p
+	return p, nil
+}
+
+func (c *pageCache) trim(f *os.File) error {
+	if len(c.pages) < maxDiskPages {
+		return nil
+	}
+	if err := c.flushLocked(f); err != nil {
+		return err
+	}
+	c.pages = make(map[int64]*page)
+	return nil
+}
+
+// locked indica si el disco está cifrado y todavía no se dio la frase; en
+// ese caso sólo se puede tocar el MBR, directo al archivo.
+func (c *pageCache) locked() bool {
+	return c.crypt != nil && c.crypt.x == nil
+}
+
+func (c *pageCache) read(f *os.File, off int64, n int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.locked() {
+		if !clearOnly(off, n) {
+			return nil, ErrLocked
+		}
+		buf := make([]byte, n)
+		_, err := f.ReadAt(buf, off)
+		return buf, err
+	}
+	if err := c.trim(f); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	done := 0
+	for done < n {
+		pos := off + int64(done)
+		idx, in := pos/pageSize, int(pos%pageSize)
+		p, err := c.get(f, idx, true)
+		if err != nil {
+			return buf, err
+		}
+		if in >= p.n {
+			if done == 0 {
+				return buf, io.EOF
+			}
+			return buf, io.ErrUnexpectedEOF
+		}
+		done += copy(buf[done:], p.data[in:p.n])
+		if p.n < pageSize && done < n {
+			return buf, io.ErrUnexpectedEOF
+		}
+	}
+	return buf, nil
+}
+
+func (c *pageCache) write(f *os.File, off int64, buf []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.locked() {
+		if !clearOnly(off, len(buf)) {
+			return ErrLocked
+		}
+		_, err := f.WriteAt(buf, off)
+		return err
+	}
+	if err := c.trim(f); err != nil {
+		return err
+	}
+	done := 0
+	for done < len(buf) {
+		pos := off + int64(done)
+		idx, in := pos/pageSize, int(pos%pageSize)
+		chunk := pageSize - in
+		if rest := len(buf) - done; rest < chunk {
+			chunk = rest
+		}
+		// Una página completa no necesita leerse antes de sobrescribirse.
+		p, err := c.get(f, idx, chunk < pageSize)
+		if err != nil {
+			return err
+		}
+		copy(p.data[in:], buf[done:done+chunk])
+		if in+chunk > p.n {
+			p.n = in + chunk
+		}
+		p.dirty = true
+		done += chunk
+	}
+	return nil
+}
+
+// flushLocked escribe las páginas sucias en orden, agrupando las contiguas.
+func (c *pageCache) flushLocked(f *os.File) error {
+	var idxs []int64
+	for idx, p := range c.pages {
+		if p.dirty {
+			idxs = append(idxs, idx)
+		}
+	}
+	sort.Slice(idxs, func(i, j int) bool { return idxs[i] < idxs[j] })
+
+	for i := 0; i < len(idxs); {
+		j := i
+		run := append([]byte(nil), c.pages[idxs[i]].data[:c.pages[idxs[i]].n]...)
+		for j+1 < len(idxs) && idxs[j+1] == idxs[j]+1 && c.pages[idxs[j]].n == pageSize {
+			j++
+			run = append(run, c.pages[idxs[j]].data[:c.pages[idxs[j]].n]...)
+		}
+		if c.crypt != nil {
+			run = c.crypt.encrypt(idxs[i]*pageSize, run)
+		}
+		if _, err := f.WriteAt(run, idxs[i]*pageSize); err != nil {
+			return fmt.Errorf("diskio: escribiendo páginas: %w", err)
+		}
+		for k := i; k <= j; k++ {
+			c.pages[idxs[k]].dirty = false
+		}
+		i = j + 1
+	}
+	return nil
+}
+
+func (c *pageCache) drop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages = make(map[int64]*page)
+}
+
+// Flush escribe al archivo las páginas sucias del disco path (si está abierto).
+func Flush(path string) error {
+	d, ok := lookupDisk(path)
+	if !ok {
+		return nil
+	}
+	d.cache.mu.Lock()
+	defer d.cache.mu.Unlock()
+	return d.cache.flushLocked(d.f)
+}
+
+// FlushAll escribe las páginas sucias de todos los discos abiertos.
+func FlushAll() error {
+	handlesMu.Lock()
+	disks := make([]*disk, 0, len(handles))
+	for _, d := range handles {
+		disks = append(disks, d)
+	}
+	handlesMu.Unlock()
+
+	var first error
+	for _, d := range disks {
+		d.cache.mu.Lock()
+		err := d.cache.flushLocked(d.f)
+		d.cache.mu.Unlock()
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Sync hace Flush y además fuerza el fsync del archivo.
+func Sync(path string) error {
+	d, ok := lookupDisk(path)
+	if !ok {
+		return nil
+	}
+	d.cache.mu.Lock()
+	defer d.cache.mu.Unlock()
+	if err := d.cache.flushLocked(d.f); err != nil {
+		return err
+	}
+	return d.f.Sync()
+}
+
+// SyncAll aplica Sync a todos los discos abiertos.
+func SyncAll() error {
+	handlesMu.Lock()
+	paths := make([]string, 0, len(handles))
+	for k := range handles {
+		paths = append(paths, k)
+	}
+	handlesMu.Unlock()
+
+	var first error
+	for _, p := range paths {
+		if err := Sync(p); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Invalidate escribe las páginas sucias y descarta la caché del disco.
+func Invalidate(path string) error {
+	d, ok := lookupDisk(path)
+	if !ok {
+		return nil
+	}
+	d.cache.mu.Lock()
+	defer d.cache.mu.Unlock()
+	if err := d.cache.flushLocked(d.f); err != nil {
+		return err
+	}
+	d.cache.pages = make(map[int64]*page)
+	return nil
+}
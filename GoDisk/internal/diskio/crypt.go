@@ -0,0 +1,274 @@
+package diskio
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+// Discos cifrados: todo lo que va después del MBR se cifra con AES-256-XTS
+// en sectores de 512 bytes (el sector 0 cubre sólo [fin del MBR, 512)). La
+// clave sale de la frase con PBKDF2-SHA256; sal, iteraciones y un
+// verificador van en un trailer al final del archivo, fuera del área del
+// disco. La caché de páginas descifra al cargar y cifra al escribir, así
+// que ext2, ext3 y reports no se enteran.
+//
+// Un sector todo en cero se guarda tal cual (y se lee como ceros): así los
+// discos siguen siendo dispersos y compact sigue funcionando, a cambio de
+// que se note qué sectores están vacíos.
+
+const (
+	cryptSector = 512
+	cryptIter   = 100_000
+	cryptKeyLen = 64 // dos claves AES-256
+)
+
+var cryptMagic = [8]byte{'M', 'I', 'A', 'C', 'R', 'Y', 'P', 'T'}
+
+type cryptTrailer struct {
+	Magic  [8]byte
+	Cipher [16]byte
+	Iter   int32
+	Salt   [16]byte
+	Check  [32]byte
+}
+
+var (
+	trailerSize = int64(binary.Size(cryptTrailer{}))
+	clearSize   = int64(binary.Size(structs.MBR{}))
+
+	ErrLocked        = errors.New("diskio: disco cifrado; falta la frase (-passphrase)")
+	ErrBadPassphrase = errors.New("diskio: frase incorrecta")
+)
+
+// diskCrypt es el estado de cifrado de un disco abierto; x es nil mientras
+// no se haya dado la frase.
+type diskCrypt struct {
+	end int64 // fin del área cifrable (= inicio del trailer)
+	x   *xtsCipher
+}
+
+// Claves ya verificadas, por disco; sobreviven a CloseHandle/Invalidate.
+var (
+	keysMu sync.Mutex
+	keys   = map[string]*xtsCipher{}
+)
+
+func readTrailer(f *os.File) (cryptTrailer, int64, bool) {
+	var t cryptTrailer
+	st, err := f.Stat()
+	if err != nil || st.Size() < clearSize+trailerSize {
+		return t, 0, false
+	}
+	off := st.Size() - trailerSize
+	buf := make([]byte, trailerSize)
+	if _, err := f.ReadAt(buf, off); err != nil {
+		return t, 0, false
+	}
+	if binary.Read(bytes.NewReader(buf), binary.LittleEndian, &t) != nil || t.Magic != cryptMagic {
+		return t, 0, false
+	}
+	return t, off, true
+}
+
+// openCrypt detecta el trailer al abrir el disco.
+func openCrypt(f *os.File, key string) *diskCrypt {
+	_, end, ok := readTrailer(f)
+	if !ok {
+		return nil
+	}
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	return &diskCrypt{end: end, x: keys[key]}
+}
+
+func deriveKey(pass string, t cryptTrailer) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, pass, t.Salt[:], int(t.Iter), cryptKeyLen)
+}
+
+func keyCheck(key []byte, t cryptTrailer) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write(t.Salt[:])
+	return m.Sum(nil)
+}
+
+// units recorre los sectores cifrables de [off, off+len(buf)) y llama a fn
+// con cada tramo y su número de sector.
+func (c *diskCrypt) units(off int64, buf []byte, fn func(b []byte, unit uint64)) {
+	end := min(off+int64(len(buf)), c.end)
+	for s := off / cryptSector; s*cryptSector < end; s++ {
+		lo, hi := max(s*cryptSector, off, clearSize), min((s+1)*cryptSector, end)
+		if hi-lo >= xtsBlock {
+			fn(buf[lo-off:hi-off], uint64(s))
+		}
+	}
+}
+
+// decrypt descifra en su lugar lo leído del archivo en off.
+func (c *diskCrypt) decrypt(off int64, buf []byte) {
+	c.units(off, buf, func(b []byte, unit uint64) {
+		if !allZero(b) {
+			c.x.crypt(b, unit, true)
+		}
+	})
+}
+
+// encrypt devuelve una copia cifrada de buf para escribirla en off.
+func (c *diskCrypt) encrypt(off int64, buf []byte) []byte {
+	out := append([]byte(nil), buf...)
+	c.units(off, out, func(b []byte, unit uint64) {
+		if !allZero(b) {
+			c.x.crypt(b, unit, false)
+		}
+	})
+	return out
+}
+
+// clearOnly indica si [off, off+n) cae dentro del MBR, que se lee y escribe
+// sin clave.
+func clearOnly(off int64, n int) bool {
+	return off+int64(n) <= clearSize
+}
+
+// IsEncrypted indica si el disco tiene trailer de cifrado.
+func IsEncrypted(path string) bool {
+	d, err := getDisk(path, false)
+	if err != nil {
+		return false
+	}
+	defer d.release()
+	return d.cache.crypt != nil
+}
+
+// DataSize devuelve el tamaño útil del disco (sin el trailer de cifrado).
+func DataSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, end, ok := readTrailer(f); ok {
+		return end, nil
+	}
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return st.Size(), nil
+}
+
+// Unlock verifica la frase contra el trailer y deja la clave registrada para
+// el resto del proceso.
+func Unlock(path, pass string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	t, _, ok := readTrailer(f)
+	f.Close()
+	if !ok {
+		return errors.New("diskio: el disco no está cifrado")
+	}
+	key, err := deriveKey(pass, t)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(keyCheck(key, t), t.Check[:]) {
+		return ErrBadPassphrase
+	}
+	x, err := newXTS(key)
+	if err != nil {
+		return err
+	}
+	if err := Invalidate(path); err != nil {
+		return err
+	}
+	keysMu.Lock()
+	keys[handleKey(path)] = x
+	keysMu.Unlock()
+	CloseHandle(path)
+	return nil
+}
+
+// EncryptDisk cifra en su lugar un disco en claro y le agrega el trailer.
+// Sirve igual para un disco recién creado (casi todo en cero) que para uno
+// con datos.
+func EncryptDisk(path, pass string) error {
+	if pass == "" {
+		return errors.New("diskio: la frase no puede ser vacía")
+	}
+	if err := Invalidate(path); err != nil {
+		return err
+	}
+	CloseHandle(path)
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, _, ok := readTrailer(f); ok {
+		return errors.New("diskio: el disco ya está cifrado")
+	}
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	t := cryptTrailer{Magic: cryptMagic, Iter: cryptIter}
+	copy(t.Cipher[:], "aes-256-xts")
+	if _, err := rand.Read(t.Salt[:]); err != nil {
+		return err
+	}
+	key, err := deriveKey(pass, t)
+	if err != nil {
+		return err
+	}
+	copy(t.Check[:], keyCheck(key, t))
+	x, err := newXTS(key)
+	if err != nil {
+		return err
+	}
+	c := &diskCrypt{end: st.Size(), x: x}
+
+	const chunk = 1 << 20
+	buf := make([]byte, chunk)
+	for off := int64(0); off < c.end; off += chunk {
+		n, err := f.ReadAt(buf, off)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("diskio: cifrando: %w", err)
+		}
+		if allZero(buf[:n]) {
+			continue
+		}
+		if _, err := f.WriteAt(c.encrypt(off, buf[:n]), off); err != nil {
+			return fmt.Errorf("diskio: cifrando: %w", err)
+		}
+	}
+
+	var tb bytes.Buffer
+	if err := binary.Write(&tb, binary.LittleEndian, t); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(tb.Bytes(), c.end); err != nil {
+		return fmt.Errorf("diskio: escribiendo trailer: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	keys[handleKey(path)] = x
+	keysMu.Unlock()
+	return nil
+}
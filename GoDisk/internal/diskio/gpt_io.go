@@ -0,0 +1,188 @@
+package diskio
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+// GPT es la tabla completa en memoria (una de las dos copias).
+type GPT struct {
+	Header  structs.GPTHeader
+	Entries [structs.GPTEntryCount]structs.GPTEntry
+}
+
+var (
+	ErrNoGPT      = errors.New("diskio: el disco no tiene tabla GPT")
+	ErrGPTCorrupt = errors.New("diskio: ambas copias de la GPT están dañadas")
+)
+
+// Tipo "Linux filesystem data" (0FC63DAF-8483-4772-8E79-3D69D8477DE4) en el
+// orden de bytes mixto de GPT.
+var gptTypeLinux = [16]byte{0xAF, 0x3D, 0xC6, 0x0F, 0x83, 0x84, 0x72, 0x47, 0x8E, 0x79, 0x3D, 0x69, 0xD8, 0x47, 0x7D, 0xE4}
+
+func gptEntriesLen() int64 {
+	n := int64(binary.Size(structs.GPTEntry{})) * structs.GPTEntryCount
+	return alignUp(n, structs.GPTSectorSize)
+}
+
+func alignUp(n, a int64) int64 {
+	return (n + a - 1) / a * a
+}
+
+func newGUID() [16]byte {
+	var g [16]byte
+	_, _ = rand.Read(g[:])
+	g[6] = (g[6] & 0x0f) | 0x40 // versión 4
+	g[8] = (g[8] & 0x3f) | 0x80
+	return g
+}
+
+// NewGPT arma una tabla vacía para un disco de size bytes.
+func NewGPT(size int64) (GPT, error) {
+	var g GPT
+	entriesLen := gptEntriesLen()
+	primaryEntries := int64(structs.GPTHeaderOffset + structs.GPTSectorSize)
+	first := primaryEntries + entriesLen
+	backupHeader := size/structs.GPTSectorSize*structs.GPTSectorSize - structs.GPTSectorSize
+	backupEntries := backupHeader - entriesLen
+	if backupEntries <= first {
+		return g, fmt.Errorf("diskio: disco demasiado pequeño para GPT (%d bytes)", size)
+	}
+
+	h := &g.Header
+	copy(h.Gpt_signature[:], structs.GPTSignature)
+	h.Gpt_revision = structs.GPTRevision
+	h.Gpt_header_size = uint32(binary.Size(structs.GPTHeader{}))
+	h.Gpt_my_offset = structs.GPTHeaderOffset
+	h.Gpt_alt_offset = backupHeader
+	h.Gpt_first_usable = first
+	h.Gpt_last_usable = backupEntries
+	h.Gpt_disk_guid = newGUID()
+	h.Gpt_entries_offset = primaryEntries
+	h.Gpt_num_entries = structs.GPTEntryCount
+	h.Gpt_entry_size = uint32(binary.Size(structs.GPTEntry{}))
+
+	for i := range g.Entries {
+		g.Entries[i].Part_status = '0'
+		g.Entries[i].Part_start = -1
+		g.Entries[i].Part_correlative = -1
+	}
+	return g, nil
+}
+
+func encode(v any) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, v)
+	return buf.Bytes()
+}
+
+func headerCRC(h structs.GPTHeader) uint32 {
+	h.Gpt_header_crc = 0
+	return crc32.ChecksumIEEE(encode(h))
+}
+
+// readGPTCopy lee y valida la copia cuyo encabezado está en off.
+func readGPTCopy(path string, off int64) (GPT, error) {
+	var g GPT
+	if err := ReadAt(path, off, &g.Header); err != nil {
+		return g, err
+	}
+	h := g.Header
+	if string(h.Gpt_signature[:]) != structs.GPTSignature {
+		return g, ErrNoGPT
+	}
+	if headerCRC(h) != h.Gpt_header_crc {
+		return g, fmt.Errorf("diskio: GPT en %d: CRC de encabezado inválido", off)
+	}
+	if h.Gpt_num_entries != structs.GPTEntryCount || h.Gpt_entry_size != uint32(binary.Size(structs.GPTEntry{})) {
+		return g, fmt.Errorf("diskio: GPT en %d: geometría de entradas no soportada", off)
+	}
+	if err := ReadAt(path, h.Gpt_entries_offset, &g.Entries); err != nil {
+		return g, err
+	}
+	if crc32.ChecksumIEEE(encode(g.Entries)) != h.Gpt_entries_crc {
+		return g, fmt.Errorf("diskio: GPT en %d: CRC de entradas inválido", off)
+	}
+	return g, nil
+}
+
+// ReadGPT lee la GPT principal y, si está dañada, la de respaldo al final
+// del disco. size es el tamaño lógico del disco (Mbr_tamano).
+func ReadGPT(path string, size int64) (GPT, error) {
+	g, err := readGPTCopy(path, structs.GPTHeaderOffset)
+	if err == nil {
+		return g, nil
+	}
+	alt := size/structs.GPTSectorSize*structs.GPTSectorSize - structs.GPTSectorSize
+	if g.Header.Gpt_alt_offset > 0 {
+		alt = g.Header.Gpt_alt_offset
+	}
+	b, berr := readGPTCopy(path, alt)
+	if berr == nil {
+		return b, nil
+	}
+	if errors.Is(err, ErrNoGPT) && errors.Is(berr, ErrNoGPT) {
+		return g, ErrNoGPT
+	}
+	return g, ErrGPTCorrupt
+}
+
+// WriteGPT escribe las dos copias (principal y respaldo) con sus CRC.
+func WriteGPT(path string, g GPT) error {
+	h := g.Header
+	primary := int64(structs.GPTHeaderOffset)
+	backup := h.Gpt_alt_offset
+	if h.Gpt_my_offset != primary {
+		backup = h.Gpt_my_offset
+	}
+	entriesCRC := crc32.ChecksumIEEE(encode(g.Entries))
+
+	copies := []struct{ my, alt, entries int64 }{
+		{primary, backup, primary + structs.GPTSectorSize},
+		{backup, primary, backup - gptEntriesLen()},
+	}
+	for _, c := range copies {
+		hc := h
+		hc.Gpt_my_offset = c.my
+		hc.Gpt_alt_offset = c.alt
+		hc.Gpt_entries_offset = c.entries
+		hc.Gpt_entries_crc = entriesCRC
+		hc.Gpt_header_crc = headerCRC(hc)
+		if err := WriteAt(path, c.entries, &g.Entries); err != nil {
+			return fmt.Errorf("diskio: escribiendo entradas GPT: %w", err)
+		}
+		if err := WriteAt(path, c.my, &hc); err != nil {
+			return fmt.Errorf("diskio: escribiendo encabezado GPT: %w", err)
+		}
+	}
+	return nil
+}
+
+// ProtectiveMBR marca el MBR con una única entrada tipo 'G' que cubre el
+// disco después del propio MBR.
+func ProtectiveMBR(m *structs.MBR) {
+	for i := range m.Mbr_partitions {
+		m.Mbr_partitions[i] = structs.Partition{Part_status: '0', Part_start: -1, Part_correlative: -1}
+	}
+	p := &m.Mbr_partitions[0]
+	p.Part_status = '1'
+	p.Part_type = structs.GPTProtective
+	p.Part_start = structs.GPTHeaderOffset
+	p.Part_s = m.Mbr_tamano - structs.GPTHeaderOffset
+	copy(p.Part_name[:], "GPT")
+}
+
+func hasProtectiveEntry(m structs.MBR) bool {
+	for _, p := range m.Mbr_partitions {
+		if p.Part_status == '1' && p.Part_type == structs.GPTProtective {
+			return true
+		}
+	}
+	return false
+}
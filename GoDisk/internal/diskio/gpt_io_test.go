@@ -0,0 +1,111 @@
+package diskio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+const gptTestDiskSize = 2 << 20
+
+// newGPTDisk crea un disco GPT con dos particiones, como mkdisk -type=gpt
+// más dos fdisk.
+func newGPTDisk(t *testing.T) (string, GPT) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gpt.mia")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, gptTestDiskSize); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { CloseHandle(path) })
+
+	g, err := NewGPT(gptTestDiskSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := g.Header.Gpt_first_usable
+	for i, p := range []struct {
+		name        string
+		start, size int64
+	}{{"P1", first, 64 << 10}, {"P2", first + 64<<10, 128 << 10}} {
+		e := &g.Entries[i]
+		e.Part_status = '1'
+		e.Part_fit = 'f'
+		e.Part_start = p.start
+		e.Part_s = p.size
+		copy(e.Part_name[:], p.name)
+	}
+	m := structs.NewMBR(gptTestDiskSize, 'f', 7)
+	ProtectiveMBR(&m)
+	if err := WriteMBR(path, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteGPT(path, g); err != nil {
+		t.Fatal(err)
+	}
+	return path, g
+}
+
+func TestGPTBackupCopy(t *testing.T) {
+	cases := []struct {
+		name    string
+		damage  func(g GPT) []int64 // offsets a pisar con basura
+		wantErr error
+	}{
+		{"intacta", func(GPT) []int64 { return nil }, nil},
+		{"encabezado principal dañado", func(GPT) []int64 { return []int64{structs.GPTHeaderOffset + 20} }, nil},
+		{"entradas principales dañadas", func(g GPT) []int64 { return []int64{g.Header.Gpt_entries_offset + 40} }, nil},
+		{"ambas copias dañadas", func(g GPT) []int64 {
+			return []int64{structs.GPTHeaderOffset + 20, g.Header.Gpt_alt_offset + 20}
+		}, ErrGPTCorrupt},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path, g := newGPTDisk(t)
+			for _, off := range c.damage(g) {
+				if err := WriteBytes(path, off, []byte("basura")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := Flush(path); err != nil {
+				t.Fatal(err)
+			}
+			CloseHandle(path)
+
+			got, err := ReadGPT(path, gptTestDiskSize)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("ReadGPT err = %v, se esperaba %v", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadGPT: %v", err)
+			}
+			if got.Entries != g.Entries {
+				t.Fatal("las entradas leídas no coinciden con las escritas")
+			}
+
+			tbl, err := OpenTable(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tbl.Kind() != "gpt" {
+				t.Fatalf("Kind = %q, se esperaba gpt", tbl.Kind())
+			}
+			var names []string
+			for _, p := range tbl.Partitions() {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, []string{"P1", "P2"}) {
+				t.Fatalf("particiones = %v", names)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package diskio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Caché de descriptores abiertos por disco (.mia), cada uno con su caché de
+// páginas (ver cache.go). Los accesos al archivo usan ReadAt/WriteAt
+// (pread/pwrite), así que el mismo *os.File se comparte entre goroutines.
+type disk struct {
+	f     *os.File
+	cache *pageCache
+	ro    bool // abierto sólo para lectura y fuera de handles
+}
+
+var (
+	handlesMu sync.Mutex
+	handles   = map[string]*disk{}
+)
+
+func handleKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// getDisk devuelve el descriptor cacheado del disco. Si el archivo no se
+// puede abrir para escritura y write es false, devuelve uno de solo lectura
+// que no se cachea (así una escritura posterior vuelve a intentar O_RDWR);
+// el llamador lo suelta con release.
+func getDisk(path string, write bool) (*disk, error) {
+	k := handleKey(path)
+
+	handlesMu.Lock()
+	defer handlesMu.Unlock()
+
+	if d, ok := handles[k]; ok {
+		return d, nil
+	}
+	f, err := os.OpenFile(k, os.O_RDWR, 0o666)
+	if err != nil {
+		if write {
+			return nil, err
+		}
+		ro, err2 := os.Open(k)
+		if err2 != nil {
+			return nil, err
+		}
+		d := &disk{f: ro, cache: newPageCache(), ro: true}
+		d.cache.crypt = openCrypt(ro, k)
+		return d, nil
+	}
+	d := &disk{f: f, cache: newPageCache()}
+	d.cache.crypt = openCrypt(f, k)
+	handles[k] = d
+	return d, nil
+}
+
+// release cierra el descriptor si getDisk lo abrió sólo para lectura.
+func (d *disk) release() {
+	if d.ro {
+		_ = d.f.Close()
+	}
+}
+
+func lookupDisk(path string) (*disk, bool) {
+	handlesMu.Lock()
+	defer handlesMu.Unlock()
+	d, ok := handles[handleKey(path)]
+	return d, ok
+}
+
+// CloseHandle cierra y descarta el descriptor cacheado de un disco junto con
+// sus páginas (sin escribirlas). Debe llamarse antes de borrar o recrear el
+// archivo .mia.
+func CloseHandle(path string) {
+	k := handleKey(path)
+
+	handlesMu.Lock()
+	defer handlesMu.Unlock()
+
+	if d, ok := handles[k]; ok {
+		d.cache.drop()
+		_ = d.f.Close()
+		delete(handles, k)
+	}
+}
+
+// ReadAt decodifica (little endian) data desde el offset absoluto off.
+func ReadAt(path string, off int64, data any) error {
+	n := binary.Size(data)
+	if n < 0 {
+		return fmt.Errorf("diskio: tipo no soportado %T", data)
+	}
+	buf, err := ReadBytes(path, off, n)
+	if err != nil {
+		return err
+	}
+	return binary.Read(bytes.NewReader(buf), binary.LittleEndian, data)
+}
+
+// WriteAt codifica (little endian) data en el offset absoluto off.
+func WriteAt(path string, off int64, data any) error {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		return err
+	}
+	return WriteBytes(path, off, buf.Bytes())
+}
+
+func ReadBytes(path string, off int64, n int) ([]byte, error) {
+	d, err := getDisk(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer d.release()
+	return d.cache.read(d.f, off, n)
+}
+
+func WriteBytes(path string, off int64, buf []byte) error {
+	d, err := getDisk(path, true)
+	if err != nil {
+		return err
+	}
+	return d.cache.write(d.f, off, buf)
+}
+
+// ZeroRange rellena con \0 el tramo [off, off+n) en trozos de 1 MiB.
+func ZeroRange(path string, off, n int64) error {
+	const chunk = 1 << 20
+	buf := make([]byte, min(n, chunk))
+	for n > 0 {
+		k := min(n, chunk)
+		if err := WriteBytes(path, off, buf[:k]); err != nil {
+			return err
+		}
+		off += k
+		n -= k
+	}
+	return nil
+}
+
+// CopyRange copia n bytes de src a dst; si los tramos se traslapan copia en
+// el sentido que no pisa lo que falta por leer.
+func CopyRange(path string, src, dst, n int64) error {
+	if n <= 0 || src == dst {
+		return nil
+	}
+	const chunk = 1 << 20
+	for done := int64(0); done < n; {
+		k := min(n-done, chunk)
+		off := done
+		if dst > src {
+			off = n - done - k // hacia atrás
+		}
+		buf, err := ReadBytes(path, src+off, int(k))
+		if err != nil {
+			return err
+		}
+		if err := WriteBytes(path, dst+off, buf); err != nil {
+			return err
+		}
+		done += k
+	}
+	return nil
+}
+
+// ========== Candados por partición ==========
+
+type partKey struct {
+	disk  string
+	start int64
+}
+
+var (
+	locksMu sync.Mutex
+	locks   = map[partKey]*sync.RWMutex{}
+)
+
+func partitionLock(path string, start int64) *sync.RWMutex {
+	k := partKey{disk: handleKey(path), start: start}
+
+	locksMu.Lock()
+	defer locksMu.Unlock()
+
+	l, ok := locks[k]
+	if !ok {
+		l = &sync.RWMutex{}
+		locks[k] = l
+	}
+	return l
+}
+
+// LockPartition toma el candado exclusivo (mutaciones) de la partición que
+// inicia en start dentro del disco path. Devuelve la función de liberación.
+func LockPartition(path string, start int64) func() {
+	l := partitionLock(path, start)
+	l.Lock()
+	return l.Unlock
+}
+
+// RLockPartition toma el candado compartido (lecturas) de la partición.
+func RLockPartition(path string, start int64) func() {
+	l := partitionLock(path, start)
+	l.RLock()
+	return l.RUnlock
+}
@@ -0,0 +1,115 @@
+package diskio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Los .mia se crean dispersos (Truncate no reserva bloques en el host). Con
+// el uso quedan tramos en cero ya reservados (fdisk -delete=full, loss...);
+// Compact los devuelve al sistema de archivos perforando huecos.
+
+// holeBlock es la granularidad de Compact: sólo se perforan bloques
+// alineados que estén completamente en cero.
+const holeBlock = 4096
+
+// ErrNoPunch indica que la plataforma no permite perforar huecos.
+var ErrNoPunch = errors.New("diskio: la plataforma no soporta perforar huecos")
+
+type CompactResult struct {
+	LogicalBytes int64 `json:"logicalBytes"`
+	Before       int64 `json:"allocatedBefore"`
+	After        int64 `json:"allocatedAfter"`
+	Punched      int64 `json:"punchedBytes"`
+}
+
+// Allocated devuelve los bytes que el host tiene reservados para el archivo
+// (menos que el tamaño lógico si es disperso).
+func Allocated(path string) (int64, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return allocatedBytes(st), nil
+}
+
+// Compact escribe la caché del disco y perfora huecos en cada tramo de
+// bloques de holeBlock bytes que esté en cero.
+func Compact(path string) (CompactResult, error) {
+	var res CompactResult
+	if err := Flush(path); err != nil {
+		return res, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return res, err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return res, err
+	}
+	res.LogicalBytes = st.Size()
+	res.Before = allocatedBytes(st)
+
+	buf := make([]byte, holeBlock)
+	runStart := int64(-1)
+	flushRun := func(end int64) error {
+		if runStart < 0 {
+			return nil
+		}
+		n := end - runStart
+		if err := punchHole(f, runStart, n); err != nil {
+			return fmt.Errorf("diskio: perforando [%d,%d): %w", runStart, end, err)
+		}
+		res.Punched += n
+		runStart = -1
+		return nil
+	}
+
+	for off := int64(0); off+holeBlock <= res.LogicalBytes; off += holeBlock {
+		next, err := nextData(f, off)
+		if err != nil {
+			return res, err
+		}
+		if next > off {
+			// Ya es hueco hasta next: no hace falta leerlo.
+			if err := flushRun(off); err != nil {
+				return res, err
+			}
+			off = next/holeBlock*holeBlock - holeBlock
+			continue
+		}
+		if _, err := f.ReadAt(buf, off); err != nil {
+			return res, err
+		}
+		if !allZero(buf) {
+			if err := flushRun(off); err != nil {
+				return res, err
+			}
+			continue
+		}
+		if runStart < 0 {
+			runStart = off
+		}
+	}
+	if err := flushRun(res.LogicalBytes / holeBlock * holeBlock); err != nil {
+		return res, err
+	}
+
+	if st, err = f.Stat(); err == nil {
+		res.After = allocatedBytes(st)
+	}
+	return res, nil
+}
+
+func allZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
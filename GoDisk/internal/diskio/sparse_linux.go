@@ -0,0 +1,43 @@
+//go:build linux
+
+package diskio
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	fallocKeepSize  = 0x01 // FALLOC_FL_KEEP_SIZE
+	fallocPunchHole = 0x02 // FALLOC_FL_PUNCH_HOLE
+	seekData        = 3    // SEEK_DATA
+)
+
+func punchHole(f *os.File, off, n int64) error {
+	return syscall.Fallocate(int(f.Fd()), fallocPunchHole|fallocKeepSize, off, n)
+}
+
+func allocatedBytes(st os.FileInfo) int64 {
+	if s, ok := st.Sys().(*syscall.Stat_t); ok {
+		return s.Blocks * 512
+	}
+	return st.Size()
+}
+
+// nextData devuelve el primer offset >= off con datos reservados; si no hay
+// más datos devuelve el tamaño del archivo.
+func nextData(f *os.File, off int64) (int64, error) {
+	n, err := syscall.Seek(int(f.Fd()), off, seekData)
+	if err == syscall.ENXIO {
+		st, err := f.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return st.Size(), nil
+	}
+	if err != nil {
+		// Sin soporte de SEEK_DATA: se lee todo.
+		return off, nil
+	}
+	return n, nil
+}
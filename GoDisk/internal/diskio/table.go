@@ -0,0 +1,257 @@
+package diskio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+// PartInfo describe una partición sin importar el formato de la tabla.
+type PartInfo struct {
+	Index       int  // slot del MBR, entrada de la GPT o 4+posición en la cadena EBR
+	Type        byte // 'P', 'E' o 'L'
+	Status      byte
+	Fit         byte
+	Start       int64
+	Size        int64
+	Name        string
+	ID          string
+	Correlative int32
+	EBR         int64 // sólo lógicas: byte donde está su EBR (-1 en otro caso)
+}
+
+func (p PartInfo) Usable() bool { return p.Size > 0 && p.Start >= 0 }
+
+func (p PartInfo) End() int64 { return p.Start + p.Size }
+
+// Region es un tramo del disco: metadatos de la tabla, hueco libre, etc.
+type Region struct {
+	Label string
+	Start int64
+	Size  int64
+}
+
+func (r Region) End() int64 { return r.Start + r.Size }
+
+// PartitionTable es la vista común sobre MBR (con su cadena EBR) y GPT. La
+// usan fdisk, mount, la rehidratación y los reportes; los cambios quedan en
+// memoria hasta Save, que vuelve a validar la tabla antes de escribir.
+type PartitionTable interface {
+	Kind() string // "mbr" | "gpt"
+	DiskSize() int64
+	Fit() byte
+	Created() int64
+	Signature() int64
+	// Reserved devuelve los tramos de metadatos de la tabla.
+	Reserved() []Region
+	// Partitions devuelve las particiones en uso: primarias y extendida en
+	// orden de slot y luego las lógicas en el orden de la cadena EBR.
+	Partitions() []PartInfo
+	Find(name string) (PartInfo, bool)
+	FindPrimary(name string) (PartInfo, bool)
+	// FreeRanges devuelve los huecos de primer nivel (fuera de la extendida).
+	FreeRanges() []Region
+	// Create ubica una partición typ ('P', 'E', 'L') de size bytes según
+	// fit ('F', 'B', 'W').
+	Create(typ byte, name string, fit byte, size int64) (PartInfo, error)
+	Delete(name string) (PartInfo, error)
+	// Resize cambia el tamaño (delta puede ser negativo); al crecer exige
+	// espacio libre contiguo después de la partición.
+	Resize(name string, delta int64) (PartInfo, error)
+	// Move reubica la partición para que su tramo empiece en start (-1 =
+	// automático: el hueco más bajo donde quepa). En las lógicas el tramo
+	// incluye su EBR. Sólo cambia la tabla: los bytes se copian aparte con
+	// CopyRange antes de Save.
+	Move(name string, start int64) (from, to PartInfo, err error)
+	// SetMountMeta graba id/correlativo de montaje (id vacío los limpia).
+	SetMountMeta(name, id string, correlative int32) error
+	// Check valida traslapes, límites del disco y la cadena EBR.
+	Check() error
+	Save() error
+}
+
+var (
+	ErrPartNotFound = errors.New("diskio: partición no encontrada")
+	ErrNoSpace      = errors.New("diskio: no hay suficiente espacio contiguo")
+	ErrEBRChain     = errors.New("diskio: cadena EBR inválida")
+)
+
+// OpenTable lee la tabla del disco y devuelve la implementación adecuada:
+// si el MBR es protector se usa la GPT.
+func OpenTable(path string) (PartitionTable, error) {
+	m, err := ReadMBR(path)
+	if err != nil {
+		return nil, err
+	}
+	if hasProtectiveEntry(m) {
+		g, err := ReadGPT(path, m.Mbr_tamano)
+		if err != nil {
+			return nil, err
+		}
+		return &GPTTable{path: path, mbr: m, gpt: g}, nil
+	}
+	return openMBRTable(path, m), nil
+}
+
+// NewTable arma la tabla a partir de un MBR (y su GPT si g no es nil) que
+// todavía no se escribió; los EBR se leen del disco. Sirve para validar con
+// Check antes de Save.
+func NewTable(path string, m structs.MBR, g *GPT) PartitionTable {
+	if g != nil {
+		return &GPTTable{path: path, mbr: m, gpt: *g}
+	}
+	return openMBRTable(path, m)
+}
+
+// IsGPT indica si el disco usa GPT (MBR protector).
+func IsGPT(path string) bool {
+	m, err := ReadMBR(path)
+	return err == nil && hasProtectiveEntry(m)
+}
+
+func fixedName(b []byte) string {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return string(b)
+}
+
+func setMountMeta(status *byte, pid *[4]byte, corr *int32, id string, correlative int32) {
+	*pid = [4]byte{}
+	copy(pid[:], id)
+	*corr = correlative
+	if id != "" {
+		*status = '1'
+	}
+}
+
+func upper(b byte) byte {
+	if b >= 'a' && b <= 'z' {
+		return b - 'a' + 'A'
+	}
+	return b
+}
+
+func findIn(parts []PartInfo, name string) (PartInfo, bool) {
+	for _, p := range parts {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return PartInfo{}, false
+}
+
+func validName(parts []PartInfo, name string) error {
+	if strings.TrimSpace(name) == "" || len(name) > 16 {
+		return fmt.Errorf("diskio: nombre de partición inválido %q", name)
+	}
+	if _, ok := findIn(parts, name); ok {
+		return fmt.Errorf("diskio: ya existe una partición con el nombre '%s'", name)
+	}
+	return nil
+}
+
+// gaps devuelve los huecos de [lo, hi) que no cubren los tramos usados.
+func gaps(lo, hi int64, used []Region) []Region {
+	sorted := append([]Region(nil), used...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var out []Region
+	cur := lo
+	for _, r := range sorted {
+		if r.Start > cur {
+			out = append(out, Region{"libre", cur, r.Start - cur})
+		}
+		if r.End() > cur {
+			cur = r.End()
+		}
+	}
+	if hi > cur {
+		out = append(out, Region{"libre", cur, hi - cur})
+	}
+	return out
+}
+
+// pickFit elige el hueco para size bytes: primer, mejor o peor ajuste
+// (este último es el default de fdisk). Devuelve -1 si ninguno alcanza.
+func pickFit(free []Region, size int64, fit byte) int64 {
+	best := -1
+	for i, r := range free {
+		if r.Size < size {
+			continue
+		}
+		switch upper(fit) {
+		case 'F':
+			return r.Start
+		case 'B':
+			if best < 0 || r.Size < free[best].Size {
+				best = i
+			}
+		default:
+			if best < 0 || r.Size > free[best].Size {
+				best = i
+			}
+		}
+	}
+	if best < 0 {
+		return -1
+	}
+	return free[best].Start
+}
+
+// growRoom indica cuántos bytes libres hay justo después de end.
+func growRoom(free []Region, end int64) int64 {
+	for _, r := range free {
+		if r.Start == end {
+			return r.Size
+		}
+	}
+	return 0
+}
+
+// placeIn busca dónde ubicar need bytes dentro de [lo, hi) sin tocar los
+// tramos usados. start < 0 elige el hueco más bajo que alcance.
+func placeIn(lo, hi int64, used []Region, start, need int64) (int64, error) {
+	for _, r := range gaps(lo, hi, used) {
+		if start < 0 && r.Size >= need {
+			return r.Start, nil
+		}
+		if start >= r.Start && start+need <= r.End() {
+			return start, nil
+		}
+	}
+	if start < 0 {
+		return -1, ErrNoSpace
+	}
+	return -1, fmt.Errorf("diskio: el tramo [%d,%d) no está libre", start, start+need)
+}
+
+// without devuelve rs sin los tramos con la etiqueta dada.
+func without(rs []Region, label string) []Region {
+	var out []Region
+	for _, r := range rs {
+		if r.Label != label {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// checkRegions valida que los tramos queden dentro de [lo, hi) y no se
+// traslapen entre sí.
+func checkRegions(lo, hi int64, rs []Region) error {
+	sorted := append([]Region(nil), rs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	for i, r := range sorted {
+		if r.Start < lo || r.End() > hi || r.Size < 0 {
+			return fmt.Errorf("diskio: '%s' [%d,%d) fuera de [%d,%d)", r.Label, r.Start, r.End(), lo, hi)
+		}
+		if i > 0 && sorted[i-1].End() > r.Start {
+			return fmt.Errorf("diskio: '%s' se traslapa con '%s'", r.Label, sorted[i-1].Label)
+		}
+	}
+	return nil
+}
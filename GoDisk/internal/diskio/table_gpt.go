@@ -0,0 +1,194 @@
+package diskio
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+type GPTTable struct {
+	path string
+	mbr  structs.MBR
+	gpt  GPT
+}
+
+func (t *GPTTable) Kind() string     { return "gpt" }
+func (t *GPTTable) DiskSize() int64  { return t.mbr.Mbr_tamano }
+func (t *GPTTable) Fit() byte        { return t.mbr.Dsk_fit }
+func (t *GPTTable) Created() int64   { return t.mbr.Mbr_fecha_creacion }
+func (t *GPTTable) Signature() int64 { return t.mbr.Mbr_dsk_signature }
+func (t *GPTTable) Header() structs.GPTHeader {
+	return t.gpt.Header
+}
+
+func (t *GPTTable) Save() error {
+	if err := t.Check(); err != nil {
+		return err
+	}
+	return WriteGPT(t.path, t.gpt)
+}
+
+// UsableRange devuelve [inicio, fin) del área para particiones.
+func (t *GPTTable) UsableRange() (int64, int64) {
+	return t.gpt.Header.Gpt_first_usable, t.gpt.Header.Gpt_last_usable
+}
+
+func (t *GPTTable) Reserved() []Region {
+	h := t.gpt.Header
+	backup := h.Gpt_alt_offset
+	if h.Gpt_my_offset != structs.GPTHeaderOffset {
+		backup = h.Gpt_my_offset
+	}
+	el := gptEntriesLen()
+	return []Region{
+		{"MBR", 0, structs.GPTHeaderOffset},
+		{"GPT", structs.GPTHeaderOffset, structs.GPTSectorSize},
+		{"GPT entradas", structs.GPTHeaderOffset + structs.GPTSectorSize, el},
+		{"GPT respaldo entradas", backup - el, el},
+		{"GPT respaldo", backup, t.mbr.Mbr_tamano - backup},
+	}
+}
+
+func entryUsed(e structs.GPTEntry) bool { return e.Part_status == '1' && e.Part_s > 0 }
+
+func (t *GPTTable) Partitions() []PartInfo {
+	var out []PartInfo
+	for i, e := range t.gpt.Entries {
+		if !entryUsed(e) {
+			continue
+		}
+		out = append(out, PartInfo{
+			Index:       i,
+			Type:        'P',
+			Status:      e.Part_status,
+			Fit:         e.Part_fit,
+			Start:       e.Part_start,
+			Size:        e.Part_s,
+			Name:        fixedName(e.Part_name[:]),
+			ID:          fixedName(e.Part_id[:]),
+			Correlative: e.Part_correlative,
+			EBR:         -1,
+		})
+	}
+	return out
+}
+
+func (t *GPTTable) Find(name string) (PartInfo, bool) {
+	return findIn(t.Partitions(), name)
+}
+
+func (t *GPTTable) FindPrimary(name string) (PartInfo, bool) {
+	return t.Find(name)
+}
+
+func (t *GPTTable) entry(name string) *structs.GPTEntry {
+	p, ok := t.Find(name)
+	if !ok {
+		return nil
+	}
+	return &t.gpt.Entries[p.Index]
+}
+
+func (t *GPTTable) SetMountMeta(name, id string, correlative int32) error {
+	e := t.entry(name)
+	if e == nil {
+		return ErrPartNotFound
+	}
+	setMountMeta(&e.Part_status, &e.Part_id, &e.Part_correlative, id, correlative)
+	return nil
+}
+
+func (t *GPTTable) used() []Region {
+	var rs []Region
+	for _, p := range t.Partitions() {
+		rs = append(rs, Region{p.Name, p.Start, p.Size})
+	}
+	return rs
+}
+
+func (t *GPTTable) FreeRanges() []Region {
+	first, last := t.UsableRange()
+	return gaps(first, last, t.used())
+}
+
+func (t *GPTTable) Check() error {
+	first, last := t.UsableRange()
+	return checkRegions(first, last, t.used())
+}
+
+func (t *GPTTable) Create(typ byte, name string, fit byte, size int64) (PartInfo, error) {
+	if upper(typ) != 'P' {
+		return PartInfo{}, errors.New("gpt: no usa particiones extendidas/lógicas")
+	}
+	if err := validName(t.Partitions(), name); err != nil {
+		return PartInfo{}, err
+	}
+	if size <= 0 {
+		return PartInfo{}, errors.New("gpt: tamaño inválido")
+	}
+	start := pickFit(t.FreeRanges(), size, fit)
+	if start < 0 {
+		return PartInfo{}, ErrNoSpace
+	}
+	for i := range t.gpt.Entries {
+		e := &t.gpt.Entries[i]
+		if entryUsed(*e) {
+			continue
+		}
+		*e = structs.GPTEntry{
+			Gpt_type_guid:    gptTypeLinux,
+			Gpt_unique_guid:  newGUID(),
+			Part_status:      '1',
+			Part_fit:         upper(fit),
+			Part_start:       start,
+			Part_s:           size,
+			Part_correlative: -1,
+		}
+		copy(e.Part_name[:], name)
+		p, _ := t.Find(name)
+		return p, nil
+	}
+	return PartInfo{}, fmt.Errorf("gpt: no quedan entradas libres (%d)", structs.GPTEntryCount)
+}
+
+func (t *GPTTable) Delete(name string) (PartInfo, error) {
+	p, ok := t.Find(name)
+	if !ok {
+		return PartInfo{}, ErrPartNotFound
+	}
+	t.gpt.Entries[p.Index] = structs.GPTEntry{Part_status: '0', Part_start: -1, Part_correlative: -1}
+	return p, nil
+}
+
+func (t *GPTTable) Resize(name string, delta int64) (PartInfo, error) {
+	p, ok := t.Find(name)
+	if !ok {
+		return PartInfo{}, ErrPartNotFound
+	}
+	if p.Size+delta <= 0 {
+		return p, errors.New("gpt: tamaño resultante inválido")
+	}
+	if delta > 0 && growRoom(t.FreeRanges(), p.End()) < delta {
+		return p, fmt.Errorf("gpt: no hay espacio libre contiguo suficiente después de '%s'", name)
+	}
+	t.gpt.Entries[p.Index].Part_s += delta
+	p.Size += delta
+	return p, nil
+}
+
+func (t *GPTTable) Move(name string, start int64) (PartInfo, PartInfo, error) {
+	p, ok := t.Find(name)
+	if !ok {
+		return p, p, ErrPartNotFound
+	}
+	first, last := t.UsableRange()
+	at, err := placeIn(first, last, without(t.used(), name), start, p.Size)
+	if err != nil {
+		return p, p, err
+	}
+	t.gpt.Entries[p.Index].Part_start = at
+	to := p
+	to.Start = at
+	return p, to, nil
+}
@@ -0,0 +1,497 @@
+package diskio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+// La extendida siempre empieza con un EBR "cabeza". Cada lógica ocupa su EBR
+// seguido de los datos (Part_start = dirección del EBR + tamaño del EBR) y
+// Part_next guarda la dirección absoluta del siguiente EBR (-1 al final).
+// Si se borra la primera lógica, la cabeza queda inactiva (estado '0') pero
+// conserva Part_next para no perder el resto de la cadena.
+
+// maxEBRs acota la cadena: más nodos que esto se trata como ciclo.
+const maxEBRs = 1024
+
+var ebrSize = int64(binary.Size(structs.EBR{}))
+
+type ebrNode struct {
+	addr int64
+	ebr  structs.EBR
+}
+
+type MBRTable struct {
+	path     string
+	mbr      structs.MBR
+	chain    []ebrNode // chain[0] es la cabeza de la extendida
+	dropped  []ebrNode // EBRs sacados de la cadena; Save los marca inactivos
+	chainErr error
+	dirty    bool // la cadena cambió y Save debe reescribirla
+}
+
+func openMBRTable(path string, m structs.MBR) *MBRTable {
+	t := &MBRTable{path: path, mbr: m}
+	if ext, ok := t.extended(); ok {
+		t.chain, t.chainErr = loadChain(path, ext)
+	}
+	return t
+}
+
+// loadChain recorre la cadena EBR validando que cada EBR caiga dentro de la
+// extendida y que no se repita ninguna dirección.
+func loadChain(path string, ext PartInfo) ([]ebrNode, error) {
+	var out []ebrNode
+	seen := map[int64]bool{}
+	off := ext.Start
+	for {
+		if off < ext.Start || off+ebrSize > ext.End() {
+			return out, fmt.Errorf("%w: EBR en %d fuera de la extendida", ErrEBRChain, off)
+		}
+		if seen[off] || len(out) >= maxEBRs {
+			return out, fmt.Errorf("%w: ciclo en %d", ErrEBRChain, off)
+		}
+		seen[off] = true
+
+		var e structs.EBR
+		if err := ReadAt(path, off, &e); err != nil {
+			return out, fmt.Errorf("%w: leyendo EBR en %d: %v", ErrEBRChain, off, err)
+		}
+		out = append(out, ebrNode{off, e})
+		if e.Part_next <= 0 {
+			return out, nil
+		}
+		off = e.Part_next
+	}
+}
+
+func (t *MBRTable) Kind() string     { return "mbr" }
+func (t *MBRTable) DiskSize() int64  { return t.mbr.Mbr_tamano }
+func (t *MBRTable) Fit() byte        { return t.mbr.Dsk_fit }
+func (t *MBRTable) Created() int64   { return t.mbr.Mbr_fecha_creacion }
+func (t *MBRTable) Signature() int64 { return t.mbr.Mbr_dsk_signature }
+func (t *MBRTable) MBR() structs.MBR { return t.mbr }
+func (t *MBRTable) Reserved() []Region {
+	return []Region{{"MBR", 0, int64(binary.Size(structs.MBR{}))}}
+}
+
+// EBRs devuelve la dirección de cada EBR de la cadena, cabeza incluida.
+func (t *MBRTable) EBRs() []int64 {
+	out := make([]int64, 0, len(t.chain))
+	for _, n := range t.chain {
+		out = append(out, n.addr)
+	}
+	return out
+}
+
+func slotUsed(p structs.Partition) bool { return p.Part_s > 0 && p.Part_start >= 0 }
+
+func ebrActive(e structs.EBR) bool { return e.Part_status == '1' && e.Part_s > 0 }
+
+func (t *MBRTable) Partitions() []PartInfo {
+	var out []PartInfo
+	for i, p := range t.mbr.Mbr_partitions {
+		if !slotUsed(p) {
+			continue
+		}
+		out = append(out, PartInfo{
+			Index:       i,
+			Type:        upper(p.Part_type),
+			Status:      p.Part_status,
+			Fit:         p.Part_fit,
+			Start:       p.Part_start,
+			Size:        p.Part_s,
+			Name:        fixedName(p.Part_name[:]),
+			ID:          fixedName(p.Part_id[:]),
+			Correlative: p.Part_correlative,
+			EBR:         -1,
+		})
+	}
+	for k, n := range t.chain {
+		if !ebrActive(n.ebr) {
+			continue
+		}
+		out = append(out, PartInfo{
+			Index:       len(t.mbr.Mbr_partitions) + k,
+			Type:        'L',
+			Status:      n.ebr.Part_status,
+			Fit:         n.ebr.Part_fit,
+			Start:       n.ebr.Part_start,
+			Size:        n.ebr.Part_s,
+			Name:        fixedName(n.ebr.Part_name[:]),
+			Correlative: -1,
+			EBR:         n.addr,
+		})
+	}
+	return out
+}
+
+func (t *MBRTable) Find(name string) (PartInfo, bool) {
+	return findIn(t.Partitions(), name)
+}
+
+func (t *MBRTable) FindPrimary(name string) (PartInfo, bool) {
+	p, ok := t.Find(name)
+	if !ok || p.Type != 'P' {
+		return PartInfo{}, false
+	}
+	return p, true
+}
+
+func (t *MBRTable) extended() (PartInfo, bool) {
+	for _, p := range t.Partitions() {
+		if p.Type == 'E' {
+			return p, true
+		}
+	}
+	return PartInfo{}, false
+}
+
+func (t *MBRTable) SetMountMeta(name, id string, correlative int32) error {
+	p, ok := t.Find(name)
+	if !ok || p.Type == 'L' {
+		return ErrPartNotFound
+	}
+	s := &t.mbr.Mbr_partitions[p.Index]
+	setMountMeta(&s.Part_status, &s.Part_id, &s.Part_correlative, id, correlative)
+	return nil
+}
+
+// ---------- espacio ----------
+
+func (t *MBRTable) topUsed() []Region {
+	var rs []Region
+	for _, p := range t.Partitions() {
+		if p.Type != 'L' {
+			rs = append(rs, Region{p.Name, p.Start, p.Size})
+		}
+	}
+	return rs
+}
+
+func (t *MBRTable) FreeRanges() []Region {
+	mbrSize := int64(binary.Size(structs.MBR{}))
+	return gaps(mbrSize, t.mbr.Mbr_tamano, t.topUsed())
+}
+
+// logicalUsed devuelve EBRs y datos de las lógicas. La cabeza inactiva sólo
+// cuenta si todavía encadena otras lógicas; si está sola su lugar queda libre
+// para la próxima lógica.
+func (t *MBRTable) logicalUsed() []Region {
+	var rs []Region
+	for k, n := range t.chain {
+		if ebrActive(n.ebr) {
+			name := fixedName(n.ebr.Part_name[:])
+			rs = append(rs, Region{"EBR " + name, n.addr, ebrSize})
+			rs = append(rs, Region{name, n.ebr.Part_start, n.ebr.Part_s})
+		} else if k > 0 || len(t.chain) > 1 {
+			rs = append(rs, Region{"EBR", n.addr, ebrSize})
+		}
+	}
+	return rs
+}
+
+// FreeInExtended devuelve los huecos dentro de la extendida.
+func (t *MBRTable) FreeInExtended() []Region {
+	ext, ok := t.extended()
+	if !ok {
+		return nil
+	}
+	return gaps(ext.Start, ext.End(), t.logicalUsed())
+}
+
+func (t *MBRTable) Check() error {
+	mbrSize := int64(binary.Size(structs.MBR{}))
+	rs := append([]Region{{"MBR", 0, mbrSize}}, t.topUsed()...)
+	if err := checkRegions(0, t.mbr.Mbr_tamano, rs); err != nil {
+		return err
+	}
+	n := 0
+	for _, p := range t.Partitions() {
+		if p.Type == 'E' {
+			n++
+		}
+	}
+	if n > 1 {
+		return errors.New("diskio: hay más de una partición extendida")
+	}
+	if t.chainErr != nil {
+		return t.chainErr
+	}
+	if ext, ok := t.extended(); ok {
+		return checkRegions(ext.Start, ext.End(), t.logicalUsed())
+	}
+	return nil
+}
+
+func overlapsAny(rs []Region, start, size int64) bool {
+	for _, r := range rs {
+		if start < r.End() && r.Start < start+size {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- cambios ----------
+
+func (t *MBRTable) Create(typ byte, name string, fit byte, size int64) (PartInfo, error) {
+	typ = upper(typ)
+	if err := validName(t.Partitions(), name); err != nil {
+		return PartInfo{}, err
+	}
+	if size <= 0 {
+		return PartInfo{}, errors.New("diskio: tamaño inválido")
+	}
+
+	switch typ {
+	case 'P', 'E':
+		slot := -1
+		for i, p := range t.mbr.Mbr_partitions {
+			if !slotUsed(p) {
+				slot = i
+				break
+			}
+		}
+		if slot < 0 {
+			return PartInfo{}, errors.New("diskio: ya existen 4 particiones, no se pueden crear más")
+		}
+		if _, ok := t.extended(); ok && typ == 'E' {
+			return PartInfo{}, errors.New("diskio: ya existe una partición extendida en este disco")
+		}
+		if typ == 'E' && size <= ebrSize {
+			return PartInfo{}, errors.New("diskio: la extendida no alcanza para su EBR")
+		}
+		start := pickFit(t.FreeRanges(), size, fit)
+		if start < 0 {
+			return PartInfo{}, ErrNoSpace
+		}
+		p := structs.Partition{
+			Part_status:      '1',
+			Part_type:        typ,
+			Part_fit:         upper(fit),
+			Part_start:       start,
+			Part_s:           size,
+			Part_correlative: -1,
+		}
+		copy(p.Part_name[:], name)
+		t.mbr.Mbr_partitions[slot] = p
+		if typ == 'E' {
+			t.chain = []ebrNode{{start, structs.EBR{Part_status: '0', Part_start: -1, Part_next: -1}}}
+			t.chainErr = nil
+			t.dirty = true
+		}
+
+	case 'L':
+		if _, ok := t.extended(); !ok {
+			return PartInfo{}, errors.New("diskio: no existe una partición extendida")
+		}
+		if t.chainErr != nil {
+			return PartInfo{}, t.chainErr
+		}
+		at := pickFit(t.FreeInExtended(), size+ebrSize, fit)
+		if at < 0 {
+			return PartInfo{}, fmt.Errorf("%w en la extendida", ErrNoSpace)
+		}
+		e := structs.EBR{
+			Part_status: '1',
+			Part_fit:    upper(fit),
+			Part_start:  at + ebrSize,
+			Part_s:      size,
+			Part_next:   -1,
+		}
+		copy(e.Part_name[:], name)
+		if head := &t.chain[0]; head.addr == at && !ebrActive(head.ebr) {
+			e.Part_next = head.ebr.Part_next
+			head.ebr = e
+		} else {
+			t.chain = append(t.chain, ebrNode{at, e})
+		}
+		t.dirty = true
+
+	default:
+		return PartInfo{}, fmt.Errorf("diskio: tipo de partición inválido %q", typ)
+	}
+
+	p, _ := t.Find(name)
+	return p, nil
+}
+
+func (t *MBRTable) Delete(name string) (PartInfo, error) {
+	p, ok := t.Find(name)
+	if !ok {
+		return PartInfo{}, ErrPartNotFound
+	}
+	if p.Type != 'L' {
+		t.mbr.Mbr_partitions[p.Index] = structs.Partition{Part_status: '0', Part_start: -1, Part_correlative: -1}
+		if p.Type == 'E' {
+			t.chain, t.dropped, t.chainErr, t.dirty = nil, nil, nil, false
+		}
+		return p, nil
+	}
+	if t.chainErr != nil {
+		return p, t.chainErr
+	}
+
+	k := p.Index - len(t.mbr.Mbr_partitions)
+	if k == 0 {
+		head := &t.chain[0]
+		head.ebr = structs.EBR{Part_status: '0', Part_start: -1, Part_next: head.ebr.Part_next}
+	} else {
+		t.dropped = append(t.dropped, t.chain[k])
+		t.chain = append(t.chain[:k], t.chain[k+1:]...)
+	}
+	t.dirty = true
+	return p, nil
+}
+
+func (t *MBRTable) Resize(name string, delta int64) (PartInfo, error) {
+	p, ok := t.Find(name)
+	if !ok {
+		return PartInfo{}, ErrPartNotFound
+	}
+	if p.Size+delta <= 0 {
+		return p, errors.New("diskio: tamaño resultante inválido")
+	}
+
+	if p.Type == 'L' {
+		if t.chainErr != nil {
+			return p, t.chainErr
+		}
+		if delta > 0 && growRoom(t.FreeInExtended(), p.End()) < delta {
+			return p, fmt.Errorf("diskio: no hay espacio contiguo suficiente en la extendida después de '%s'", name)
+		}
+		t.chain[p.Index-len(t.mbr.Mbr_partitions)].ebr.Part_s += delta
+		t.dirty = true
+		p.Size += delta
+		return p, nil
+	}
+
+	if delta > 0 && growRoom(t.FreeRanges(), p.End()) < delta {
+		return p, fmt.Errorf("diskio: no hay espacio libre contiguo suficiente después de '%s'", name)
+	}
+	if p.Type == 'E' && delta < 0 {
+		for _, r := range t.logicalUsed() {
+			if r.End() > p.End()+delta {
+				return p, fmt.Errorf("diskio: reducir '%s' cortaría '%s'", name, r.Label)
+			}
+		}
+	}
+	t.mbr.Mbr_partitions[p.Index].Part_s += delta
+	p.Size += delta
+	return p, nil
+}
+
+func (t *MBRTable) Move(name string, start int64) (PartInfo, PartInfo, error) {
+	p, ok := t.Find(name)
+	if !ok {
+		return p, p, ErrPartNotFound
+	}
+	if p.Type == 'L' {
+		return t.moveLogical(p, start)
+	}
+	if p.Type == 'E' && t.chainErr != nil {
+		return p, p, t.chainErr
+	}
+
+	mbrSize := int64(binary.Size(structs.MBR{}))
+	at, err := placeIn(mbrSize, t.mbr.Mbr_tamano, without(t.topUsed(), name), start, p.Size)
+	if err != nil {
+		return p, p, err
+	}
+	t.mbr.Mbr_partitions[p.Index].Part_start = at
+
+	// La extendida arrastra toda su cadena.
+	if delta := at - p.Start; p.Type == 'E' && delta != 0 {
+		for i := range t.chain {
+			n := &t.chain[i]
+			n.addr += delta
+			if ebrActive(n.ebr) {
+				n.ebr.Part_start += delta
+			}
+		}
+		t.dirty = true
+	}
+	to := p
+	to.Start = at
+	return p, to, nil
+}
+
+// moveLogical mueve EBR y datos juntos. La cabeza no se puede mover de
+// lugar: queda inactiva y la lógica pasa a un EBR nuevo justo después.
+func (t *MBRTable) moveLogical(p PartInfo, start int64) (PartInfo, PartInfo, error) {
+	if t.chainErr != nil {
+		return p, p, t.chainErr
+	}
+	ext, _ := t.extended()
+	k := p.Index - len(t.mbr.Mbr_partitions)
+
+	used := without(without(t.logicalUsed(), p.Name), "EBR "+p.Name)
+	if k == 0 {
+		used = append(used, Region{"EBR", ext.Start, ebrSize})
+	}
+	at, err := placeIn(ext.Start, ext.End(), used, start, ebrSize+p.Size)
+	if err != nil {
+		return p, p, err
+	}
+
+	e := t.chain[k].ebr
+	e.Part_start = at + ebrSize
+	if k == 0 {
+		head := &t.chain[0]
+		head.ebr = structs.EBR{Part_status: '0', Part_start: -1, Part_next: head.ebr.Part_next}
+		t.chain = append(t.chain[:1], append([]ebrNode{{at, e}}, t.chain[1:]...)...)
+	} else {
+		t.dropped = append(t.dropped, t.chain[k])
+		t.chain[k] = ebrNode{at, e}
+	}
+	t.dirty = true
+
+	to, _ := t.Find(p.Name)
+	return p, to, nil
+}
+
+// Save valida la tabla y escribe el MBR y, si cambió, la cadena EBR con los
+// Part_next recalculados.
+func (t *MBRTable) Save() error {
+	if err := t.Check(); err != nil && (t.dirty || !errors.Is(err, ErrEBRChain)) {
+		return err
+	}
+	if err := WriteMBR(t.path, t.mbr); err != nil {
+		return err
+	}
+	if !t.dirty {
+		return nil
+	}
+
+	// Un EBR descartado que cae dentro de algo vivo (p. ej. tras un move)
+	// no se toca.
+	live := t.logicalUsed()
+	for _, n := range t.dropped {
+		if overlapsAny(live, n.addr, ebrSize) {
+			continue
+		}
+		e := n.ebr
+		e.Part_status = '0'
+		e.Part_next = -1
+		if err := WriteAt(t.path, n.addr, &e); err != nil {
+			return fmt.Errorf("diskio: escribiendo EBR: %w", err)
+		}
+	}
+	for i := range t.chain {
+		n := &t.chain[i]
+		n.ebr.Part_next = -1
+		if i+1 < len(t.chain) {
+			n.ebr.Part_next = t.chain[i+1].addr
+		}
+		if err := WriteAt(t.path, n.addr, &n.ebr); err != nil {
+			return fmt.Errorf("diskio: escribiendo EBR: %w", err)
+		}
+	}
+	t.dropped = nil
+	t.dirty = false
+	return nil
+}
@@ -0,0 +1,146 @@
+package diskio
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+const testDiskSize = 4 << 20
+
+// newTestDisk crea un disco vacío como lo deja mkdisk.
+func newTestDisk(t *testing.T, kind string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), kind+".mia")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, testDiskSize); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { CloseHandle(path) })
+
+	m := structs.NewMBR(testDiskSize, 'f', 42)
+	if kind == "gpt" {
+		g, err := NewGPT(testDiskSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ProtectiveMBR(&m)
+		if err := WriteGPT(path, g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := WriteMBR(path, m); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+type partSpec struct {
+	typ  byte
+	name string
+	size int64
+}
+
+func TestTableSaveReopen(t *testing.T) {
+	cases := []struct {
+		kind  string
+		parts []partSpec
+	}{
+		{"mbr", []partSpec{{'P', "P1", 512 << 10}, {'E', "E1", 1 << 20}, {'L', "L1", 100 << 10}, {'L', "L2", 200 << 10}, {'P', "P2", 256 << 10}}},
+		{"gpt", []partSpec{{'P', "P1", 512 << 10}, {'P', "P2", 1 << 20}, {'P', "P3", 4096}}},
+	}
+	for _, c := range cases {
+		t.Run(c.kind, func(t *testing.T) {
+			path := newTestDisk(t, c.kind)
+			tbl, err := OpenTable(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tbl.Kind() != c.kind {
+				t.Fatalf("Kind = %q, se esperaba %q", tbl.Kind(), c.kind)
+			}
+			for _, p := range c.parts {
+				if _, err := tbl.Create(p.typ, p.name, 'F', p.size); err != nil {
+					t.Fatalf("Create %s: %v", p.name, err)
+				}
+			}
+			if err := tbl.Save(); err != nil {
+				t.Fatal(err)
+			}
+			want := tbl.Partitions()
+			if len(want) != len(c.parts) {
+				t.Fatalf("%d particiones, se esperaban %d", len(want), len(c.parts))
+			}
+
+			// Reabrir desde el archivo, sin la caché de páginas.
+			if err := Flush(path); err != nil {
+				t.Fatal(err)
+			}
+			CloseHandle(path)
+			again, err := OpenTable(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := again.Check(); err != nil {
+				t.Fatalf("Check tras reabrir: %v", err)
+			}
+			if got := again.Partitions(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("tras reabrir:\n got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestTableCheckRejectsOverlap(t *testing.T) {
+	for _, kind := range []string{"mbr", "gpt"} {
+		t.Run(kind, func(t *testing.T) {
+			path := newTestDisk(t, kind)
+			tbl, err := OpenTable(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a, err := tbl.Create('P', "A", 'F', 64<<10)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// B pisa la segunda mitad de A; se escribe a mano, sin pasar
+			// por Save.
+			if kind == "gpt" {
+				g := tbl.(*GPTTable).gpt
+				g.Entries[1] = g.Entries[0]
+				g.Entries[1].Part_start = a.Start + a.Size/2
+				copy(g.Entries[1].Part_name[:], "B")
+				err = WriteGPT(path, g)
+			} else {
+				m := tbl.(*MBRTable).mbr
+				m.Mbr_partitions[1] = m.Mbr_partitions[0]
+				m.Mbr_partitions[1].Part_start = a.Start + a.Size/2
+				copy(m.Mbr_partitions[1].Part_name[:], "B")
+				err = WriteMBR(path, m)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bad, err := OpenTable(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(bad.Partitions()) != 2 {
+				t.Fatalf("particiones = %+v", bad.Partitions())
+			}
+			if err := bad.Check(); err == nil {
+				t.Fatal("Check aceptó particiones traslapadas")
+			}
+			if err := bad.Save(); err == nil {
+				t.Fatal("Save escribió una tabla inválida")
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package diskio
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// AES-XTS (IEEE 1619) sobre unidades de datos arbitrarias >= 16 bytes; si la
+// unidad no es múltiplo de 16 se usa ciphertext stealing. Se implementa aquí
+// para no depender de golang.org/x/crypto.
+
+const xtsBlock = aes.BlockSize
+
+type xtsCipher struct {
+	k1, k2 cipher.Block
+}
+
+// newXTS recibe las dos claves AES concatenadas (64 bytes para AES-256).
+func newXTS(key []byte) (*xtsCipher, error) {
+	if len(key)%2 != 0 {
+		return nil, errors.New("xts: largo de clave inválido")
+	}
+	k1, err := aes.NewCipher(key[:len(key)/2])
+	if err != nil {
+		return nil, err
+	}
+	k2, err := aes.NewCipher(key[len(key)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return &xtsCipher{k1: k1, k2: k2}, nil
+}
+
+// mulAlpha multiplica el tweak por α en GF(2^128) (little endian).
+func mulAlpha(t *[xtsBlock]byte) {
+	var carry byte
+	for i := 0; i < xtsBlock; i++ {
+		next := t[i] >> 7
+		t[i] = t[i]<<1 | carry
+		carry = next
+	}
+	if carry != 0 {
+		t[0] ^= 0x87
+	}
+}
+
+func (x *xtsCipher) block(dst, src []byte, t *[xtsBlock]byte, decrypt bool) {
+	var b [xtsBlock]byte
+	for i := range b {
+		b[i] = src[i] ^ t[i]
+	}
+	if decrypt {
+		x.k1.Decrypt(b[:], b[:])
+	} else {
+		x.k1.Encrypt(b[:], b[:])
+	}
+	for i := range b {
+		dst[i] = b[i] ^ t[i]
+	}
+}
+
+// crypt cifra o descifra buf en su lugar como la unidad número unit.
+func (x *xtsCipher) crypt(buf []byte, unit uint64, decrypt bool) {
+	if len(buf) < xtsBlock {
+		return
+	}
+	var t [xtsBlock]byte
+	binary.LittleEndian.PutUint64(t[:8], unit)
+	x.k2.Encrypt(t[:], t[:])
+
+	full := len(buf) / xtsBlock
+	tail := len(buf) % xtsBlock
+	if tail != 0 {
+		full-- // el último bloque completo participa del stealing
+	}
+	for i := 0; i < full; i++ {
+		b := buf[i*xtsBlock : (i+1)*xtsBlock]
+		x.block(b, b, &t, decrypt)
+		mulAlpha(&t)
+	}
+	if tail == 0 {
+		return
+	}
+
+	last := buf[full*xtsBlock : (full+1)*xtsBlock]
+	rest := buf[(full+1)*xtsBlock:]
+	tNext := t
+	mulAlpha(&tNext)
+	var cc [xtsBlock]byte
+	if !decrypt {
+		x.block(cc[:], last, &t, false)
+		var pp [xtsBlock]byte
+		copy(pp[:], rest)
+		copy(pp[tail:], cc[tail:])
+		copy(rest, cc[:tail])
+		x.block(last, pp[:], &tNext, false)
+		return
+	}
+	var pp [xtsBlock]byte
+	x.block(pp[:], last, &tNext, true)
+	copy(cc[:], rest)
+	copy(cc[tail:], pp[tail:])
+	copy(rest, pp[:tail])
+	x.block(last, cc[:], &t, true)
+}
@@ -0,0 +1,95 @@
+// Package events reparte a los suscriptores (p. ej. /api/events) los cambios
+// que hacen las mutaciones de usersvc sobre una partición montada.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Tipos de evento.
+const (
+	KindCreate  = "create"
+	KindDelete  = "delete"
+	KindRename  = "rename"
+	KindChmod   = "chmod"
+	KindModify  = "modify"
+	KindJournal = "journal"
+)
+
+type Event struct {
+	Seq    uint64    `json:"seq"`
+	Kind   string    `json:"kind"`
+	ID     string    `json:"id"`   // partición montada
+	Op     string    `json:"op"`   // operación del journal (MKDIR, REMOVE, ...)
+	Path   string    `json:"path"` // ruta afectada (origen en RENAME/MOVE)
+	Detail string    `json:"detail,omitempty"`
+	User   string    `json:"user,omitempty"`
+	Error  string    `json:"error,omitempty"`  // operación fallida (sólo KindJournal)
+	JCount int32     `json:"jcount,omitempty"` // entrada del journal (sólo KindJournal)
+	Time   time.Time `json:"time"`
+}
+
+// KindOf clasifica una operación del journal.
+func KindOf(op string) string {
+	switch op {
+	case "MKDIR", "MKFILE", "COPY":
+		return KindCreate
+	case "REMOVE":
+		return KindDelete
+	case "RENAME", "MOVE":
+		return KindRename
+	case "CHMOD", "CHOWN":
+		return KindChmod
+	}
+	return KindModify
+}
+
+// bufSize es lo que puede atrasarse un suscriptor antes de perder eventos.
+const bufSize = 64
+
+var (
+	mu   sync.Mutex
+	seq  uint64
+	subs = map[chan Event]string{}
+)
+
+// Subscribe devuelve un canal con los eventos de la partición id (todas si
+// id es "") y la función que cancela la suscripción y cierra el canal.
+func Subscribe(id string) (<-chan Event, func()) {
+	ch := make(chan Event, bufSize)
+	mu.Lock()
+	subs[ch] = id
+	mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			mu.Lock()
+			delete(subs, ch)
+			mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish numera ev y lo entrega sin bloquear: si el buffer de un
+// suscriptor está lleno, ese suscriptor pierde el evento.
+func Publish(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	seq++
+	ev.Seq = seq
+	for ch, id := range subs {
+		if id != "" && id != ev.ID {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package ext2
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// FreeRun es una racha de bloques libres consecutivos en bm_block.
+type FreeRun struct {
+	Start  int32
+	Length int32
+}
+
+// FreeRuns devuelve las rachas libres del bitmap en orden de inicio.
+func FreeRuns(bm []byte) []FreeRun {
+	var out []FreeRun
+	for i := 0; i < len(bm); {
+		if bm[i] != 0 {
+			i++
+			continue
+		}
+		j := i
+		for j < len(bm) && bm[j] == 0 {
+			j++
+		}
+		out = append(out, FreeRun{Start: int32(i), Length: int32(j - i)})
+		i = j
+	}
+	return out
+}
+
+func normFit(fit byte) byte {
+	switch fit {
+	case 'b', 'B':
+		return 'b'
+	case 'w', 'W':
+		return 'w'
+	default:
+		return 'f'
+	}
+}
+
+func runDistance(r FreeRun, near int32) int32 {
+	if near < 0 {
+		return r.Start
+	}
+	d := r.Start - near
+	if d < 0 {
+		d = -d
+	}
+	return d
+}
+
+// pickRun elige la racha para n bloques igual que fdisk elige hueco:
+// first fit = primera racha suficiente a partir de near (circular),
+// best fit = la menor que alcance, worst fit = la mayor. Empates por
+// cercanía a near. Devuelve -1 si ninguna alcanza.
+func pickRun(runs []FreeRun, n int32, near int32, fit byte) int {
+	best := -1
+	switch normFit(fit) {
+	case 'b', 'w':
+		worst := normFit(fit) == 'w'
+		for i, r := range runs {
+			if r.Length < n {
+				continue
+			}
+			if best < 0 {
+				best = i
+				continue
+			}
+			b := runs[best]
+			switch {
+			case !worst && r.Length < b.Length, worst && r.Length > b.Length:
+				best = i
+			case r.Length == b.Length && runDistance(r, near) < runDistance(b, near):
+				best = i
+			}
+		}
+	default:
+		for i, r := range runs {
+			if r.Length >= n && r.Start >= near {
+				return i
+			}
+		}
+		for i, r := range runs {
+			if r.Length >= n {
+				return i
+			}
+		}
+	}
+	return best
+}
+
+// allocBlocks reserva n bloques buscando que queden contiguos cerca de near.
+// Si ninguna racha alcanza, completa con las rachas más grandes (el archivo
+// queda fragmentado pero se escribe).
+func allocBlocks(sb *SuperBloque, bmBl []byte, n int, near int32, fit byte) ([]int32, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if int(sb.SFreeBlocksCount) < n {
+		return nil, errors.New("sin bloques libres suficientes")
+	}
+	runs := FreeRuns(bmBl)
+
+	var out []int32
+	if i := pickRun(runs, int32(n), near, fit); i >= 0 {
+		for k := int32(0); k < int32(n); k++ {
+			out = append(out, runs[i].Start+k)
+		}
+	} else {
+		sort.SliceStable(runs, func(a, b int) bool { return runs[a].Length > runs[b].Length })
+		for _, r := range runs {
+			for k := int32(0); k < r.Length && len(out) < n; k++ {
+				out = append(out, r.Start+k)
+			}
+			if len(out) == n {
+				break
+			}
+		}
+		if len(out) < n {
+			return nil, errors.New("sin bloques libres suficientes")
+		}
+		sort.Slice(out, func(a, b int) bool { return out[a] < out[b] })
+	}
+
+	claimBlocks(sb, bmBl, out)
+	return out, nil
+}
+
+// claimBlocks marca como usados bloques ya elegidos y corrige la pista del SB.
+func claimBlocks(sb *SuperBloque, bmBl []byte, blocks []int32) {
+	for _, b := range blocks {
+		MarkBlock(bmBl, b, true)
+		sb.SFreeBlocksCount--
+	}
+	if h := sb.SFirstBlo; h < 0 || int(h) >= len(bmBl) || bmBl[h] != 0 {
+		sb.SFirstBlo = FirstFreeFrom(bmBl, h)
+	}
+}
+
+// lastBlockOf devuelve el último bloque asignado del inodo (o -1).
+func lastBlockOf(ino Inodo) int32 {
+	last := int32(-1)
+	for _, p := range ino.IBlock {
+		if p >= 0 {
+			last = p
+		}
+	}
+	return last
+}
+
+// nearDirBlock es la pista de ubicación para archivos nuevos: junto al
+// último bloque de la carpeta padre.
+func nearDirBlock(mp *mount.MountedPartition, sb SuperBloque, dirIno int32) int32 {
+	ino, err := readInodeAt(mp, sb, dirIno)
+	if err != nil {
+		return -1
+	}
+	return lastBlockOf(ino)
+}
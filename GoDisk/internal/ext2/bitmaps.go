@@ -2,12 +2,18 @@ package ext2
 
 var (
 	ErrPartTooSmall = fmtError("ext2: partición demasiado pequeña")
+	ErrNotExist     = fmtError("no existe")
+	ErrPermission   = fmtError("permiso denegado")
+	ErrExist        = fmtError("ya existe")
 )
 
 type fmtError string
 
 func (e fmtError) Error() string { return string(e) }
 
+// Los bitmaps se manejan en memoria con 1 byte por entrada; el formato en
+// disco (bytes o bits empaquetados) lo resuelven EncodeBitmap/DecodeBitmap.
+
 func NewBitmaps(nIn, nBl int32) ([]byte, []byte) {
 	return make([]byte, nIn), make([]byte, nBl)
 }
@@ -26,10 +32,112 @@ func MarkBlock(bm []byte, idx int32, used bool) {
 	}
 }
 func FirstFree(bm []byte) int32 {
-	for i := range bm {
+	return FirstFreeFrom(bm, 0)
+}
+
+// FirstFreeFrom busca la primera entrada libre a partir de hint, dando la
+// vuelta al inicio si hace falta.
+func FirstFreeFrom(bm []byte, hint int32) int32 {
+	n := int32(len(bm))
+	if hint < 0 || hint >= n {
+		hint = 0
+	}
+	for i := hint; i < n; i++ {
 		if bm[i] == 0 {
-			return int32(i)
+			return i
+		}
+	}
+	for i := int32(0); i < hint; i++ {
+		if bm[i] == 0 {
+			return i
 		}
 	}
 	return -1
 }
+
+// ===== Reserva / liberación con pistas del SB =====
+// SFirtsIno / SFirstBlo se mantienen como la primera entrada libre, así la
+// búsqueda arranca ahí en lugar de recorrer el bitmap desde cero.
+
+func allocInode(sb *SuperBloque, bmIn []byte) int32 {
+	idx := FirstFreeFrom(bmIn, sb.SFirtsIno)
+	if idx < 0 {
+		return -1
+	}
+	MarkInode(bmIn, idx, true)
+	sb.SFreeInodesCount--
+	sb.SFirtsIno = FirstFreeFrom(bmIn, idx)
+	return idx
+}
+
+func allocBlock(sb *SuperBloque, bmBl []byte) int32 {
+	idx := FirstFreeFrom(bmBl, sb.SFirstBlo)
+	if idx < 0 {
+		return -1
+	}
+	MarkBlock(bmBl, idx, true)
+	sb.SFreeBlocksCount--
+	sb.SFirstBlo = FirstFreeFrom(bmBl, idx)
+	return idx
+}
+
+func freeInode(sb *SuperBloque, bmIn []byte, idx int32) {
+	MarkInode(bmIn, idx, false)
+	sb.SFreeInodesCount++
+	if sb.SFirtsIno < 0 || idx < sb.SFirtsIno {
+		sb.SFirtsIno = idx
+	}
+}
+
+func freeBlock(sb *SuperBloque, bmBl []byte, idx int32) {
+	MarkBlock(bmBl, idx, false)
+	sb.SFreeBlocksCount++
+	if sb.SFirstBlo < 0 || idx < sb.SFirstBlo {
+		sb.SFirstBlo = idx
+	}
+}
+
+// ===== Formato en disco =====
+
+// PackedBitmapLen es el tamaño en disco de un bitmap empaquetado de n entradas.
+func PackedBitmapLen(n int32) int64 {
+	return (int64(n) + 7) / 8
+}
+
+// BitmapDiskLen devuelve cuántos bytes ocupa en disco un bitmap de n entradas
+// según el formato registrado en el SB.
+func BitmapDiskLen(sb SuperBloque, n int32) int64 {
+	if sb.HasFeature(FeaturePackedBitmaps) {
+		return PackedBitmapLen(n)
+	}
+	return int64(n)
+}
+
+// EncodeBitmap convierte el bitmap en memoria (1 byte por entrada) al formato
+// en disco del SB.
+func EncodeBitmap(sb SuperBloque, bm []byte) []byte {
+	if !sb.HasFeature(FeaturePackedBitmaps) {
+		return bm
+	}
+	out := make([]byte, PackedBitmapLen(int32(len(bm))))
+	for i, v := range bm {
+		if v != 0 {
+			out[i/8] |= 1 << (uint(i) % 8)
+		}
+	}
+	return out
+}
+
+// DecodeBitmap expande raw (formato en disco) a n entradas de 1 byte.
+func DecodeBitmap(sb SuperBloque, raw []byte, n int32) []byte {
+	if !sb.HasFeature(FeaturePackedBitmaps) {
+		return raw
+	}
+	bm := make([]byte, n)
+	for i := range bm {
+		if raw[i/8]&(1<<(uint(i)%8)) != 0 {
+			bm[i] = 1
+		}
+	}
+	return bm
+}
@@ -0,0 +1,60 @@
+package ext2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBitmapRoundTrip(t *testing.T) {
+	packed := SuperBloque{SFeatures: FeaturePackedBitmaps}
+	plain := SuperBloque{}
+
+	cases := []struct {
+		name string
+		sb   SuperBloque
+		used []int32
+		n    int32
+		disk int64
+	}{
+		{"packed vacío", packed, nil, 16, 2},
+		{"packed una entrada", packed, []int32{0}, 1, 1},
+		{"packed borde de byte", packed, []int32{7, 8}, 9, 2},
+		{"packed no múltiplo de 8", packed, []int32{0, 3, 12, 99}, 100, 13},
+		{"packed lleno", packed, seq(64), 64, 8},
+		{"byte por entrada", plain, []int32{0, 5, 9}, 10, 10},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bm := make([]byte, c.n)
+			for _, i := range c.used {
+				MarkBlock(bm, i, true)
+			}
+			raw := EncodeBitmap(c.sb, bm)
+			if int64(len(raw)) != c.disk || BitmapDiskLen(c.sb, c.n) != c.disk {
+				t.Fatalf("largo en disco = %d (BitmapDiskLen %d), se esperaba %d", len(raw), BitmapDiskLen(c.sb, c.n), c.disk)
+			}
+			got := DecodeBitmap(c.sb, raw, c.n)
+			if !bytes.Equal(got, bm) {
+				t.Fatalf("DecodeBitmap(EncodeBitmap(bm)) = %v, se esperaba %v", got, bm)
+			}
+		})
+	}
+}
+
+func TestPackedBitmapLayout(t *testing.T) {
+	sb := SuperBloque{SFeatures: FeaturePackedBitmaps}
+	bm := make([]byte, 10)
+	MarkInode(bm, 0, true)
+	MarkInode(bm, 9, true)
+	if got, want := EncodeBitmap(sb, bm), []byte{0x01, 0x02}; !bytes.Equal(got, want) {
+		t.Fatalf("EncodeBitmap = %x, se esperaba %x (bit i%%8 del byte i/8)", got, want)
+	}
+}
+
+func seq(n int32) []int32 {
+	out := make([]int32, n)
+	for i := range out {
+		out[i] = int32(i)
+	}
+	return out
+}
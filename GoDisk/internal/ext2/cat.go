@@ -12,10 +12,11 @@ func ReadFileByPath(reg *mount.Registry, id, absPath string) (Inodo, []byte, err
 	if !ok {
 		return Inodo{}, nil, fmt.Errorf("cat: id %s no está montado", id)
 	}
+	defer lockR(&mp)()
 
 	// SB
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return Inodo{}, nil, fmt.Errorf("cat: leyendo SB: %w", err)
 	}
 
@@ -64,11 +65,8 @@ func ReadFileByPath(reg *mount.Registry, id, absPath string) (Inodo, []byte, err
 		return Inodo{}, nil, fmt.Errorf("cat: '%s' no es un archivo", absPath)
 	}
 
-	// Leer contenido según i_size usando punteros directos
-	total := int(ino.ISize)
-	if total < 0 {
-		total = 0
-	}
+	// Leer contenido según i_size (o lo guardado, si va comprimido)
+	total := ino.StoredSize()
 	var out []byte
 	for _, p := range ino.IBlock {
 		if p < 0 {
@@ -83,8 +81,9 @@ func ReadFileByPath(reg *mount.Registry, id, absPath string) (Inodo, []byte, err
 			break
 		}
 	}
-	if len(out) > total {
-		out = out[:total]
+	out, err = DecodeFileData(ino, out)
+	if err != nil {
+		return Inodo{}, nil, fmt.Errorf("cat: %w", err)
 	}
 	return ino, out, nil
 }
@@ -0,0 +1,159 @@
+package ext2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// Con FeatureChecksums, mkfs reserva después del área de bloques una tabla
+// con el CRC32C de cada inodo y de cada bloque (4 bytes por entrada: primero
+// los inodos y luego los bloques). Las escrituras de io.go la mantienen y
+// las lecturas la verifican. Una entrada en cero significa "sin registrar"
+// (la estructura nunca se escribió desde mkfs).
+
+const checksumLen = 4
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ChecksumError indica que un inodo o bloque no coincide con su checksum.
+type ChecksumError struct {
+	Kind     string // "inodo" | "bloque"
+	Index    int32
+	Stored   uint32
+	Computed uint32
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("ext2: %s %d corrupto (checksum %08x, calculado %08x)", e.Kind, e.Index, e.Stored, e.Computed)
+}
+
+// ChecksumPerInode es lo que cuesta la tabla por cada inodo (y sus 3 bloques).
+func ChecksumPerInode(opts MkfsOptions) int64 {
+	if !opts.Checksums {
+		return 0
+	}
+	return 4 * checksumLen
+}
+
+func ChecksumTableStart(sb SuperBloque) int64 {
+	return sb.SBlockStart + int64(sb.SBlocksCount)*int64(BlockSize)
+}
+
+func ChecksumTableLen(sb SuperBloque) int64 {
+	if !sb.HasFeature(FeatureChecksums) {
+		return 0
+	}
+	return int64(sb.SInodesCount+sb.SBlocksCount) * checksumLen
+}
+
+// FSEnd devuelve dónde termina el FS dentro de la partición: bloques, tablas
+// de checksums y referencias y copias del superbloque.
+func FSEnd(sb SuperBloque) int64 {
+	return RefcountTableStart(sb) + RefcountTableLen(sb) + SuperBackupsLen(OptionsFromSuperBlock(sb))
+}
+
+// sumOf calcula el CRC32C; el 0 queda reservado para "sin registrar".
+func sumOf(raw []byte) uint32 {
+	if c := crc32.Checksum(raw, castagnoli); c != 0 {
+		return c
+	}
+	return 1
+}
+
+// checksumOff devuelve la entrada de la tabla para el inodo o bloque idx
+// (-1 si el FS no tiene checksums o idx está fuera de rango).
+func checksumOff(sb SuperBloque, kind string, idx int32) int64 {
+	if !sb.HasFeature(FeatureChecksums) || idx < 0 {
+		return -1
+	}
+	switch {
+	case kind == "inodo" && idx < sb.SInodesCount:
+		return ChecksumTableStart(sb) + int64(idx)*checksumLen
+	case kind == "bloque" && idx < sb.SBlocksCount:
+		return ChecksumTableStart(sb) + int64(sb.SInodesCount+idx)*checksumLen
+	}
+	return -1
+}
+
+// verifyRaw compara raw con la entrada guardada.
+func verifyRaw(mp *mount.MountedPartition, sb SuperBloque, kind string, idx int32, raw []byte) error {
+	off := checksumOff(sb, kind, idx)
+	if off < 0 {
+		return nil
+	}
+	var stored uint32
+	if err := readAt(mp.DiskPath, mp.Start+off, &stored); err != nil {
+		return err
+	}
+	if stored == 0 {
+		return nil
+	}
+	if got := sumOf(raw); got != stored {
+		return &ChecksumError{Kind: kind, Index: idx, Stored: stored, Computed: got}
+	}
+	return nil
+}
+
+// readChecked lee la estructura idx en off y verifica su checksum.
+func readChecked(mp *mount.MountedPartition, sb SuperBloque, kind string, idx int32, off int64, data any) error {
+	raw, err := readRawChecked(mp, sb, kind, idx, off, binary.Size(data))
+	if err != nil {
+		return err
+	}
+	return binary.Read(bytes.NewReader(raw), binary.LittleEndian, data)
+}
+
+func readRawChecked(mp *mount.MountedPartition, sb SuperBloque, kind string, idx int32, off int64, n int) ([]byte, error) {
+	raw, err := readBytes(mp.DiskPath, off, n)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyRaw(mp, sb, kind, idx, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// writeChecked escribe la estructura idx en off y actualiza su checksum.
+func writeChecked(mp *mount.MountedPartition, sb SuperBloque, kind string, idx int32, off int64, data any) error {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		return err
+	}
+	return writeRawChecked(mp, sb, kind, idx, off, buf.Bytes())
+}
+
+func writeRawChecked(mp *mount.MountedPartition, sb SuperBloque, kind string, idx int32, off int64, raw []byte) error {
+	if err := writeBytes(mp.DiskPath, off, raw); err != nil {
+		return err
+	}
+	if c := checksumOff(sb, kind, idx); c >= 0 {
+		return writeAt(mp.DiskPath, mp.Start+c, sumOf(raw))
+	}
+	return nil
+}
+
+// ResetChecksums deja la tabla en cero (todo "sin registrar"); la usan mkfs
+// y loss.
+func ResetChecksums(mp *mount.MountedPartition, sb SuperBloque) error {
+	n := ChecksumTableLen(sb)
+	if n == 0 {
+		return nil
+	}
+	return writeBytes(mp.DiskPath, mp.Start+ChecksumTableStart(sb), make([]byte, n))
+}
+
+// WriteInode y WriteBlock exponen las escrituras con checksum para los
+// formateadores de otros paquetes (ext3).
+func WriteInode(mp *mount.MountedPartition, sb SuperBloque, idx int32, ino Inodo) error {
+	return writeInodeAt(mp, sb, idx, ino)
+}
+
+func WriteBlock(mp *mount.MountedPartition, sb SuperBloque, blk int32, data any) error {
+	off := mp.Start + sb.SBlockStart + int64(blk)*int64(BlockSize)
+	return writeChecked(mp, sb, "bloque", blk, off, data)
+}
@@ -30,8 +30,9 @@ func Chmod(reg *mount.Registry, id, absPath string, perms [3]byte, recursive boo
 	if !ok {
 		return fmt.Errorf("chmod: id %s no está montado", id)
 	}
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	defer lockW(&mp)()
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("chmod: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "chmod"); err != nil {
@@ -58,7 +59,7 @@ func Chmod(reg *mount.Registry, id, absPath string, perms [3]byte, recursive boo
 			return err
 		}
 		if !exists {
-			return fmt.Errorf("chmod: ruta no existe: %s", absPath)
+			return fmt.Errorf("chmod: ruta %w: %s", ErrNotExist, absPath)
 		}
 		idx = i
 	}
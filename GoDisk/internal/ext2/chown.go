@@ -15,8 +15,9 @@ func Chown(reg *mount.Registry, id, startPath, newUser string, recursive bool, a
 	if !ok {
 		return fmt.Errorf("chown: id %s no está montado", id)
 	}
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	defer lockW(&mp)()
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("chown: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "chown"); err != nil {
@@ -41,11 +42,11 @@ func Chown(reg *mount.Registry, id, startPath, newUser string, recursive bool, a
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("chown: ruta no existe: %s", startPath)
+		return fmt.Errorf("chown: ruta %w: %s", ErrNotExist, startPath)
 	}
 
 	// Resolver UID de 'newUser' desde users.txt
-	newUID, err := lookupUserUID(reg, id, newUser)
+	newUID, err := lookupUserUID(mp, newUser)
 	if err != nil {
 		return err
 	}
@@ -97,12 +98,12 @@ func Chown(reg *mount.Registry, id, startPath, newUser string, recursive bool, a
 
 // lookupUserUID lee users.txt y devuelve el UID de un usuario activo.
 // Error si no existe o está eliminado (UID=0).
-func lookupUserUID(reg *mount.Registry, id, user string) (int, error) {
+func lookupUserUID(mp *mount.MountedPartition, user string) (int, error) {
 	user = strings.TrimSpace(user)
 	if user == "" {
 		return 0, errors.New("chown: -usuario vacío")
 	}
-	txt, err := ReadUsersText(reg, id)
+	txt, err := readUsersText(mp)
 	if err != nil {
 		return 0, fmt.Errorf("chown: %w", err)
 	}
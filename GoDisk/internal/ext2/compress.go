@@ -0,0 +1,169 @@
+package ext2
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// Compresión por archivo: con InodeFlagCompressed el contenido se guarda en
+// los bloques como DEFLATE crudo. ISize sigue siendo el tamaño lógico y
+// IStored lo que ocupa el flujo comprimido. writeDataToFileInode comprime y
+// los lectores (cat, copy, reports) descomprimen; edit y copy heredan la
+// bandera del inodo.
+
+// requireInodeFlags rechaza la compresión en particiones con el formato de
+// inodo original, que no tiene dónde guardar la bandera.
+func requireInodeFlags(sb SuperBloque, op string) error {
+	if !sb.HasFeature(FeatureInodeFlags) {
+		return fmt.Errorf("%s: la partición usa el formato de inodo original (sin compresión); re-formatéala con mkfs", op)
+	}
+	return nil
+}
+
+func (ino Inodo) Compressed() bool { return ino.IFlags&InodeFlagCompressed != 0 }
+
+// StoredSize devuelve cuántos bytes de los bloques son contenido.
+func (ino Inodo) StoredSize() int {
+	n := ino.ISize
+	if ino.Compressed() {
+		n = ino.IStored
+	}
+	return int(max(n, 0))
+}
+
+// packData devuelve lo que hay que guardar en bloques para data.
+func packData(ino Inodo, data []byte) ([]byte, error) {
+	if !ino.Compressed() || len(data) == 0 {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeFileData recibe el contenido crudo de los bloques de ino (al menos
+// StoredSize bytes) y devuelve el contenido lógico.
+func DecodeFileData(ino Inodo, raw []byte) ([]byte, error) {
+	if n := ino.StoredSize(); len(raw) > n {
+		raw = raw[:n]
+	}
+	if !ino.Compressed() || ino.ISize <= 0 {
+		return raw, nil
+	}
+	out, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(raw)), int64(ino.ISize)))
+	if err != nil {
+		return nil, fmt.Errorf("ext2: descomprimiendo: %w", err)
+	}
+	if len(out) != int(ino.ISize) {
+		return nil, fmt.Errorf("ext2: descomprimiendo: %d bytes, se esperaban %d", len(out), ino.ISize)
+	}
+	return out, nil
+}
+
+// SetCompression activa o desactiva la compresión de un archivo y reescribe
+// su contenido en el formato nuevo.
+func SetCompression(reg *mount.Registry, id, absPath string, on bool, uid, gid int, isRoot bool) error {
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return fmt.Errorf("chattr: id %s no está montado", id)
+	}
+	defer lockW(&mp)()
+
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
+		return fmt.Errorf("chattr: leyendo SB: %w", err)
+	}
+	if err := requireSupportedFS(sb, "chattr"); err != nil {
+		return err
+	}
+	if on {
+		if err := requireInodeFlags(sb, "chattr"); err != nil {
+			return err
+		}
+	}
+
+	comps, err := splitPath(absPath)
+	if err != nil {
+		return err
+	}
+	if len(comps) == 0 {
+		return errors.New("chattr: ruta apunta a '/' (no es archivo)")
+	}
+	idx, exists, err := resolvePathInode(mp, sb, comps)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("chattr: %s no existe", absPath)
+	}
+	if idx == 1 {
+		return errors.New("chattr: users.txt no se puede comprimir")
+	}
+	ino, err := readInodeAt(mp, sb, idx)
+	if err != nil {
+		return err
+	}
+	if ino.IType != 1 {
+		return fmt.Errorf("chattr: %s no es un archivo", absPath)
+	}
+	if !canReadWrite(ino, uid, gid, isRoot) {
+		return fmt.Errorf("chattr: permisos insuficientes para '%s' (rw requeridos)", absPath)
+	}
+	if ino.Compressed() == on {
+		return nil
+	}
+
+	data, err := readDataFromFileInode(mp, sb, idx)
+	if err != nil {
+		return err
+	}
+	if !on && (len(data)+BlockSize-1)/BlockSize > InodeDirectCount {
+		return fmt.Errorf("chattr: %s no cabe sin comprimir (%d bytes)", absPath, len(data))
+	}
+	if err := setFileFlags(mp, sb, idx, InodeFlagCompressed, on); err != nil {
+		return err
+	}
+	bmIn, bmBl, err := loadBitmaps(mp, sb)
+	if err != nil {
+		return err
+	}
+	near := int32(-1)
+	if ino.IBlock[0] >= 0 {
+		near = ino.IBlock[0]
+	}
+	if err := writeDataToFileInode(mp, &sb, bmBl, idx, data, near); err != nil {
+		return err
+	}
+	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
+		return err
+	}
+	return WriteSuperBlock(mp, sb)
+}
+
+// setFileFlags prende o apaga flag en el inodo idx sin tocar el contenido.
+func setFileFlags(mp *mount.MountedPartition, sb SuperBloque, idx int32, flag byte, on bool) error {
+	ino, err := readInodeAt(mp, sb, idx)
+	if err != nil {
+		return err
+	}
+	if on {
+		ino.IFlags |= flag
+	} else {
+		ino.IFlags &^= flag
+	}
+	return writeInodeAt(mp, sb, idx, ino)
+}
@@ -17,8 +17,9 @@ func CopyNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 	if !ok {
 		return fmt.Errorf("copy: id %s no está montado", id)
 	}
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	defer lockW(&mp)()
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("copy: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "copy"); err != nil {
@@ -37,7 +38,7 @@ func CopyNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("copy: ruta origen no existe: %s", srcPath)
+		return fmt.Errorf("copy: ruta origen %w: %s", ErrNotExist, srcPath)
 	}
 	srcNode, err := readInodeAt(mp, sb, srcIno)
 	if err != nil {
@@ -57,7 +58,7 @@ func CopyNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("copy: carpeta destino no existe: %s", destDir)
+		return fmt.Errorf("copy: carpeta destino %w: %s", ErrNotExist, destDir)
 	}
 	dstNode, err := readInodeAt(mp, sb, dstIno)
 	if err != nil {
@@ -67,7 +68,7 @@ func CopyNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 		return fmt.Errorf("copy: -destino debe ser una carpeta: %s", destDir)
 	}
 	if !CanWrite(dstNode, uid, gid, isRoot) {
-		return fmt.Errorf("copy: sin permiso de escritura en carpeta destino")
+		return fmt.Errorf("copy: %w: sin escritura en carpeta destino", ErrPermission)
 	}
 
 	clean := func(comps []string) string {
@@ -99,12 +100,10 @@ func CopyNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 		if err := copyFileToNew(mp, &sb, bmIn, bmBl, srcIno, dstIno, baseName, uid, gid); err != nil {
 			return err
 		}
-		sb.SFirtsIno = FirstFree(bmIn)
-		sb.SFirstBlo = FirstFree(bmBl)
 		if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 			return err
 		}
-		return writeAt(mp.DiskPath, mp.Start, sb)
+		return WriteSuperBlock(mp, sb)
 	}
 
 	if err := copyDirToNewSkip(mp, &sb, bmIn, bmBl, srcIno, dstIno, baseName, uid, gid, isRoot, strings.TrimPrefix(srcAbs, "/")); err != nil {
@@ -115,19 +114,15 @@ func CopyNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 
 func copyDirToNewSkip(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byte, srcIno, dstParentIno int32, dstName string, uid, gid int, isRoot bool, srcAbs string) error {
 
-	newIdx := FirstFree(bmIn)
+	newIdx := allocInode(sb, bmIn)
 	if newIdx < 0 {
 		return errors.New("copy: no hay inodos libres para carpeta")
 	}
-	MarkInode(bmIn, newIdx, true)
-	sb.SFreeInodesCount--
 
-	blk := FirstFree(bmBl)
+	blk := allocBlock(sb, bmBl)
 	if blk < 0 {
 		return errors.New("copy: no hay bloques libres para carpeta")
 	}
-	MarkBlock(bmBl, blk, true)
-	sb.SFreeBlocksCount--
 
 	srcNode, _ := readInodeAt(mp, *sb, srcIno)
 	dir := newInodoCarpeta()
@@ -189,10 +184,8 @@ func copyDirToNewSkip(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []
 		}
 	}
 
-	sb.SFirtsIno = FirstFree(bmIn)
-	sb.SFirstBlo = FirstFree(bmBl)
 	if err := saveBitmaps(mp, *sb, bmIn, bmBl); err != nil {
 		return err
 	}
-	return writeAt(mp.DiskPath, mp.Start, *sb)
+	return WriteSuperBlock(mp, *sb)
 }
@@ -29,10 +29,7 @@ func readDataFromFileInode(mp *mount.MountedPartition, sb SuperBloque, idx int32
 	if ino.IType != 1 {
 		return nil, errors.New("readDataFromFileInode: inodo no es archivo")
 	}
-	sz := int(ino.ISize)
-	if sz < 0 {
-		sz = 0
-	}
+	sz := ino.StoredSize()
 	out := make([]byte, 0, sz)
 	rest := sz
 
@@ -51,7 +48,7 @@ func readDataFromFileInode(mp *mount.MountedPartition, sb SuperBloque, idx int32
 		out = append(out, bf.BContent[:n]...)
 		rest -= n
 	}
-	return out, nil
+	return DecodeFileData(ino, out)
 }
 
 // --- Estructura para enlistar hijos de un directorio
@@ -112,24 +109,26 @@ func copyFileToNew(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byt
 	if srcNode.IType != 1 {
 		return fmt.Errorf("copyFileToNew: origen no es archivo")
 	}
+	if sb.HasFeature(FeatureRefcounts) {
+		return shareFileToNew(mp, sb, bmIn, bmBl, srcNode, dstParentIno, dstName, uid, gid)
+	}
 	data, err := readDataFromFileInode(mp, *sb, srcIno)
 	if err != nil {
 		return err
 	}
 
 	// reservar inodo nuevo
-	newIdx := FirstFree(bmIn)
+	newIdx := allocInode(sb, bmIn)
 	if newIdx < 0 {
 		return errors.New("copy: no hay inodos libres para archivo")
 	}
-	MarkInode(bmIn, newIdx, true)
-	sb.SFreeInodesCount--
 
 	ino := newInodoArchivo(len(data))
 	ino.IUid = int32(uid)
 	ino.IGid = int32(gid)
 	ino.IType = 1
 	ino.IPerm = srcNode.IPerm
+	ino.IFlags = srcNode.IFlags & InodeFlagCompressed
 	for i := range ino.IBlock {
 		if ino.IBlock[i] == 0 {
 			ino.IBlock[i] = -1
@@ -140,7 +139,7 @@ func copyFileToNew(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byt
 	}
 
 	// escribir data (asigna bloques y descuenta del bitmap)
-	if err := writeDataToFileInode(mp, sb, bmBl, newIdx, data); err != nil {
+	if err := writeDataToFileInode(mp, sb, bmBl, newIdx, data, nearDirBlock(mp, *sb, dstParentIno)); err != nil {
 		return err
 	}
 
@@ -151,6 +150,36 @@ func copyFileToNew(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byt
 	return nil
 }
 
+// shareFileToNew crea la copia apuntando a los mismos bloques que el
+// origen; cada bloque gana una referencia y se separa al primer cambio.
+func shareFileToNew(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byte,
+	src Inodo, dstParentIno int32, dstName string, uid, gid int) error {
+
+	newIdx := allocInode(sb, bmIn)
+	if newIdx < 0 {
+		return errors.New("copy: no hay inodos libres para archivo")
+	}
+	ino := newInodoArchivo(int(src.ISize))
+	ino.IUid = int32(uid)
+	ino.IGid = int32(gid)
+	ino.IPerm = src.IPerm
+	ino.IFlags = src.IFlags & InodeFlagCompressed
+	ino.IStored = src.IStored
+	ino.IBlock = src.IBlock
+	for _, p := range ino.IBlock {
+		if p < 0 {
+			continue
+		}
+		if err := shareBlock(mp, *sb, p); err != nil {
+			return err
+		}
+	}
+	if err := writeInodeAt(mp, *sb, newIdx, ino); err != nil {
+		return err
+	}
+	return addDirEntry(mp, sb, bmBl, dstParentIno, dstName, newIdx)
+}
+
 func joinAbs(base, name string) string {
 	if base == "" || base == "/" {
 		return "/" + name
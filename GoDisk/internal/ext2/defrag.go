@@ -0,0 +1,286 @@
+package ext2
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// DefragTarget es un nodo (archivo o carpeta) a desfragmentar.
+type DefragTarget struct {
+	Path string
+	Ino  int32
+}
+
+// DefragResult resume lo hecho sobre un nodo.
+type DefragResult struct {
+	Moved   int  // bloques reubicados
+	Freed   int  // bloques liberados al compactar la carpeta
+	NoSpace bool // no hubo racha contigua suficiente; el nodo quedó igual
+}
+
+// DefragTargets lista absPath y todo su subárbol ordenado por número de
+// inodo; ese orden es el que usa el journal de ext3 para reanudar.
+func DefragTargets(reg *mount.Registry, id, absPath string) ([]DefragTarget, error) {
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return nil, fmt.Errorf("defrag: id %s no está montado", id)
+	}
+	defer lockR(&mp)()
+
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
+		return nil, fmt.Errorf("defrag: leyendo SB: %w", err)
+	}
+	if err := requireSupportedFS(sb, "defrag"); err != nil {
+		return nil, err
+	}
+	comps, err := splitPath(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("defrag: %w", err)
+	}
+	root, exists, err := resolvePathInode(mp, sb, comps)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("defrag: no existe %q", absPath)
+	}
+
+	var out []DefragTarget
+	seen := map[int32]bool{}
+	var walk func(idx int32, p string) error
+	walk = func(idx int32, p string) error {
+		if seen[idx] {
+			return nil
+		}
+		seen[idx] = true
+		out = append(out, DefragTarget{Path: p, Ino: idx})
+
+		ino, err := readInodeAt(mp, sb, idx)
+		if err != nil {
+			return err
+		}
+		if ino.IType != 0 {
+			return nil
+		}
+		children, err := listDirChildren(mp, sb, idx)
+		if err != nil {
+			return err
+		}
+		for _, ch := range children {
+			if err := walk(ch.Ino, joinAbs(p, ch.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root, absPath); err != nil {
+		return nil, fmt.Errorf("defrag: recorriendo %q: %w", absPath, err)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Ino < out[j].Ino })
+	return out, nil
+}
+
+// DefragInode deja contiguos los bloques del inodo idx y, si es carpeta,
+// compacta sus entradas quitando los huecos que deja removeDirEntry.
+// Si el inodo ya no está en uso (borrado mientras tanto) no hace nada.
+func DefragInode(reg *mount.Registry, id string, idx int32) (DefragResult, error) {
+	var res DefragResult
+
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return res, fmt.Errorf("defrag: id %s no está montado", id)
+	}
+	defer lockW(&mp)()
+
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
+		return res, fmt.Errorf("defrag: leyendo SB: %w", err)
+	}
+	if err := requireSupportedFS(sb, "defrag"); err != nil {
+		return res, err
+	}
+	bmIn, bmBl, err := loadBitmaps(mp, sb)
+	if err != nil {
+		return res, err
+	}
+	if idx < 0 || idx >= sb.SInodesCount || bmIn[idx] == 0 {
+		return res, nil
+	}
+	ino, err := readInodeAt(mp, sb, idx)
+	if err != nil {
+		return res, err
+	}
+	// Mover un bloque compartido dejaría al otro dueño apuntando al viejo.
+	if shared, err := hasSharedBlocks(mp, sb, ino); err != nil || shared {
+		return res, err
+	}
+
+	var old []int32
+	for _, p := range ino.IBlock {
+		if p >= 0 && p < sb.SBlocksCount {
+			old = append(old, p)
+		}
+	}
+	if len(old) == 0 {
+		return res, nil
+	}
+
+	// Contenido final en memoria, bloque por bloque.
+	isDir := ino.IType == 0
+	var folders []BlockFolder
+	var files []BlockFile
+	n := len(old)
+	changed := false
+
+	if isDir {
+		var ents []DirEntry
+		for _, b := range old {
+			bf, err := readFolderBlockAt(mp, sb, b)
+			if err != nil {
+				return res, err
+			}
+			for _, e := range bf.BContent {
+				if trimNull(e.BName[:]) == "" || e.BInodo < 0 {
+					continue
+				}
+				ents = append(ents, e)
+			}
+		}
+		n = (len(ents) + 3) / 4
+		if n == 0 {
+			n = 1
+		}
+		folders = make([]BlockFolder, n)
+		for i, e := range ents {
+			folders[i/4].BContent[i%4] = e
+		}
+		for i := range folders {
+			orig, err := readFolderBlockAt(mp, sb, old[i])
+			if err != nil {
+				return res, err
+			}
+			if orig != folders[i] {
+				changed = true
+			}
+		}
+		if n < len(old) {
+			changed = true
+		}
+	} else {
+		files = make([]BlockFile, n)
+		for i, b := range old {
+			bf, err := readFileBlockAt(mp, sb, b)
+			if err != nil {
+				return res, err
+			}
+			files[i] = bf
+		}
+	}
+
+	// Destino: en su lugar si los primeros n ya son contiguos; si no, una
+	// racha libre cerca del inicio actual; como último recurso, contando
+	// también los bloques propios como libres.
+	var dest []int32
+	if countRuns(old[:n]) == 1 {
+		if !changed {
+			return res, nil
+		}
+		dest = old[:n]
+	} else {
+		start := int32(-1)
+		runs := FreeRuns(bmBl)
+		if i := pickRun(runs, int32(n), old[0], mp.Fit); i >= 0 {
+			start = runs[i].Start
+		}
+		if start < 0 {
+			tmp := append([]byte(nil), bmBl...)
+			for _, b := range old {
+				tmp[b] = 0
+			}
+			runs = FreeRuns(tmp)
+			if i := pickRun(runs, int32(n), old[0], mp.Fit); i >= 0 {
+				start = runs[i].Start
+			}
+		}
+		if start < 0 {
+			res.NoSpace = true
+			return res, nil
+		}
+		for k := int32(0); k < int32(n); k++ {
+			dest = append(dest, start+k)
+		}
+	}
+
+	// Datos primero, luego inodo y por último bitmaps/SB.
+	for i, b := range dest {
+		if isDir {
+			err = writeFolderBlockAt(mp, sb, b, folders[i])
+		} else {
+			err = writeFileBlockAt(mp, sb, b, files[i])
+		}
+		if err != nil {
+			return res, err
+		}
+		if b != old[i] {
+			res.Moved++
+		}
+	}
+
+	w := 0
+	for i := range ino.IBlock {
+		if w < len(dest) {
+			ino.IBlock[i] = dest[w]
+			w++
+		} else {
+			ino.IBlock[i] = -1
+		}
+	}
+	if isDir {
+		ino.ISize = int32(n * BlockSize)
+	}
+	if err := writeInodeAt(mp, sb, idx, ino); err != nil {
+		return res, err
+	}
+
+	inDest := map[int32]bool{}
+	for _, b := range dest {
+		inDest[b] = true
+	}
+	inOld := map[int32]bool{}
+	for _, b := range old {
+		inOld[b] = true
+		if !inDest[b] {
+			freeBlock(&sb, bmBl, b)
+		}
+	}
+	var claim []int32
+	for _, b := range dest {
+		if !inOld[b] {
+			claim = append(claim, b)
+		}
+	}
+	claimBlocks(&sb, bmBl, claim)
+	res.Freed = len(old) - n
+
+	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
+		return res, err
+	}
+	return res, WriteSuperBlock(mp, sb)
+}
+
+// countRuns cuenta las rachas de bloques consecutivos en orden lógico.
+func countRuns(blocks []int32) int {
+	if len(blocks) == 0 {
+		return 0
+	}
+	n := 1
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i] != blocks[i-1]+1 {
+			n++
+		}
+	}
+	return n
+}
@@ -13,9 +13,10 @@ func EditFile(reg *mount.Registry, id, absPath string, data []byte, uid, gid int
 	if !ok {
 		return fmt.Errorf("edit: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("edit: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "edit"); err != nil {
@@ -62,17 +63,15 @@ func EditFile(reg *mount.Registry, id, absPath string, data []byte, uid, gid int
 		return err
 	}
 
-	if err := writeDataToFileInode(mp, &sb, bmBl, childIno, data); err != nil {
+	if err := writeDataToFileInode(mp, &sb, bmBl, childIno, data, nearDirBlock(mp, sb, parentIno)); err != nil {
 		return err
 	}
 
 	// Guardar bitmaps y SB (inodos no cambian de asignación)
-	sb.SFirtsIno = FirstFree(bmIn)
-	sb.SFirstBlo = FirstFree(bmBl)
 	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 		return err
 	}
-	return writeAt(mp.DiskPath, mp.Start, sb)
+	return WriteSuperBlock(mp, sb)
 }
 
 // findDirPath navega por directorios (sin crear). Devuelve el inodo del último directorio.
@@ -0,0 +1,404 @@
+package ext2
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// File da acceso por rangos a un archivo: lee y escribe sólo los bloques
+// que toca el rango. Cada llamada toma el candado de la partición y vuelve a
+// resolver la ruta, así que el valor no retiene estado del disco. Los
+// archivos comprimidos no se pueden tocar por bloques: se descomprimen,
+// se modifican en memoria y se reescriben completos.
+type File struct {
+	mp     *mount.MountedPartition
+	path   string
+	uid    int
+	gid    int
+	isRoot bool
+}
+
+// OpenFile valida que absPath exista y sea un archivo.
+func OpenFile(reg *mount.Registry, id, absPath string, uid, gid int, isRoot bool) (*File, error) {
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return nil, fmt.Errorf("file: id %s no está montado", id)
+	}
+	f := &File{mp: mp, path: absPath, uid: uid, gid: gid, isRoot: isRoot}
+	defer lockR(&f.mp)()
+	sb, err := f.superBlock()
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := f.resolve(sb); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) superBlock() (SuperBloque, error) {
+	sb, err := ReadSuperBlock(f.mp)
+	if err != nil {
+		return sb, fmt.Errorf("file: leyendo SB: %w", err)
+	}
+	return sb, requireSupportedFS(sb, "file")
+}
+
+// resolve devuelve el inodo del archivo y el de su carpeta.
+func (f *File) resolve(sb SuperBloque) (int32, Inodo, int32, error) {
+	comps, err := splitPath(f.path)
+	if err != nil {
+		return -1, Inodo{}, -1, err
+	}
+	if len(comps) == 0 {
+		return -1, Inodo{}, -1, errors.New("file: ruta apunta a '/' (no es archivo)")
+	}
+	parent, ok, err := resolvePathInode(f.mp, sb, comps[:len(comps)-1])
+	if err != nil {
+		return -1, Inodo{}, -1, err
+	}
+	idx := int32(-1)
+	if ok {
+		idx = lookupInDir(f.mp, sb, parent, comps[len(comps)-1])
+	}
+	if idx < 0 {
+		return -1, Inodo{}, -1, fmt.Errorf("file: %s: %w", f.path, ErrNotExist)
+	}
+	ino, err := readInodeAt(f.mp, sb, idx)
+	if err != nil {
+		return -1, Inodo{}, -1, err
+	}
+	if ino.IType != 1 {
+		return -1, Inodo{}, -1, fmt.Errorf("file: %s no es un archivo", f.path)
+	}
+	return idx, ino, parent, nil
+}
+
+// Size devuelve el tamaño lógico.
+func (f *File) Size() (int64, error) {
+	defer lockR(&f.mp)()
+	sb, err := f.superBlock()
+	if err != nil {
+		return 0, err
+	}
+	_, ino, _, err := f.resolve(sb)
+	return int64(max(ino.ISize, 0)), err
+}
+
+// ReadAt devuelve hasta n bytes desde off (menos si el archivo termina
+// antes; vacío si off está al final o más allá).
+func (f *File) ReadAt(off int64, n int) ([]byte, error) {
+	if off < 0 || n < 0 {
+		return nil, errors.New("file: rango inválido")
+	}
+	defer lockR(&f.mp)()
+	sb, err := f.superBlock()
+	if err != nil {
+		return nil, err
+	}
+	idx, ino, _, err := f.resolve(sb)
+	if err != nil {
+		return nil, err
+	}
+	if !CanRead(ino, f.uid, f.gid, f.isRoot) {
+		return nil, fmt.Errorf("file: %s: %w", f.path, ErrPermission)
+	}
+	size := int64(max(ino.ISize, 0))
+	end := min(off+int64(n), size)
+	if off >= end {
+		return []byte{}, nil
+	}
+	if ino.Compressed() {
+		data, err := readDataFromFileInode(f.mp, sb, idx)
+		if err != nil {
+			return nil, err
+		}
+		return data[off:end], nil
+	}
+
+	blocks := fileBlocks(ino)
+	out := make([]byte, 0, end-off)
+	for i := off / BlockSize; i*BlockSize < end; i++ {
+		if int(i) >= len(blocks) {
+			return nil, fmt.Errorf("file: %s tiene menos bloques que su tamaño", f.path)
+		}
+		b, err := readFileBlockAt(f.mp, sb, blocks[i])
+		if err != nil {
+			return nil, err
+		}
+		lo, hi := max(off-i*BlockSize, 0), min(end-i*BlockSize, BlockSize)
+		out = append(out, b.BContent[lo:hi]...)
+	}
+	return out, nil
+}
+
+// WriteAt escribe data desde off; si off queda después del final, el hueco
+// se rellena con ceros.
+func (f *File) WriteAt(off int64, data []byte) error {
+	if off < 0 {
+		return errors.New("file: offset negativo")
+	}
+	return f.modify(func(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, ino *Inodo, near int32) error {
+		return writeRange(mp, sb, bmBl, ino, off, data, near)
+	}, func(cur []byte) []byte {
+		return patchBytes(cur, off, data)
+	})
+}
+
+// Append agrega data al final.
+func (f *File) Append(data []byte) error {
+	return f.modify(func(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, ino *Inodo, near int32) error {
+		return writeRange(mp, sb, bmBl, ino, int64(max(ino.ISize, 0)), data, near)
+	}, func(cur []byte) []byte {
+		return append(cur, data...)
+	})
+}
+
+// Truncate deja el archivo en size bytes (rellena con ceros si crece).
+func (f *File) Truncate(size int64) error {
+	if size < 0 {
+		return errors.New("file: tamaño negativo")
+	}
+	return f.modify(func(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, ino *Inodo, near int32) error {
+		cur := int64(max(ino.ISize, 0))
+		if size >= cur {
+			return writeRange(mp, sb, bmBl, ino, cur, make([]byte, size-cur), near)
+		}
+		return shrinkTo(mp, sb, bmBl, ino, size)
+	}, func(cur []byte) []byte {
+		if size <= int64(len(cur)) {
+			return cur[:size]
+		}
+		return patchBytes(cur, size, nil)
+	})
+}
+
+type rangeOp func(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, ino *Inodo, near int32) error
+
+// modify resuelve el archivo con el candado exclusivo, aplica op sobre el
+// inodo y guarda inodo, bitmaps y SB. Para archivos comprimidos usa mem, que
+// transforma el contenido completo en memoria.
+func (f *File) modify(op rangeOp, mem func([]byte) []byte) error {
+	defer lockW(&f.mp)()
+	sb, err := f.superBlock()
+	if err != nil {
+		return err
+	}
+	idx, ino, parent, err := f.resolve(sb)
+	if err != nil {
+		return err
+	}
+	if !CanWrite(ino, f.uid, f.gid, f.isRoot) {
+		return fmt.Errorf("file: %s: %w (w requerido)", f.path, ErrPermission)
+	}
+	bmIn, bmBl, err := loadBitmaps(f.mp, sb)
+	if err != nil {
+		return err
+	}
+	near := nearDirBlock(f.mp, sb, parent)
+
+	if ino.Compressed() {
+		data, err := readDataFromFileInode(f.mp, sb, idx)
+		if err != nil {
+			return err
+		}
+		if err := writeDataToFileInode(f.mp, &sb, bmBl, idx, mem(data), near); err != nil {
+			return err
+		}
+	} else {
+		if err := op(f.mp, &sb, bmBl, &ino, near); err != nil {
+			return err
+		}
+		if err := writeInodeAt(f.mp, sb, idx, ino); err != nil {
+			return err
+		}
+	}
+	if err := saveBitmaps(f.mp, sb, bmIn, bmBl); err != nil {
+		return err
+	}
+	return WriteSuperBlock(f.mp, sb)
+}
+
+// patchBytes copia data sobre cur en off, creciendo con ceros si hace falta.
+func patchBytes(cur []byte, off int64, data []byte) []byte {
+	if end := off + int64(len(data)); end > int64(len(cur)) {
+		cur = append(cur, make([]byte, end-int64(len(cur)))...)
+	}
+	copy(cur[off:], data)
+	return cur
+}
+
+// fileBlocks devuelve los bloques del archivo en orden lógico.
+func fileBlocks(ino Inodo) []int32 {
+	var out []int32
+	for _, p := range ino.IBlock {
+		if p >= 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// setFileBlocks deja blocks al inicio de IBlock.
+func setFileBlocks(ino *Inodo, blocks []int32) {
+	for i := range ino.IBlock {
+		ino.IBlock[i] = -1
+		if i < len(blocks) {
+			ino.IBlock[i] = blocks[i]
+		}
+	}
+}
+
+// privateBlock devuelve el bloque i listo para escribir: si está compartido
+// lo copia a uno nuevo y suelta la referencia al viejo.
+func privateBlock(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, blocks []int32, i int) (BlockFile, error) {
+	b, err := readFileBlockAt(mp, *sb, blocks[i])
+	if err != nil {
+		return b, err
+	}
+	n, err := blockRefs(mp, *sb, blocks[i])
+	if err != nil || n == 0 {
+		return b, err
+	}
+	nb, err := allocBlocks(sb, bmBl, 1, blocks[i], mp.Fit)
+	if err != nil {
+		return b, fmt.Errorf("file: %w para copiar bloque compartido", err)
+	}
+	if err := setBlockRefs(mp, *sb, blocks[i], n-1); err != nil {
+		return b, err
+	}
+	blocks[i] = nb[0]
+	return b, nil
+}
+
+// writeRange escribe data en [off, off+len(data)) tocando sólo esos
+// bloques (y los del hueco, si off está después del final).
+func writeRange(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, ino *Inodo, off int64, data []byte, near int32) error {
+	size := int64(max(ino.ISize, 0))
+	end := off + int64(len(data))
+	newSize := max(size, end)
+	want := int((newSize + BlockSize - 1) / BlockSize)
+	if want > InodeDirectCount {
+		return fmt.Errorf("file: tamaño excede apuntadores directos (%d*%d)", InodeDirectCount, BlockSize)
+	}
+
+	blocks := fileBlocks(*ino)
+	have := len(blocks)
+	if want > have {
+		if have > 0 {
+			near = blocks[have-1] + 1
+		}
+		nb, err := allocBlocks(sb, bmBl, want-have, near, mp.Fit)
+		if err != nil {
+			return fmt.Errorf("file: %w para archivo", err)
+		}
+		blocks = append(blocks, nb...)
+	}
+
+	// Los bytes después de ISize en el último bloque ya son ceros (todas las
+	// escrituras rellenan), así que de los bloques existentes sólo se tocan
+	// los del rango; los nuevos se escriben siempre, con ceros en el hueco.
+	for i := min(off/BlockSize, int64(have)); i < int64(want); i++ {
+		base := i * BlockSize
+		lo, hi := max(off, base), min(end, base+BlockSize)
+		if int(i) < have && lo >= hi {
+			continue
+		}
+		var b BlockFile
+		if int(i) < have {
+			var err error
+			if b, err = privateBlock(mp, sb, bmBl, blocks, int(i)); err != nil {
+				return err
+			}
+		}
+		if lo < hi {
+			copy(b.BContent[lo-base:hi-base], data[lo-off:hi-off])
+		}
+		if err := writeFileBlockAt(mp, *sb, blocks[i], b); err != nil {
+			return err
+		}
+	}
+	setFileBlocks(ino, blocks)
+	ino.ISize = int32(newSize)
+	return nil
+}
+
+// shrinkTo suelta los bloques que sobran y limpia la cola del último, para
+// que crecer después lea ceros.
+func shrinkTo(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, ino *Inodo, size int64) error {
+	blocks := fileBlocks(*ino)
+	keep := int((size + BlockSize - 1) / BlockSize)
+	for _, b := range blocks[keep:] {
+		if err := releaseBlock(mp, sb, bmBl, b); err != nil {
+			return err
+		}
+	}
+	blocks = blocks[:keep]
+	if tail := size % BlockSize; tail != 0 {
+		b, err := privateBlock(mp, sb, bmBl, blocks, keep-1)
+		if err != nil {
+			return err
+		}
+		clear(b.BContent[tail:])
+		if err := writeFileBlockAt(mp, *sb, blocks[keep-1], b); err != nil {
+			return err
+		}
+	}
+	setFileBlocks(ino, blocks)
+	ino.ISize = int32(size)
+	return nil
+}
+
+// Reader expone el archivo como io.ReadSeeker (para http.ServeContent).
+func (f *File) Reader() (io.ReadSeeker, error) {
+	size, err := f.Size()
+	if err != nil {
+		return nil, err
+	}
+	return io.NewSectionReader(readerAt{f}, 0, size), nil
+}
+
+type readerAt struct{ f *File }
+
+func (r readerAt) ReadAt(p []byte, off int64) (int, error) {
+	b, err := r.f.ReadAt(off, len(p))
+	if err != nil {
+		return 0, err
+	}
+	n := copy(p, b)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// StatPath devuelve el inodo de absPath (archivo o carpeta).
+func StatPath(reg *mount.Registry, id, absPath string) (Inodo, error) {
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return Inodo{}, fmt.Errorf("stat: id %s no está montado", id)
+	}
+	defer lockR(&mp)()
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
+		return Inodo{}, fmt.Errorf("stat: leyendo SB: %w", err)
+	}
+	if err := requireSupportedFS(sb, "stat"); err != nil {
+		return Inodo{}, err
+	}
+	comps, err := splitPath(absPath)
+	if err != nil {
+		return Inodo{}, err
+	}
+	idx, ok, err := resolvePathInode(mp, sb, comps)
+	if err != nil {
+		return Inodo{}, err
+	}
+	if !ok {
+		return Inodo{}, fmt.Errorf("stat: %s: %w", absPath, ErrNotExist)
+	}
+	return readInodeAt(mp, sb, idx)
+}
@@ -15,8 +15,9 @@ func Find(reg *mount.Registry, id, startPath, pattern string, uid, gid int, isRo
 	if !ok {
 		return nil, fmt.Errorf("find: id %s no está montado", id)
 	}
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	defer lockR(&mp)()
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return nil, fmt.Errorf("find: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "find"); err != nil {
@@ -3,7 +3,7 @@ package ext2
 import "errors"
 
 func requireSupportedFS(sb SuperBloque, op string) error {
-	if sb.SMagic != MagicEXT2 {
+	if !ValidMagic(sb.SMagic) {
 		return errors.New(op + ": superbloque inválido (magic)")
 	}
 	if sb.SFilesystemType != FileSystemType && sb.SFilesystemType != FileSystemTypeEXT3 {
@@ -1,88 +1,125 @@
 package ext2
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
-	"io"
-	"os"
 	"strings"
 
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
 // ========== Lectura / Escritura cruda en offset ==========
+// Todo pasa por diskio, que mantiene un descriptor por disco.
 
 func readAt(path string, off int64, data any) error {
-	f, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return err
-	}
-	return binary.Read(f, binary.LittleEndian, data)
+	return diskio.ReadAt(path, off, data)
 }
 
 func writeAt(path string, off int64, data any) error {
-	f, err := os.OpenFile(path, os.O_RDWR, 0o666)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return err
-	}
-	return binary.Write(f, binary.LittleEndian, data)
+	return diskio.WriteAt(path, off, data)
 }
 
 func readBytes(path string, off int64, n int) ([]byte, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
+	return diskio.ReadBytes(path, off, n)
+}
+
+func writeBytes(path string, off int64, buf []byte) error {
+	return diskio.WriteBytes(path, off, buf)
+}
+
+// lockW / lockR toman el candado de la partición montada y, si un fdisk
+// -move la reubicó, dejan en mp la entrada nueva; uso:
+//
+//	defer lockW(&mp)()
+func lockW(mp **mount.MountedPartition) func() {
+	return mount.Lock(mp, true)
+}
+
+func lockR(mp **mount.MountedPartition) func() {
+	return mount.Lock(mp, false)
+}
+
+// ========== Lectura / Escritura de estructuras EXT2 ==========
+
+// InodeSize devuelve lo que ocupa un inodo en disco: sin FeatureInodeFlags
+// (formato original) el inodo termina antes de IFlags.
+func InodeSize(sb SuperBloque) int64 {
+	if sb.HasFeature(FeatureInodeFlags) {
+		return sizeof(Inodo{})
+	}
+	return sizeof(Inodo{}) - sizeof(byte(0)) - sizeof(int32(0))
+}
+
+// inodeOff ubica el inodo idx; rechaza tablas cuyo SInodeS no sea el del
+// formato, para no escribir un inodo encima del siguiente.
+func inodeOff(mp *mount.MountedPartition, sb SuperBloque, idx int32) (int64, error) {
+	if n := InodeSize(sb); int64(sb.SInodeS) != n {
+		return 0, fmt.Errorf("ext2: tamaño de inodo %d en el superbloque, el formato usa %d", sb.SInodeS, n)
 	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
+	return mp.Start + sb.SInodeStart + int64(idx)*int64(sb.SInodeS), nil
+}
+
+// decodeInode decodifica un inodo de InodeSize bytes; los campos que el
+// formato no guarda quedan en cero.
+func decodeInode(raw []byte) (Inodo, error) {
+	var ino Inodo
+	full := make([]byte, sizeof(ino))
+	copy(full, raw)
+	err := binary.Read(bytes.NewReader(full), binary.LittleEndian, &ino)
+	return ino, err
+}
+
+func encodeInode(sb SuperBloque, ino Inodo) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, ino); err != nil {
 		return nil, err
 	}
-	buf := make([]byte, n)
-	_, err = io.ReadFull(f, buf)
-	return buf, err
+	return buf.Bytes()[:InodeSize(sb)], nil
 }
 
-func writeBytes(path string, off int64, buf []byte) error {
-	f, err := os.OpenFile(path, os.O_RDWR, 0o666)
+func readInodeAt(mp *mount.MountedPartition, sb SuperBloque, idx int32) (Inodo, error) {
+	off, err := inodeOff(mp, sb, idx)
 	if err != nil {
-		return err
+		return Inodo{}, err
 	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return err
+	raw, err := readRawChecked(mp, sb, "inodo", idx, off, int(InodeSize(sb)))
+	if err != nil {
+		return Inodo{}, err
 	}
-	_, err = f.Write(buf)
-	return err
+	return decodeInode(raw)
 }
 
-// ========== Lectura / Escritura de estructuras EXT2 ==========
-
-func readInodeAt(mp *mount.MountedPartition, sb SuperBloque, idx int32) (Inodo, error) {
-	var ino Inodo
-	off := mp.Start + sb.SInodeStart + int64(idx)*int64(sb.SInodeS)
-	if err := readAt(mp.DiskPath, off, &ino); err != nil {
+// ReadInode lee el inodo idx sin verificar su checksum (para reportes).
+func ReadInode(mp *mount.MountedPartition, sb SuperBloque, idx int32) (Inodo, error) {
+	off, err := inodeOff(mp, sb, idx)
+	if err != nil {
 		return Inodo{}, err
 	}
-	return ino, nil
+	raw, err := readBytes(mp.DiskPath, off, int(InodeSize(sb)))
+	if err != nil {
+		return Inodo{}, err
+	}
+	return decodeInode(raw)
 }
 
 func writeInodeAt(mp *mount.MountedPartition, sb SuperBloque, idx int32, ino Inodo) error {
-	off := mp.Start + sb.SInodeStart + int64(idx)*int64(sb.SInodeS)
-	return writeAt(mp.DiskPath, off, ino)
+	off, err := inodeOff(mp, sb, idx)
+	if err != nil {
+		return err
+	}
+	raw, err := encodeInode(sb, ino)
+	if err != nil {
+		return err
+	}
+	return writeRawChecked(mp, sb, "inodo", idx, off, raw)
 }
 
 func readFolderBlockAt(mp *mount.MountedPartition, sb SuperBloque, blk int32) (BlockFolder, error) {
 	var b BlockFolder
 	off := mp.Start + sb.SBlockStart + int64(blk)*int64(BlockSize)
-	if err := readAt(mp.DiskPath, off, &b); err != nil {
+	if err := readChecked(mp, sb, "bloque", blk, off, &b); err != nil {
 		return BlockFolder{}, err
 	}
 	return b, nil
@@ -90,13 +127,13 @@ func readFolderBlockAt(mp *mount.MountedPartition, sb SuperBloque, blk int32) (B
 
 func writeFolderBlockAt(mp *mount.MountedPartition, sb SuperBloque, blk int32, b BlockFolder) error {
 	off := mp.Start + sb.SBlockStart + int64(blk)*int64(BlockSize)
-	return writeAt(mp.DiskPath, off, b)
+	return writeChecked(mp, sb, "bloque", blk, off, b)
 }
 
 func readFileBlockAt(mp *mount.MountedPartition, sb SuperBloque, blk int32) (BlockFile, error) {
 	var b BlockFile
 	off := mp.Start + sb.SBlockStart + int64(blk)*int64(BlockSize)
-	if err := readAt(mp.DiskPath, off, &b); err != nil {
+	if err := readChecked(mp, sb, "bloque", blk, off, &b); err != nil {
 		return BlockFile{}, err
 	}
 	return b, nil
@@ -104,31 +141,29 @@ func readFileBlockAt(mp *mount.MountedPartition, sb SuperBloque, blk int32) (Blo
 
 func writeFileBlockAt(mp *mount.MountedPartition, sb SuperBloque, blk int32, b BlockFile) error {
 	off := mp.Start + sb.SBlockStart + int64(blk)*int64(BlockSize)
-	return writeAt(mp.DiskPath, off, b)
+	return writeChecked(mp, sb, "bloque", blk, off, b)
 }
 
-// ========== Bitmaps (modelo 1 byte por entrada) ==========
+// ========== Bitmaps ==========
+// En memoria siempre 1 byte por entrada; en disco según SFeatures.
 
 func loadBitmaps(mp *mount.MountedPartition, sb SuperBloque) ([]byte, []byte, error) {
-	szIn := int(sb.SInodesCount)
-	szBl := int(sb.SBlocksCount)
-
-	bmIn, err := readBytes(mp.DiskPath, mp.Start+sb.SBmInodeStart, szIn)
+	rawIn, err := readBytes(mp.DiskPath, mp.Start+sb.SBmInodeStart, int(BitmapDiskLen(sb, sb.SInodesCount)))
 	if err != nil {
 		return nil, nil, fmt.Errorf("ext2: leyendo bm_inode: %w", err)
 	}
-	bmBl, err := readBytes(mp.DiskPath, mp.Start+sb.SBmBlockStart, szBl)
+	rawBl, err := readBytes(mp.DiskPath, mp.Start+sb.SBmBlockStart, int(BitmapDiskLen(sb, sb.SBlocksCount)))
 	if err != nil {
 		return nil, nil, fmt.Errorf("ext2: leyendo bm_block: %w", err)
 	}
-	return bmIn, bmBl, nil
+	return DecodeBitmap(sb, rawIn, sb.SInodesCount), DecodeBitmap(sb, rawBl, sb.SBlocksCount), nil
 }
 
 func saveBitmaps(mp *mount.MountedPartition, sb SuperBloque, bmIn, bmBl []byte) error {
-	if err := writeBytes(mp.DiskPath, mp.Start+sb.SBmInodeStart, bmIn); err != nil {
+	if err := writeBytes(mp.DiskPath, mp.Start+sb.SBmInodeStart, EncodeBitmap(sb, bmIn)); err != nil {
 		return fmt.Errorf("ext2: escribiendo bm_inode: %w", err)
 	}
-	if err := writeBytes(mp.DiskPath, mp.Start+sb.SBmBlockStart, bmBl); err != nil {
+	if err := writeBytes(mp.DiskPath, mp.Start+sb.SBmBlockStart, EncodeBitmap(sb, bmBl)); err != nil {
 		return fmt.Errorf("ext2: escribiendo bm_block: %w", err)
 	}
 	return nil
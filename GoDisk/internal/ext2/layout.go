@@ -7,7 +7,76 @@ import (
 
 func sizeof[T any](v T) int64 { return int64(binary.Size(v)) }
 
+// MkfsOptions agrupa las opciones de formato; quedan registradas en
+// SFeatures para que lectores y recovery las respeten.
+type MkfsOptions struct {
+	PackedBitmaps     bool
+	BackupSuperblocks bool
+	Checksums         bool
+	Refcounts         bool
+}
+
+func (o MkfsOptions) Features() int32 {
+	var f int32
+	if o.PackedBitmaps {
+		f |= FeaturePackedBitmaps
+	}
+	if o.BackupSuperblocks {
+		f |= FeatureBackupSB
+	}
+	if o.Checksums {
+		f |= FeatureChecksums
+	}
+	if o.Refcounts {
+		f |= FeatureRefcounts
+	}
+	return f
+}
+
+// OptionsFromSuperBlock reconstruye las opciones con las que se formateó.
+func OptionsFromSuperBlock(sb SuperBloque) MkfsOptions {
+	return MkfsOptions{
+		PackedBitmaps:     sb.HasFeature(FeaturePackedBitmaps),
+		BackupSuperblocks: sb.HasFeature(FeatureBackupSB),
+		Checksums:         sb.HasFeature(FeatureChecksums),
+		Refcounts:         sb.HasFeature(FeatureRefcounts),
+	}
+}
+
+// BitmapRegionLens devuelve el tamaño en disco de bm_inode y bm_block para n
+// inodos (3n bloques).
+func BitmapRegionLens(n int32, opts MkfsOptions) (int64, int64) {
+	if opts.PackedBitmaps {
+		return PackedBitmapLen(n), PackedBitmapLen(3 * n)
+	}
+	return int64(n), 3 * int64(n)
+}
+
+// FitInodeCount calcula el mayor n tal que overhead + n*perInode + bitmaps
+// quepa en partSize.
+func FitInodeCount(partSize, overhead, perInode int64, opts MkfsOptions) int64 {
+	var n64 int64
+	if opts.PackedBitmaps {
+		// 4 bits de bitmap por inodo (1 del inodo + 3 de sus bloques)
+		n64 = 2 * (partSize - overhead) / (2*perInode + 1)
+	} else {
+		n64 = (partSize - overhead) / (perInode + 4)
+	}
+	for n64 > 0 {
+		in, bl := BitmapRegionLens(int32(n64), opts)
+		if overhead+n64*perInode+in+bl <= partSize {
+			break
+		}
+		n64--
+	}
+	return n64
+}
+
 func ComputeLayout(partSize int64) (int32, SuperBloque, error) {
+	return ComputeLayoutWith(partSize, MkfsOptions{})
+}
+
+func ComputeLayoutWith(partSize int64, opts MkfsOptions) (int32, SuperBloque, error) {
 	var sb SuperBloque
 	var dummySB SuperBloque
 	var dummyIn Inodo
@@ -16,18 +85,18 @@ func ComputeLayout(partSize int64) (int32, SuperBloque, error) {
 	szIn := sizeof(dummyIn)
 	szBlk := int64(BlockSize)
 
-	den := int64(4) + szIn + 3*szBlk
-	n64 := (partSize - szSB) / den
+	n64 := FitInodeCount(partSize, szSB+SuperBackupsLen(opts), szIn+3*szBlk+ChecksumPerInode(opts)+RefcountPerInode(opts), opts)
 	if n64 < 2 {
 		return 0, sb, ErrPartTooSmall
 	}
 	n := int32(n64)
+	bmInLen, bmBlLen := BitmapRegionLens(n, opts)
 
 	off := int64(0)
 	sbOff := off
 	bmInOff := sbOff + szSB
-	bmBlOff := bmInOff + int64(n)
-	inTblOff := bmBlOff + 3*int64(n)
+	bmBlOff := bmInOff + bmInLen
+	inTblOff := bmBlOff + bmBlLen
 	blkTblOff := inTblOff + int64(n)*szIn
 
 	sb = SuperBloque{
@@ -39,7 +108,7 @@ func ComputeLayout(partSize int64) (int32, SuperBloque, error) {
 		SMtime:           time.Now().Unix(),
 		SUmtime:          0,
 		SMntCount:        1,
-		SMagic:           MagicEXT2,
+		SMagic:           MagicEXT2v2,
 		SInodeS:          int32(szIn),
 		SBlockS:          int32(szBlk),
 		SFirtsIno:        0,
@@ -48,6 +117,7 @@ func ComputeLayout(partSize int64) (int32, SuperBloque, error) {
 		SBmBlockStart:    bmBlOff,
 		SInodeStart:      inTblOff,
 		SBlockStart:      blkTblOff,
+		SFeatures:        opts.Features() | FeatureInodeFlags,
 	}
 	return n, sb, nil
 }
@@ -0,0 +1,119 @@
+package ext2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// testdata/baseline.mia.gz lo generó la versión anterior a SFeatures con:
+//
+//	mkdisk -size=256 -unit=K, fdisk -size=160 -unit=K -name=p1, mount, mkfs,
+//	login root, mkdir -path=/old, mkfile -path=/old/f.txt -size=20
+func openBaseline(t *testing.T) (*mount.Registry, string) {
+	t.Helper()
+	gz, err := os.ReadFile(filepath.Join("testdata", "baseline.mia.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw bytes.Buffer
+	if _, err := raw.ReadFrom(zr); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "baseline.mia")
+	if err := os.WriteFile(path, raw.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { diskio.CloseHandle(path) })
+
+	reg := mount.NewRegistry()
+	id, err := mount.NewService(reg).Mount(path, "p1")
+	if err != nil {
+		t.Fatalf("mount: %v", err)
+	}
+	return reg, id
+}
+
+func TestBaselineImage(t *testing.T) {
+	reg, id := openBaseline(t)
+	mp, _ := reg.GetByID(id)
+
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
+		t.Fatalf("ReadSuperBlock: %v", err)
+	}
+	if !sb.Legacy() || sb.SFeatures != 0 || InodeSize(sb) != 100 {
+		t.Fatalf("SB legacy=%v features=%#x inodo=%d; se esperaba formato original", sb.Legacy(), sb.SFeatures, InodeSize(sb))
+	}
+
+	users, err := ReadUsersText(reg, id)
+	if err != nil || !strings.Contains(users, "1, U, root, root, 123") {
+		t.Fatalf("users.txt = %q, %v", users, err)
+	}
+	if _, data, err := ReadFileByPath(reg, id, "/old/f.txt"); err != nil || string(data) != "01234567890123456789" {
+		t.Fatalf("/old/f.txt = %q, %v", data, err)
+	}
+
+	if err := MakeDir(reg, id, "/nueva", false, 1, 1); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	want := bytes.Repeat([]byte("abc"), 40)
+	if err := CreateOrOverwriteFile(reg, id, "/nueva/x.txt", want, false, false, false, 1, 1); err != nil {
+		t.Fatalf("mkfile: %v", err)
+	}
+	if err := CreateOrOverwriteFile(reg, id, "/nueva/z.txt", want, false, false, true, 1, 1); err == nil {
+		t.Fatal("mkfile -compress en un inodo original debería fallar")
+	}
+	if _, data, err := ReadFileByPath(reg, id, "/nueva/x.txt"); err != nil || !bytes.Equal(data, want) {
+		t.Fatalf("/nueva/x.txt = %q, %v", data, err)
+	}
+	if _, data, err := ReadFileByPath(reg, id, "/old/f.txt"); err != nil || string(data) != "01234567890123456789" {
+		t.Fatalf("/old/f.txt tras escribir = %q, %v", data, err)
+	}
+	if after, err := ReadUsersText(reg, id); err != nil || after != users {
+		t.Fatalf("users.txt cambió: %q, %v", after, err)
+	}
+
+	// Los bitmaps siguen siendo de un byte por entrada y cuadran con los
+	// contadores del SB.
+	sb, err = ReadSuperBlock(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sb.Legacy() {
+		t.Fatal("escribir no debe cambiar el formato del SB")
+	}
+	bmIn, bmBl, err := loadBitmaps(mp, sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name        string
+		bm          []byte
+		total, free int32
+	}{
+		{"inodos", bmIn, sb.SInodesCount, sb.SFreeInodesCount},
+		{"bloques", bmBl, sb.SBlocksCount, sb.SFreeBlocksCount},
+	} {
+		used := int32(0)
+		for i, v := range c.bm {
+			if v > 1 {
+				t.Fatalf("bitmap de %s: entrada %d = %d, se esperaba 0/1", c.name, i, v)
+			}
+			used += int32(v)
+		}
+		if used != c.total-c.free {
+			t.Fatalf("bitmap de %s: %d en uso, el SB dice %d", c.name, used, c.total-c.free)
+		}
+	}
+}
@@ -12,10 +12,11 @@ func MakeDir(reg *mount.Registry, id, absPath string, p bool, uid, gid int) erro
 	if !ok {
 		return fmt.Errorf("mkdir: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 
 	// Leer superbloque
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("mkdir: leyendo SB: %w", err)
 	}
 
@@ -57,30 +58,26 @@ func MakeDir(reg *mount.Registry, id, absPath string, p bool, uid, gid int) erro
 			return err
 		}
 		if ino.IType != 0 {
-			return fmt.Errorf("mkdir: '%s' ya existe y no es una carpeta", absPath)
+			return fmt.Errorf("mkdir: '%s' %w y no es una carpeta", absPath, ErrExist)
 		}
 		// Es carpeta ya existente
 		if p {
 			return nil
 		}
-		return fmt.Errorf("mkdir: '%s' ya existe", absPath)
+		return fmt.Errorf("mkdir: '%s' %w", absPath, ErrExist)
 	}
 
 	//  Crear nuevo inodo de carpeta
-	inIdx := FirstFree(bmIn)
+	inIdx := allocInode(&sb, bmIn)
 	if inIdx < 0 {
 		return errors.New("mkdir: no hay inodos libres")
 	}
-	MarkInode(bmIn, inIdx, true)
-	sb.SFreeInodesCount--
 
 	// Reservar bloque de carpeta
-	blk := FirstFree(bmBl)
+	blk := allocBlock(&sb, bmBl)
 	if blk < 0 {
 		return errors.New("mkdir: no hay bloques libres")
 	}
-	MarkBlock(bmBl, blk, true)
-	sb.SFreeBlocksCount--
 
 	// Inodo carpeta
 	dir := newInodoCarpeta()
@@ -113,10 +110,8 @@ func MakeDir(reg *mount.Registry, id, absPath string, p bool, uid, gid int) erro
 		return err
 	}
 
-	sb.SFirtsIno = FirstFree(bmIn)
-	sb.SFirstBlo = FirstFree(bmBl)
 	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 		return err
 	}
-	return writeAt(mp.DiskPath, mp.Start, sb)
+	return WriteSuperBlock(mp, sb)
 }
@@ -10,19 +10,29 @@ import (
 )
 
 // GoDisk/internal/ext2/mkfile.go
-func CreateOrOverwriteFile(reg *mount.Registry, id, absPath string, data []byte, recursive, force bool, uid, gid int) error {
+//
+// CreateOrOverwriteFile crea o reescribe absPath; con compress el archivo
+// queda marcado para guardarse comprimido (si ya existía y compress es
+// false conserva su bandera).
+func CreateOrOverwriteFile(reg *mount.Registry, id, absPath string, data []byte, recursive, force, compress bool, uid, gid int) error {
 	mp, ok := reg.GetByID(id)
 	if !ok {
 		return fmt.Errorf("mkfile: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("mkfile: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "mkfile"); err != nil {
 		return err
 	}
+	if compress {
+		if err := requireInodeFlags(sb, "mkfile"); err != nil {
+			return err
+		}
+	}
 
 	comps, err := splitPath(absPath)
 	if err != nil {
@@ -54,30 +64,34 @@ func CreateOrOverwriteFile(reg *mount.Registry, id, absPath string, data []byte,
 			return fmt.Errorf("mkfile: %s ya existe; usa -force para sobreescribir", absPath)
 		}
 		// Overwrite: ESCRIBIR UNA SOLA VEZ y listo
-		if err := writeDataToFileInode(mp, &sb, bmBl, childIno, data); err != nil {
+		if compress {
+			if err := setFileFlags(mp, sb, childIno, InodeFlagCompressed, true); err != nil {
+				return err
+			}
+		}
+		if err := writeDataToFileInode(mp, &sb, bmBl, childIno, data, nearDirBlock(mp, sb, parentIno)); err != nil {
 			return err
 		}
-		sb.SFirtsIno = FirstFree(bmIn)
-		sb.SFirstBlo = FirstFree(bmBl)
 		if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 			return err
 		}
-		return writeAt(mp.DiskPath, mp.Start, sb)
+		return WriteSuperBlock(mp, sb)
 	}
 
 	// Crear nuevo archivo
-	inIdx := FirstFree(bmIn)
+	inIdx := allocInode(&sb, bmIn)
 	if inIdx < 0 {
 		return errors.New("mkfile: no hay inodos libres")
 	}
-	MarkInode(bmIn, inIdx, true)
-	sb.SFreeInodesCount--
 
 	ino := newInodoArchivo(len(data))
 	ino.IUid = int32(uid)
 	ino.IGid = int32(gid)
 	ino.IType = 1
 	ino.IPerm = [3]byte{6, 6, 4}
+	if compress {
+		ino.IFlags |= InodeFlagCompressed
+	}
 	for i := range ino.IBlock {
 		if ino.IBlock[i] == 0 {
 			ino.IBlock[i] = -1
@@ -89,7 +103,7 @@ func CreateOrOverwriteFile(reg *mount.Registry, id, absPath string, data []byte,
 		return err
 	}
 
-	if err := writeDataToFileInode(mp, &sb, bmBl, inIdx, data); err != nil {
+	if err := writeDataToFileInode(mp, &sb, bmBl, inIdx, data, nearDirBlock(mp, sb, parentIno)); err != nil {
 		return err
 	}
 
@@ -97,12 +111,10 @@ func CreateOrOverwriteFile(reg *mount.Registry, id, absPath string, data []byte,
 		return err
 	}
 
-	sb.SFirtsIno = FirstFree(bmIn)
-	sb.SFirstBlo = FirstFree(bmBl)
 	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 		return err
 	}
-	return writeAt(mp.DiskPath, mp.Start, sb)
+	return WriteSuperBlock(mp, sb)
 }
 
 func splitPath(p string) ([]string, error) {
@@ -159,20 +171,16 @@ func ensureDirPath(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byt
 			return -1, fmt.Errorf("carpeta faltante '%s'; usa -r para crear padres", strings.Join(comps[:i+1], "/"))
 		}
 		// crear carpeta
-		newIdx := FirstFree(bmIn)
+		newIdx := allocInode(sb, bmIn)
 		if newIdx < 0 {
 			return -1, errors.New("sin inodos libres para carpeta")
 		}
-		MarkInode(bmIn, newIdx, true)
-		sb.SFreeInodesCount--
 
 		// reservar bloque para carpeta
-		blk := FirstFree(bmBl)
+		blk := allocBlock(sb, bmBl)
 		if blk < 0 {
 			return -1, errors.New("sin bloques libres para carpeta")
 		}
-		MarkBlock(bmBl, blk, true)
-		sb.SFreeBlocksCount--
 
 		// inodo carpeta
 		dir := newInodoCarpeta()
@@ -257,12 +265,10 @@ func addDirEntry(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, dirIn
 		_ = bi
 	}
 
-	newBlk := FirstFree(bmBl)
+	newBlk := allocBlock(sb, bmBl)
 	if newBlk < 0 {
 		return errors.New("addDirEntry: no hay bloques libres")
 	}
-	MarkBlock(bmBl, newBlk, true)
-	sb.SFreeBlocksCount--
 
 	var fb BlockFolder
 	copy(fb.BContent[0].BName[:], []byte(name))
@@ -281,7 +287,9 @@ func addDirEntry(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, dirIn
 	return errors.New("addDirEntry: sin punteros directos libres en directorio")
 }
 
-func writeDataToFileInode(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, idx int32, data []byte) error {
+// writeDataToFileInode reescribe el contenido del inodo; near es la pista
+// de ubicación para los bloques nuevos (ver allocBlocks), -1 si no importa.
+func writeDataToFileInode(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, idx int32, data []byte, near int32) error {
 	ino, err := readInodeAt(mp, *sb, idx)
 	if err != nil {
 		return err
@@ -292,6 +300,14 @@ func writeDataToFileInode(mp *mount.MountedPartition, sb *SuperBloque, bmBl []by
 			ino.IBlock[i] = -1
 		}
 	}
+	// Copy-on-write: lo compartido se reemplaza por bloques propios.
+	if err := unshareBlocks(mp, *sb, &ino); err != nil {
+		return err
+	}
+	logical := len(data)
+	if data, err = packData(ino, data); err != nil {
+		return fmt.Errorf("mkfile: comprimiendo: %w", err)
+	}
 	want := (len(data) + BlockSize - 1) / BlockSize
 	if want == 0 && len(data) > 0 {
 		want = 1
@@ -307,15 +323,15 @@ func writeDataToFileInode(mp *mount.MountedPartition, sb *SuperBloque, bmBl []by
 
 	if want > len(cur) {
 		add := want - len(cur)
-		for i := 0; i < add; i++ {
-			b := FirstFree(bmBl)
-			if b < 0 {
-				return errors.New("mkfile: sin bloques libres para archivo")
-			}
-			MarkBlock(bmBl, b, true)
-			sb.SFreeBlocksCount--
-			cur = append(cur, b)
+		// Crecer pegado al último bloque; si es archivo nuevo, junto al padre.
+		if len(cur) > 0 {
+			near = cur[len(cur)-1] + 1
 		}
+		blks, err := allocBlocks(sb, bmBl, add, near, mp.Fit)
+		if err != nil {
+			return fmt.Errorf("mkfile: %w para archivo", err)
+		}
+		cur = append(cur, blks...)
 
 		w := 0
 		for i := range ino.IBlock {
@@ -331,8 +347,7 @@ func writeDataToFileInode(mp *mount.MountedPartition, sb *SuperBloque, bmBl []by
 
 	if want < len(cur) {
 		for i := want; i < len(cur); i++ {
-			MarkBlock(bmBl, cur[i], false)
-			sb.SFreeBlocksCount++
+			freeBlock(sb, bmBl, cur[i])
 		}
 		cur = cur[:want]
 
@@ -359,6 +374,10 @@ func writeDataToFileInode(mp *mount.MountedPartition, sb *SuperBloque, bmBl []by
 			return err
 		}
 	}
-	ino.ISize = int32(len(data))
+	ino.ISize = int32(logical)
+	ino.IStored = 0
+	if ino.Compressed() {
+		ino.IStored = int32(len(data))
+	}
 	return writeInodeAt(mp, *sb, idx, ino)
 }
@@ -13,22 +13,32 @@ type Formatter struct {
 func NewFormatter(reg *mount.Registry) *Formatter { return &Formatter{reg: reg} }
 
 func (f *Formatter) MkfsFull(id string) error {
+	return f.MkfsWith(id, MkfsOptions{BackupSuperblocks: true, Refcounts: true})
+}
+
+func (f *Formatter) MkfsWith(id string, opts MkfsOptions) error {
 	mp, ok := f.reg.GetByID(id)
 	if !ok {
 		return fmt.Errorf("mkfs: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 
-	partStart := mp.Start
 	partSize := mp.Size
 
-	_, sb, err := ComputeLayout(partSize)
+	_, sb, err := ComputeLayoutWith(partSize, opts)
 	if err != nil {
 		return err
 	}
 
-	if err := writeAt(mp.DiskPath, partStart, sb); err != nil {
+	if err := WriteSuperBlock(mp, sb); err != nil {
 		return fmt.Errorf("mkfs: error escribiendo superbloque: %w", err)
 	}
+	if err := ResetChecksums(mp, sb); err != nil {
+		return fmt.Errorf("mkfs: inicializando checksums: %w", err)
+	}
+	if err := ResetRefcounts(mp, sb); err != nil {
+		return fmt.Errorf("mkfs: inicializando referencias: %w", err)
+	}
 
 	bmIn, bmBl := NewBitmaps(sb.SInodesCount, sb.SBlocksCount)
 
@@ -37,16 +47,13 @@ func (f *Formatter) MkfsFull(id string) error {
 	MarkInode(bmIn, 1, true)
 	MarkBlock(bmBl, 1, true)
 
-	if err := writeBytes(mp.DiskPath, partStart+sb.SBmInodeStart, bmIn); err != nil {
-		return err
-	}
-	if err := writeBytes(mp.DiskPath, partStart+sb.SBmBlockStart, bmBl); err != nil {
+	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 		return err
 	}
 
 	inoRoot := newInodoCarpeta()
 	inoRoot.IBlock[0] = 0
-	if err := writeAt(mp.DiskPath, partStart+sb.SInodeStart+0*int64(sb.SInodeS), inoRoot); err != nil {
+	if err := writeInodeAt(mp, sb, 0, inoRoot); err != nil {
 		return err
 	}
 
@@ -55,15 +62,15 @@ func (f *Formatter) MkfsFull(id string) error {
 	contentLen := len([]byte(usersBootstrap))
 	inoUsers := newInodoArchivo(contentLen)
 	inoUsers.IBlock[0] = 1
-	if err := writeAt(mp.DiskPath, partStart+sb.SInodeStart+1*int64(sb.SInodeS), inoUsers); err != nil {
+	if err := writeInodeAt(mp, sb, 1, inoUsers); err != nil {
 		return err
 	}
 
 	rootBlk := buildRootBlock()
-	if err := writeAt(mp.DiskPath, partStart+sb.SBlockStart+0*int64(BlockSize), rootBlk); err != nil {
+	if err := WriteBlock(mp, sb, 0, rootBlk); err != nil {
 		return err
 	}
-	if err := writeAt(mp.DiskPath, partStart+sb.SBlockStart+1*int64(BlockSize), users); err != nil {
+	if err := WriteBlock(mp, sb, 1, users); err != nil {
 		return err
 	}
 
@@ -71,7 +78,7 @@ func (f *Formatter) MkfsFull(id string) error {
 	sb.SFreeBlocksCount = sb.SBlocksCount - 2
 	sb.SFirtsIno = FirstFree(bmIn)
 	sb.SFirstBlo = FirstFree(bmBl)
-	if err := writeAt(mp.DiskPath, partStart, sb); err != nil {
+	if err := WriteSuperBlock(mp, sb); err != nil {
 		return err
 	}
 
@@ -14,8 +14,9 @@ func MoveNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 	if !ok {
 		return fmt.Errorf("move: id %s no está montado", id)
 	}
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	defer lockW(&mp)()
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("move: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "move"); err != nil {
@@ -49,7 +50,7 @@ func MoveNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 		return err
 	}
 	if !exists {
-		return fmt.Errorf("move: origen no existe: %s", srcPath)
+		return fmt.Errorf("move: origen %w: %s", ErrNotExist, srcPath)
 	}
 	srcNode, err := readInodeAt(mp, sb, srcIno)
 	if err != nil {
@@ -57,7 +58,7 @@ func MoveNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 	}
 	// Solo escritura sobre el ORIGEN
 	if !CanWrite(srcNode, uid, gid, isRoot) {
-		return errors.New("move: sin permiso de escritura sobre el origen")
+		return fmt.Errorf("move: %w: sin escritura sobre el origen", ErrPermission)
 	}
 
 	// Resolver padre de origen y nombre base
@@ -81,7 +82,7 @@ func MoveNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 		return err
 	}
 	if !dstExists {
-		return fmt.Errorf("move: carpeta destino no existe: %s", destDir)
+		return fmt.Errorf("move: carpeta destino %w: %s", ErrNotExist, destDir)
 	}
 	dstNode, err := readInodeAt(mp, sb, dstIno)
 	if err != nil {
@@ -104,7 +105,7 @@ func MoveNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 
 	// Colisión en destino
 	if lookupInDir(mp, sb, dstIno, baseName) >= 0 {
-		return fmt.Errorf("move: ya existe '%s' en '%s'", baseName, destDir)
+		return fmt.Errorf("move: %w '%s' en '%s'", ErrExist, baseName, destDir)
 	}
 
 	// Cargar bitmaps (puede necesitar nuevo bloque en carpeta destino)
@@ -124,12 +125,10 @@ func MoveNode(reg *mount.Registry, id, srcPath, destDir string, uid, gid int, is
 	}
 
 	// Persistir cambios
-	sb.SFirtsIno = FirstFree(bmIn)
-	sb.SFirstBlo = FirstFree(bmBl)
 	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 		return err
 	}
-	return writeAt(mp.DiskPath, mp.Start, sb)
+	return WriteSuperBlock(mp, sb)
 }
 
 // true si "candidate" está dentro del subárbol cuyo raíz es "ancestor"
@@ -0,0 +1,156 @@
+package ext2
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// Con FeatureRefcounts, mkfs reserva después de la tabla de checksums un
+// uint16 por bloque con las referencias EXTRA que tiene (0 = un solo dueño).
+// copy comparte los bloques de datos en vez de duplicarlos; antes de
+// escribir un archivo se sueltan sus bloques compartidos (copy-on-write) y
+// liberar un bloque compartido sólo descuenta una referencia.
+
+const refcountLen = 2
+
+// RefcountPerInode es lo que cuesta la tabla por cada inodo (sus 3 bloques).
+func RefcountPerInode(opts MkfsOptions) int64 {
+	if !opts.Refcounts {
+		return 0
+	}
+	return 3 * refcountLen
+}
+
+func RefcountTableStart(sb SuperBloque) int64 {
+	return ChecksumTableStart(sb) + ChecksumTableLen(sb)
+}
+
+func RefcountTableLen(sb SuperBloque) int64 {
+	if !sb.HasFeature(FeatureRefcounts) {
+		return 0
+	}
+	return int64(sb.SBlocksCount) * refcountLen
+}
+
+func refOff(mp *mount.MountedPartition, sb SuperBloque, blk int32) int64 {
+	return mp.Start + RefcountTableStart(sb) + int64(blk)*refcountLen
+}
+
+// blockRefs devuelve las referencias extra del bloque (0 sin la tabla).
+func blockRefs(mp *mount.MountedPartition, sb SuperBloque, blk int32) (uint16, error) {
+	if !sb.HasFeature(FeatureRefcounts) || blk < 0 || blk >= sb.SBlocksCount {
+		return 0, nil
+	}
+	var n uint16
+	err := readAt(mp.DiskPath, refOff(mp, sb, blk), &n)
+	return n, err
+}
+
+func setBlockRefs(mp *mount.MountedPartition, sb SuperBloque, blk int32, n uint16) error {
+	return writeAt(mp.DiskPath, refOff(mp, sb, blk), n)
+}
+
+// ReadRefcounts devuelve la tabla completa (nil si el FS no la tiene).
+func ReadRefcounts(mp *mount.MountedPartition, sb SuperBloque) ([]uint16, error) {
+	n := RefcountTableLen(sb)
+	if n == 0 {
+		return nil, nil
+	}
+	raw, err := readBytes(mp.DiskPath, mp.Start+RefcountTableStart(sb), int(n))
+	if err != nil {
+		return nil, fmt.Errorf("ext2: leyendo refcounts: %w", err)
+	}
+	out := make([]uint16, sb.SBlocksCount)
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint16(raw[i*refcountLen:])
+	}
+	return out, nil
+}
+
+// ResetRefcounts deja todos los bloques con un solo dueño; la usan mkfs y
+// loss.
+func ResetRefcounts(mp *mount.MountedPartition, sb SuperBloque) error {
+	n := RefcountTableLen(sb)
+	if n == 0 {
+		return nil
+	}
+	return writeBytes(mp.DiskPath, mp.Start+RefcountTableStart(sb), make([]byte, n))
+}
+
+// shareBlock suma una referencia al bloque.
+func shareBlock(mp *mount.MountedPartition, sb SuperBloque, blk int32) error {
+	n, err := blockRefs(mp, sb, blk)
+	if err != nil {
+		return err
+	}
+	if n == 0xFFFF {
+		return fmt.Errorf("ext2: bloque %d con demasiadas referencias", blk)
+	}
+	return setBlockRefs(mp, sb, blk, n+1)
+}
+
+// releaseBlock suelta una referencia; si era la única libera el bloque.
+func releaseBlock(mp *mount.MountedPartition, sb *SuperBloque, bmBl []byte, blk int32) error {
+	n, err := blockRefs(mp, *sb, blk)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return setBlockRefs(mp, *sb, blk, n-1)
+	}
+	freeBlock(sb, bmBl, blk)
+	return nil
+}
+
+// hasSharedBlocks indica si algún bloque de ino tiene otro dueño.
+func hasSharedBlocks(mp *mount.MountedPartition, sb SuperBloque, ino Inodo) (bool, error) {
+	if !sb.HasFeature(FeatureRefcounts) {
+		return false, nil
+	}
+	for _, p := range ino.IBlock {
+		if p < 0 {
+			continue
+		}
+		n, err := blockRefs(mp, sb, p)
+		if err != nil || n > 0 {
+			return n > 0, err
+		}
+	}
+	return false, nil
+}
+
+// unshareBlocks quita de ino los bloques compartidos (descontando su
+// referencia) y deja los propios al inicio de IBlock, para que quien va a
+// reescribir el contenido asigne bloques nuevos en su lugar. No escribe el
+// inodo.
+func unshareBlocks(mp *mount.MountedPartition, sb SuperBloque, ino *Inodo) error {
+	if !sb.HasFeature(FeatureRefcounts) {
+		return nil
+	}
+	var own []int32
+	for _, p := range ino.IBlock {
+		if p < 0 {
+			continue
+		}
+		n, err := blockRefs(mp, sb, p)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			own = append(own, p)
+			continue
+		}
+		if err := setBlockRefs(mp, sb, p, n-1); err != nil {
+			return err
+		}
+	}
+	for i := range ino.IBlock {
+		ino.IBlock[i] = -1
+		if i < len(own) {
+			ino.IBlock[i] = own[i]
+		}
+	}
+	return nil
+}
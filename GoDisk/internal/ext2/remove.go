@@ -13,13 +13,14 @@ func Remove(reg *mount.Registry, id, absPath string, uid, gid int) error {
 	if !ok {
 		return fmt.Errorf("remove: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 	if absPath == "/" {
 		return errors.New("remove: no se puede eliminar '/'")
 	}
 
 	// SB
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("remove: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "remove"); err != nil {
@@ -52,7 +53,7 @@ func Remove(reg *mount.Registry, id, absPath string, uid, gid int) error {
 	// Resolver objetivo
 	targetIno := lookupInDir(mp, sb, parentIno, targetName)
 	if targetIno < 0 {
-		return fmt.Errorf("remove: no existe %q", absPath)
+		return fmt.Errorf("remove: %q: %w", absPath, ErrNotExist)
 	}
 	tIno, err := readInodeAt(mp, sb, targetIno)
 	if err != nil {
@@ -61,7 +62,7 @@ func Remove(reg *mount.Registry, id, absPath string, uid, gid int) error {
 
 	// Pre-chequeo de permisos en TODO el subárbol
 	if ok := subtreeWritable(mp, sb, targetIno, uid, gid); !ok {
-		return fmt.Errorf("remove: permiso denegado en algún elemento dentro de %q", absPath)
+		return fmt.Errorf("remove: %w en algún elemento dentro de %q", ErrPermission, absPath)
 	}
 
 	//  Borrado recursivo real
@@ -70,12 +71,10 @@ func Remove(reg *mount.Registry, id, absPath string, uid, gid int) error {
 	}
 
 	// Persistir bitmaps y SB
-	sb.SFirtsIno = FirstFree(bmIn)
-	sb.SFirstBlo = FirstFree(bmBl)
 	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 		return err
 	}
-	return writeAt(mp.DiskPath, mp.Start, sb)
+	return WriteSuperBlock(mp, sb)
 }
 
 // Recorre un path de carpetas que deben existir
@@ -188,8 +187,7 @@ func deleteNode(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byte,
 
 		for i, ptr := range ino.IBlock {
 			if ptr >= 0 {
-				MarkBlock(bmBl, ptr, false)
-				sb.SFreeBlocksCount++
+				freeBlock(sb, bmBl, ptr)
 				ino.IBlock[i] = -1
 			}
 		}
@@ -199,7 +197,7 @@ func deleteNode(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byte,
 		}
 	} else {
 
-		if err := writeDataToFileInode(mp, sb, bmBl, idx, []byte{}); err != nil {
+		if err := writeDataToFileInode(mp, sb, bmBl, idx, []byte{}, -1); err != nil {
 			return err
 		}
 	}
@@ -209,8 +207,7 @@ func deleteNode(mp *mount.MountedPartition, sb *SuperBloque, bmIn, bmBl []byte,
 	}
 
 	//  Liberar inodo
-	MarkInode(bmIn, idx, false)
-	sb.SFreeInodesCount++
+	freeInode(sb, bmIn, idx)
 
 	// ( limpiar el inodo en disco
 	var zero Inodo
@@ -14,9 +14,10 @@ func RenameNode(reg *mount.Registry, id, absPath, newName string, uid, gid int,
 	if !ok {
 		return fmt.Errorf("rename: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("rename: leyendo SB: %w", err)
 	}
 	if err := requireSupportedFS(sb, "rename"); err != nil {
@@ -47,12 +48,12 @@ func RenameNode(reg *mount.Registry, id, absPath, newName string, uid, gid int,
 	// localizar entrada actual
 	childIno := lookupInDir(mp, sb, parentIno, oldName)
 	if childIno < 0 {
-		return fmt.Errorf("rename: '%s' no existe", absPath)
+		return fmt.Errorf("rename: '%s' %w", absPath, ErrNotExist)
 	}
 
 	// verificar no colisión con el nuevo nombre en el mismo directorio
 	if exists := lookupInDir(mp, sb, parentIno, newName); exists >= 0 {
-		return fmt.Errorf("rename: ya existe '%s' en el mismo directorio", newName)
+		return fmt.Errorf("rename: %w '%s' en el mismo directorio", ErrExist, newName)
 	}
 
 	// permisos: escritura sobre el propio nodo (o root)
@@ -61,7 +62,7 @@ func RenameNode(reg *mount.Registry, id, absPath, newName string, uid, gid int,
 		return err
 	}
 	if !CanWrite(ino, uid, gid, isRoot) {
-		return fmt.Errorf("rename: permisos insuficientes sobre '%s'", absPath)
+		return fmt.Errorf("rename: %w sobre '%s'", ErrPermission, absPath)
 	}
 
 	// reemplazar el nombre en el directorio padre
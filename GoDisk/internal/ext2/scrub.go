@@ -0,0 +1,145 @@
+package ext2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// ScrubIssue es un inodo o bloque cuyo contenido no coincide con su
+// checksum. Path es el archivo al que pertenece ("" si ninguno lo apunta).
+type ScrubIssue struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Index    int32  `json:"index"`
+	Stored   uint32 `json:"stored"`
+	Computed uint32 `json:"computed"`
+}
+
+type ScrubReport struct {
+	Inodes int          `json:"inodes"`
+	Blocks int          `json:"blocks"`
+	Issues []ScrubIssue `json:"issues"`
+}
+
+// Scrub verifica los checksums de todo lo alcanzable desde la raíz y luego
+// de lo que los bitmaps marcan en uso sin que nadie lo apunte. No se detiene
+// en la primera falla: las estructuras corruptas se decodifican igual para
+// seguir recorriendo.
+func Scrub(reg *mount.Registry, id string) (ScrubReport, error) {
+	var rep ScrubReport
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return rep, fmt.Errorf("scrub: id %s no está montado", id)
+	}
+	defer lockR(&mp)()
+
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
+		return rep, fmt.Errorf("scrub: leyendo SB: %w", err)
+	}
+	if err := requireSupportedFS(sb, "scrub"); err != nil {
+		return rep, err
+	}
+	if !sb.HasFeature(FeatureChecksums) {
+		return rep, errors.New("scrub: la partición no se formateó con -checksum")
+	}
+	bmIn, bmBl, err := loadBitmaps(mp, sb)
+	if err != nil {
+		return rep, err
+	}
+
+	seenIn := make([]bool, sb.SInodesCount)
+	seenBl := make([]bool, sb.SBlocksCount)
+
+	// check lee la estructura cruda, la verifica y la decodifica en data.
+	check := func(kind string, idx int32, owner string, data any) error {
+		base, n := sb.SInodeStart+int64(idx)*int64(sb.SInodeS), InodeSize(sb)
+		if kind == "bloque" {
+			base, n = sb.SBlockStart+int64(idx)*int64(BlockSize), int64(binary.Size(data))
+			rep.Blocks++
+		} else {
+			rep.Inodes++
+		}
+		raw, err := readBytes(mp.DiskPath, mp.Start+base, int(n))
+		if err != nil {
+			return err
+		}
+		var ce *ChecksumError
+		if err := verifyRaw(mp, sb, kind, idx, raw); errors.As(err, &ce) {
+			rep.Issues = append(rep.Issues, ScrubIssue{owner, kind, idx, ce.Stored, ce.Computed})
+		} else if err != nil {
+			return err
+		}
+		if ino, ok := data.(*Inodo); ok {
+			*ino, err = decodeInode(raw)
+			return err
+		}
+		return binary.Read(bytes.NewReader(raw), binary.LittleEndian, data)
+	}
+
+	var walk func(idx int32, abs string) error
+	walk = func(idx int32, abs string) error {
+		if idx < 0 || idx >= sb.SInodesCount || seenIn[idx] {
+			return nil
+		}
+		seenIn[idx] = true
+		var ino Inodo
+		if err := check("inodo", idx, abs, &ino); err != nil {
+			return err
+		}
+		for _, ptr := range ino.IBlock {
+			if ptr < 0 || ptr >= sb.SBlocksCount || seenBl[ptr] {
+				continue
+			}
+			seenBl[ptr] = true
+			if ino.IType != 0 {
+				var bf BlockFile
+				if err := check("bloque", ptr, abs, &bf); err != nil {
+					return err
+				}
+				continue
+			}
+			var bf BlockFolder
+			if err := check("bloque", ptr, abs, &bf); err != nil {
+				return err
+			}
+			for _, e := range bf.BContent {
+				nm := trimNull(e.BName[:])
+				if e.BInodo < 0 || nm == "" || nm == "." || nm == ".." {
+					continue
+				}
+				if err := walk(e.BInodo, path.Join(abs, nm)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(0, "/"); err != nil {
+		return rep, fmt.Errorf("scrub: %w", err)
+	}
+
+	// Lo marcado en uso que no se alcanzó desde la raíz.
+	for i := int32(0); i < sb.SInodesCount; i++ {
+		if bmIn[i] != 0 && !seenIn[i] {
+			var ino Inodo
+			if err := check("inodo", i, "", &ino); err != nil {
+				return rep, fmt.Errorf("scrub: %w", err)
+			}
+		}
+	}
+	for i := int32(0); i < sb.SBlocksCount; i++ {
+		if bmBl[i] != 0 && !seenBl[i] {
+			var bf BlockFile
+			if err := check("bloque", i, "", &bf); err != nil {
+				return rep, fmt.Errorf("scrub: %w", err)
+			}
+		}
+	}
+	return rep, nil
+}
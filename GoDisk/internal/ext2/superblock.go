@@ -0,0 +1,218 @@
+package ext2
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// Con FeatureBackupSB, mkfs deja SuperBackups copias del superbloque al
+// final del FS (después de los bloques y de las tablas de checksums y
+// referencias). Toda escritura del superbloque pasa por WriteSuperBlock, que actualiza primario y copias; los lectores usan
+// LoadSuperBlock, que cae a una copia si el primario no pasa la validación.
+
+const SuperBackups = 2
+
+// superMagicOff es el offset de SMagic dentro del superbloque.
+const superMagicOff = 40
+
+// Las copias sólo existen con FeatureBackupSB, que requiere MagicEXT2v2.
+var superMagicLE = []byte{0x54, 0xEF, 0x00, 0x00}
+
+// SuperBlockSize devuelve lo que ocupa sb en disco: el formato original
+// termina antes de SFeatures.
+func SuperBlockSize(sb SuperBloque) int64 {
+	if sb.Legacy() {
+		return sizeof(SuperBloque{}) - sizeof(sb.SFeatures)
+	}
+	return sizeof(SuperBloque{})
+}
+
+// DecodeSuperBlock decodifica un superbloque de cualquiera de los dos
+// formatos. En el original los bytes de SFeatures son ya el bitmap de
+// inodos, así que se descartan (sin opciones).
+func DecodeSuperBlock(raw []byte) (SuperBloque, error) {
+	var sb SuperBloque
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &sb); err != nil {
+		return sb, err
+	}
+	if sb.Legacy() {
+		sb.SFeatures = 0
+	}
+	return sb, nil
+}
+
+func readSuperBlockAt(path string, off int64) (SuperBloque, error) {
+	raw, err := readBytes(path, off, int(sizeof(SuperBloque{})))
+	if err != nil {
+		return SuperBloque{}, err
+	}
+	return DecodeSuperBlock(raw)
+}
+
+func writeSuperBlockAt(path string, off int64, sb SuperBloque) error {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, sb); err != nil {
+		return err
+	}
+	return writeBytes(path, off, buf.Bytes()[:SuperBlockSize(sb)])
+}
+
+// SuperBackupsLen devuelve los bytes que reservan las copias.
+func SuperBackupsLen(opts MkfsOptions) int64 {
+	if !opts.BackupSuperblocks {
+		return 0
+	}
+	return SuperBackups * sizeof(SuperBloque{})
+}
+
+// SuperBackupOffsets devuelve dónde van las copias, relativo al inicio de
+// la partición (vacío si el FS no tiene respaldos).
+func SuperBackupOffsets(sb SuperBloque) []int64 {
+	if !sb.HasFeature(FeatureBackupSB) {
+		return nil
+	}
+	end := RefcountTableStart(sb) + RefcountTableLen(sb)
+	offs := make([]int64, SuperBackups)
+	for i := range offs {
+		offs[i] = end + int64(i)*sizeof(SuperBloque{})
+	}
+	return offs
+}
+
+// SuperBlockOK valida magic, tipo y que el layout sea coherente consigo
+// mismo (bitmaps, tabla de inodos y bloques uno tras otro).
+func SuperBlockOK(sb SuperBloque) bool {
+	if !ValidMagic(sb.SMagic) || sb.SInodesCount < 2 || sb.SBlocksCount != 3*sb.SInodesCount {
+		return false
+	}
+	if sb.SBlockS != BlockSize || int64(sb.SInodeS) != InodeSize(sb) {
+		return false
+	}
+	szSB := SuperBlockSize(sb)
+	switch sb.SFilesystemType {
+	case FileSystemType:
+		if sb.SBmInodeStart != szSB {
+			return false
+		}
+	case FileSystemTypeEXT3:
+		// El journal va entre el superbloque y bm_inode.
+		if sb.SBmInodeStart <= szSB || (sb.SBmInodeStart-szSB)%int64(sb.SInodesCount) != 0 {
+			return false
+		}
+	default:
+		return false
+	}
+	return sb.SBmBlockStart == sb.SBmInodeStart+BitmapDiskLen(sb, sb.SInodesCount) &&
+		sb.SInodeStart == sb.SBmBlockStart+BitmapDiskLen(sb, sb.SBlocksCount) &&
+		sb.SBlockStart == sb.SInodeStart+int64(sb.SInodesCount)*int64(sb.SInodeS)
+}
+
+// ReadSuperBlock es LoadSuperBlock sin indicar la copia usada.
+func ReadSuperBlock(mp *mount.MountedPartition) (SuperBloque, error) {
+	sb, _, err := LoadSuperBlock(mp)
+	return sb, err
+}
+
+// LoadSuperBlock lee el superbloque de la partición. Si el primario no es
+// válido busca la copia de respaldo más reciente; copy es 0 para el
+// primario e i para el respaldo i. Sin respaldos válidos devuelve el
+// primario tal cual para que cada comando reporte su error.
+func LoadSuperBlock(mp *mount.MountedPartition) (SuperBloque, int, error) {
+	sb, err := readSuperBlockAt(mp.DiskPath, mp.Start)
+	if err != nil {
+		return sb, 0, err
+	}
+	if SuperBlockOK(sb) {
+		return sb, 0, nil
+	}
+	if b, i, ok := findBackupSuperBlock(mp); ok {
+		return b, i, nil
+	}
+	return sb, 0, nil
+}
+
+// WriteSuperBlock escribe el primario y, si el FS las tiene, las copias.
+func WriteSuperBlock(mp *mount.MountedPartition, sb SuperBloque) error {
+	if err := writeSuperBlockAt(mp.DiskPath, mp.Start, sb); err != nil {
+		return err
+	}
+	for _, off := range SuperBackupOffsets(sb) {
+		if off+sizeof(sb) > mp.Size {
+			break
+		}
+		if err := writeSuperBlockAt(mp.DiskPath, mp.Start+off, sb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SuperCopy describe una copia del superbloque para los reportes.
+type SuperCopy struct {
+	Index  int   `json:"index"` // 0 = primario
+	Offset int64 `json:"offset"`
+	OK     bool  `json:"ok"`
+}
+
+// SuperBlockCopies revisa el primario y las copias que indica sb.
+func SuperBlockCopies(mp *mount.MountedPartition, sb SuperBloque) []SuperCopy {
+	offs := append([]int64{0}, SuperBackupOffsets(sb)...)
+	out := make([]SuperCopy, 0, len(offs))
+	for i, off := range offs {
+		ok := false
+		if off+sizeof(SuperBloque{}) <= mp.Size {
+			c, err := readSuperBlockAt(mp.DiskPath, mp.Start+off)
+			ok = err == nil && SuperBlockOK(c)
+			if ok && i > 0 {
+				ok = SuperBackupOffsets(c)[i-1] == off
+			}
+		}
+		out = append(out, SuperCopy{Index: i, Offset: off, OK: ok})
+	}
+	return out
+}
+
+// findBackupSuperBlock recorre la partición desde el final (donde suelen
+// quedar las copias) buscando superbloques cuya propia posición coincida
+// con la de un respaldo. Si hay copias de formateos anteriores gana la de
+// SMtime más reciente.
+func findBackupSuperBlock(mp *mount.MountedPartition) (SuperBloque, int, bool) {
+	const chunk = 64 << 10
+	szSB := sizeof(SuperBloque{})
+
+	var best SuperBloque
+	bestIdx := 0
+	for hi := mp.Size; hi > szSB; hi -= chunk {
+		lo := max(hi-chunk-szSB, 0)
+		buf, err := readBytes(mp.DiskPath, mp.Start+lo, int(min(hi+szSB, mp.Size)-lo))
+		if err != nil {
+			return best, 0, false
+		}
+		for end := len(buf); ; {
+			k := bytes.LastIndex(buf[:end], superMagicLE)
+			if k < 0 {
+				break
+			}
+			end = k + len(superMagicLE) - 1
+			at := lo + int64(k) - superMagicOff
+			if k < superMagicOff || at <= 0 || at >= hi || int64(k-superMagicOff)+szSB > int64(len(buf)) {
+				continue
+			}
+			c, err := DecodeSuperBlock(buf[k-superMagicOff:])
+			if err != nil || !SuperBlockOK(c) {
+				continue
+			}
+			for i, off := range SuperBackupOffsets(c) {
+				if off != at {
+					continue
+				}
+				if bestIdx == 0 || c.SMtime > best.SMtime || (c.SMtime == best.SMtime && i+1 < bestIdx) {
+					best, bestIdx = c, i+1
+				}
+			}
+		}
+	}
+	return best, bestIdx, bestIdx > 0
+}
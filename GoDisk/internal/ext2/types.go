@@ -5,10 +5,26 @@ const (
 	BlockSize          = 64
 	FileSystemType     = 2
 	FileSystemTypeEXT3 = 3
-	MagicEXT2          = 0xEF53
+	MagicEXT2          = 0xEF53 // formato original: superbloque sin SFeatures
+	MagicEXT2v2        = 0xEF54 // superbloque con SFeatures (mkfs actual)
 	InodeDirectCount   = 15
 )
 
+// Banderas de SFeatures (opciones elegidas en mkfs)
+const (
+	FeaturePackedBitmaps int32 = 1 << 0 // bitmaps de 1 bit por entrada
+	FeatureBackupSB      int32 = 1 << 1 // copias del superbloque tras el área de bloques
+	FeatureChecksums     int32 = 1 << 2 // CRC32C por inodo y bloque
+	FeatureRefcounts     int32 = 1 << 3 // referencias por bloque (copy comparte bloques)
+	FeatureJournalOwner  int32 = 1 << 4 // el journal EXT3 guarda uid, gid y resultado
+	FeatureInodeFlags    int32 = 1 << 5 // inodos con IFlags e IStored (sin ella: 100 bytes)
+)
+
+// Banderas de IFlags (por archivo)
+const (
+	InodeFlagCompressed byte = 1 << 0 // contenido guardado con DEFLATE
+)
+
 // SuperBloque
 type SuperBloque struct {
 	SFilesystemType  int32
@@ -28,8 +44,18 @@ type SuperBloque struct {
 	SBmBlockStart    int64
 	SInodeStart      int64
 	SBlockStart      int64
+	SFeatures        int32 // sólo con MagicEXT2v2
 }
 
+func (sb SuperBloque) HasFeature(f int32) bool { return sb.SFeatures&f != 0 }
+
+// Legacy indica un superbloque del formato original (MagicEXT2): no tiene
+// SFeatures y el bitmap de inodos empieza donde éste iría.
+func (sb SuperBloque) Legacy() bool { return sb.SMagic == MagicEXT2 }
+
+// ValidMagic acepta los dos formatos de superbloque.
+func ValidMagic(m int32) bool { return m == MagicEXT2 || m == MagicEXT2v2 }
+
 type Inodo struct {
 	IUid   int32
 	IGid   int32
@@ -40,6 +66,9 @@ type Inodo struct {
 	IBlock [InodeDirectCount]int32
 	IType  byte
 	IPerm  [3]byte
+	// Sólo en disco con FeatureInodeFlags; el formato original termina en IPerm.
+	IFlags  byte  // InodeFlag*
+	IStored int32 // bytes ocupados en bloques si el contenido va comprimido
 }
 
 type DirEntry struct {
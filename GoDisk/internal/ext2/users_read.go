@@ -12,13 +12,17 @@ func ReadUsersText(reg *mount.Registry, id string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf("users: id %s no está montado", id)
 	}
+	defer lockR(&mp)()
+	return readUsersText(mp)
+}
 
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+func readUsersText(mp *mount.MountedPartition) (string, error) {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return "", fmt.Errorf("users: leyendo SB: %w", err)
 	}
 
-	if sb.SMagic != MagicEXT2 {
+	if !ValidMagic(sb.SMagic) {
 		return "", errors.New("users: la partición no parece EXT2/EXT3 válida (magic)")
 	}
 	if sb.SFilesystemType != FileSystemType && sb.SFilesystemType != 3 {
@@ -9,8 +9,13 @@ import (
 )
 
 func AppendUsersLine(reg *mount.Registry, id, line string) error {
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return fmt.Errorf("users: id %s no está montado", id)
+	}
+	defer lockW(&mp)()
 
-	cur, err := ReadUsersText(reg, id)
+	cur, err := readUsersText(mp)
 	if err != nil {
 		return err
 	}
@@ -24,7 +29,7 @@ func AppendUsersLine(reg *mount.Registry, id, line string) error {
 	}
 	newContent := cur + line + "\n"
 
-	return RewriteUsers(reg, id, newContent)
+	return rewriteUsers(mp, newContent)
 }
 
 func RewriteUsers(reg *mount.Registry, id string, content string) error {
@@ -32,12 +37,16 @@ func RewriteUsers(reg *mount.Registry, id string, content string) error {
 	if !ok {
 		return fmt.Errorf("users: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
+	return rewriteUsers(mp, content)
+}
 
-	var sb SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+func rewriteUsers(mp *mount.MountedPartition, content string) error {
+	sb, err := ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("users: leyendo SB: %w", err)
 	}
-	if sb.SMagic != MagicEXT2 {
+	if !ValidMagic(sb.SMagic) {
 		return errors.New("users: la partición no es EXT2 válida")
 	}
 
@@ -68,6 +77,9 @@ func RewriteUsers(reg *mount.Registry, id string, content string) error {
 	if err != nil {
 		return err
 	}
+	if err := unshareBlocks(mp, sb, &uino); err != nil {
+		return err
+	}
 
 	var curBlocks []int32
 	for _, ptr := range uino.IBlock {
@@ -80,12 +92,10 @@ func RewriteUsers(reg *mount.Registry, id string, content string) error {
 	if needBlocks > curCount {
 		add := needBlocks - curCount
 		for i := 0; i < add; i++ {
-			blkIdx := FirstFree(bmBl)
+			blkIdx := allocBlock(&sb, bmBl)
 			if blkIdx < 0 {
 				return errors.New("users: no hay bloques libres")
 			}
-			MarkBlock(bmBl, blkIdx, true)
-			sb.SFreeBlocksCount--
 			if uino.IBlock[curCount+i] != -1 && uino.IBlock[curCount+i] != 0 {
 
 			}
@@ -98,8 +108,7 @@ func RewriteUsers(reg *mount.Registry, id string, content string) error {
 		for i := needBlocks; i < curCount; i++ {
 			blk := uino.IBlock[i]
 			if blk >= 0 {
-				MarkBlock(bmBl, blk, false)
-				sb.SFreeBlocksCount++
+				freeBlock(&sb, bmBl, blk)
 			}
 			uino.IBlock[i] = -1
 		}
@@ -126,12 +135,10 @@ func RewriteUsers(reg *mount.Registry, id string, content string) error {
 		return err
 	}
 
-	sb.SFirstBlo = FirstFree(bmBl)
-
 	if err := saveBitmaps(mp, sb, bmIn, bmBl); err != nil {
 		return err
 	}
-	if err := writeAt(mp.DiskPath, mp.Start, sb); err != nil {
+	if err := WriteSuperBlock(mp, sb); err != nil {
 		return err
 	}
 	return nil
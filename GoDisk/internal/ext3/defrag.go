@@ -0,0 +1,158 @@
+package ext3
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+// En EXT3 cada corrida de defrag deja UNA entrada DEFRAG en el journal que
+// se reescribe en su lugar tras cada nodo ("estado=run ino=N", N = último
+// inodo terminado). Si el proceso se corta, el siguiente defrag la encuentra
+// en estado run y continúa desde N+1; al terminar queda "estado=fin".
+
+type DefragReport struct {
+	Root    string   `json:"root"`
+	Nodes   int      `json:"nodes"`
+	Moved   int      `json:"moved"`
+	Freed   int      `json:"freed"`
+	NoSpace []string `json:"noSpace"`
+	Resumed bool     `json:"resumed"`
+	FromIno int32    `json:"fromIno"`
+}
+
+type pendingDefrag struct {
+	count   int32
+	root    string
+	lastIno int32
+}
+
+func Defrag(reg *mount.Registry, id, absPath string) (DefragReport, error) {
+	rep := DefragReport{FromIno: -1}
+
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return rep, fmt.Errorf("defrag: id %s no está montado", id)
+	}
+	isExt3, pend, err := loadPendingDefrag(mp)
+	if err != nil {
+		return rep, err
+	}
+
+	absPath = strings.TrimSpace(absPath)
+	var count int32
+	if pend.count > 0 && (absPath == "" || absPath == pend.root) {
+		absPath = pend.root
+		count = pend.count
+		rep.Resumed = true
+		rep.FromIno = pend.lastIno
+	}
+	if absPath == "" {
+		absPath = "/"
+	}
+	rep.Root = absPath
+
+	targets, err := ext2.DefragTargets(reg, id, absPath)
+	if err != nil {
+		return rep, err
+	}
+
+	if isExt3 && count == 0 {
+		if count, err = journalDefrag(mp, 0, absPath, "estado=run ino=-1"); err != nil {
+			return rep, err
+		}
+	}
+
+	for _, t := range targets {
+		if t.Ino <= rep.FromIno {
+			continue
+		}
+		r, err := ext2.DefragInode(reg, id, t.Ino)
+		if err != nil {
+			return rep, fmt.Errorf("defrag: %s: %w", t.Path, err)
+		}
+		rep.Nodes++
+		rep.Moved += r.Moved
+		rep.Freed += r.Freed
+		if r.NoSpace {
+			rep.NoSpace = append(rep.NoSpace, t.Path)
+		}
+
+		if count > 0 {
+			if count, err = journalDefrag(mp, count, absPath, fmt.Sprintf("estado=run ino=%d", t.Ino)); err != nil {
+				return rep, err
+			}
+			// El progreso sólo sirve para reanudar si ya llegó al archivo.
+			if err := diskio.Flush(mp.DiskPath); err != nil {
+				return rep, fmt.Errorf("defrag: %w", err)
+			}
+		}
+	}
+
+	if count > 0 {
+		done := fmt.Sprintf("estado=fin nodos=%d movidos=%d", rep.Nodes, rep.Moved)
+		if _, err := journalDefrag(mp, count, absPath, done); err != nil {
+			return rep, err
+		}
+	}
+	return rep, nil
+}
+
+// loadPendingDefrag indica si la partición es EXT3 y, en ese caso, si la
+// última entrada DEFRAG del journal quedó sin terminar.
+func loadPendingDefrag(mp *mount.MountedPartition) (bool, pendingDefrag, error) {
+	defer lockR(&mp)()
+
+	var p pendingDefrag
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
+		return false, p, fmt.Errorf("defrag: leyendo SB: %w", err)
+	}
+	if sb.SFilesystemType != FileSystemTypeExt3 {
+		return false, p, nil
+	}
+	entries, err := readAllJournalEntries(mp, sb)
+	if err != nil {
+		return true, p, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if strings.ToUpper(trimNull(e.JContent.I_operation[:])) != "DEFRAG" {
+			continue
+		}
+		kv := parseKV(trimNull(e.JContent.I_content[:]))
+		if kv["estado"] == "run" {
+			p = pendingDefrag{
+				count:   e.JCount,
+				root:    trimNull(e.JContent.I_path[:]),
+				lastIno: int32(pint(kv, "ino", -1)),
+			}
+		}
+		break
+	}
+	return true, p, nil
+}
+
+// journalDefrag crea (count == 0) o actualiza la entrada de progreso y
+// devuelve su JCount. Si la entrada ya fue pisada por el anillo del journal
+// se agrega una nueva.
+func journalDefrag(mp *mount.MountedPartition, count int32, root, content string) (int32, error) {
+	defer lockW(&mp)()
+
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
+		return count, fmt.Errorf("defrag: leyendo SB: %w", err)
+	}
+	info := structs.NewInformation("DEFRAG", root, content, time.Now())
+	if count > 0 {
+		if err := rewriteJournalEntry(mp, sb, count, info); err == nil {
+			return count, nil
+		}
+	}
+	return appendJournalEntry(mp, sb, structs.Journal{JContent: info})
+}
@@ -1,32 +1,26 @@
 package ext3
 
 import (
-	"encoding/binary"
-	"io"
-	"os"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
+func readAt(path string, off int64, data any) error {
+	return diskio.ReadAt(path, off, data)
+}
+
 func writeAt(path string, off int64, data any) error {
-	f, err := os.OpenFile(path, os.O_RDWR, 0o666)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return err
-	}
-	return binary.Write(f, binary.LittleEndian, data)
+	return diskio.WriteAt(path, off, data)
 }
 
 func writeBytes(path string, off int64, buf []byte) error {
-	f, err := os.OpenFile(path, os.O_RDWR, 0o666)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return err
-	}
-	_, err = f.Write(buf)
-	return err
+	return diskio.WriteBytes(path, off, buf)
+}
+
+func lockW(mp **mount.MountedPartition) func() {
+	return mount.Lock(mp, true)
+}
+
+func lockR(mp **mount.MountedPartition) func() {
+	return mount.Lock(mp, false)
 }
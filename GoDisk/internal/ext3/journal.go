@@ -1,10 +1,7 @@
 package ext3
 
 import (
-	"encoding/binary"
 	"fmt"
-	"io"
-	"os"
 	"time"
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
@@ -13,88 +10,124 @@ import (
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/xbin"
 )
 
-// Si ya tienes readAt en otro archivo del paquete ext3 y quieres evitar duplicados,
-// puedes remover esta función y reutilizar la existente.
-func readAt(path string, off int64, data any) error {
-	f, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return err
-	}
-	return binary.Read(f, binary.LittleEndian, data)
-}
-
 func journalRegion(sb ext2.SuperBloque) (off, entries int64) {
-	sbSize := xbin.SizeOf[ext2.SuperBloque]()
-	jStart := sbSize
+	jStart := ext2.SuperBlockSize(sb)
 	jBytes := sb.SBmInodeStart - jStart
-	entrySz := xbin.SizeOf[structs.Journal]()
+	entrySz := entrySize(sb)
 	if entrySz <= 0 || jBytes <= 0 {
 		return 0, 0
 	}
 	return jStart, jBytes / entrySz
 }
 
-func appendJournalEntry(mp *mount.MountedPartition, sb ext2.SuperBloque, entry structs.Journal) error {
+// entrySize es el tamaño de una entrada: sin FeatureJournalOwner el
+// journal usa el formato anterior (sin uid, gid ni resultado).
+func entrySize(sb ext2.SuperBloque) int64 {
+	if sb.HasFeature(ext2.FeatureJournalOwner) {
+		return int64(xbin.SizeOf[structs.Journal]())
+	}
+	return int64(xbin.SizeOf[structs.JournalV1]())
+}
+
+func readEntry(mp *mount.MountedPartition, sb ext2.SuperBloque, off int64) (structs.Journal, error) {
+	var e structs.Journal
+	if sb.HasFeature(ext2.FeatureJournalOwner) {
+		err := readAt(mp.DiskPath, off, &e)
+		return e, err
+	}
+	var old structs.JournalV1
+	if err := readAt(mp.DiskPath, off, &old); err != nil {
+		return e, err
+	}
+	return old.Upgrade(), nil
+}
+
+func writeEntry(mp *mount.MountedPartition, sb ext2.SuperBloque, off int64, e structs.Journal) error {
+	if sb.HasFeature(ext2.FeatureJournalOwner) {
+		return writeAt(mp.DiskPath, off, e)
+	}
+	return writeAt(mp.DiskPath, off, e.Downgrade())
+}
+
+// appendJournalEntry agrega la entrada y devuelve el JCount asignado (0 si
+// la partición no tiene región de journal).
+func appendJournalEntry(mp *mount.MountedPartition, sb ext2.SuperBloque, entry structs.Journal) (int32, error) {
 	jOff, cap := journalRegion(sb)
 	if cap <= 0 {
 
-		return nil
+		return 0, nil
 	}
 
-	entrySize := int64(xbin.SizeOf[structs.Journal]())
-
-	var (
-		nextIdx   int64 = -1
-		lastCount int32 = 0
-		cur       structs.Journal
-	)
+	entrySize := entrySize(sb)
 
+	// La entrada con JCount c vive en el slot (c-1)%cap (restoreJournal
+	// respeta lo mismo), así que la siguiente va en lastCount%cap: un slot
+	// libre o, con el journal lleno, el de la más antigua.
+	var lastCount int32
 	for i := int64(0); i < cap; i++ {
 		off := mp.Start + jOff + i*entrySize
-		if err := readAt(mp.DiskPath, off, &cur); err != nil {
-			return fmt.Errorf("journal: leyendo entrada %d: %w", i, err)
-		}
-		if cur.JCount == 0 {
-			nextIdx = i // slot libre
-			break
+		cur, err := readEntry(mp, sb, off)
+		if err != nil {
+			return 0, fmt.Errorf("journal: leyendo entrada %d: %w", i, err)
 		}
 		if cur.JCount > lastCount {
 			lastCount = cur.JCount
 		}
 	}
-	if nextIdx == -1 {
-		// Journal lleno
-		nextIdx = int64(lastCount % int32(cap))
-	}
+	nextIdx := int64(lastCount) % cap
 
 	entry.JCount = lastCount + 1
 
 	wOff := mp.Start + jOff + nextIdx*entrySize
-	if err := writeAt(mp.DiskPath, wOff, entry); err != nil {
-		return fmt.Errorf("journal: escribiendo entrada idx=%d: %w", nextIdx, err)
+	if err := writeEntry(mp, sb, wOff, entry); err != nil {
+		return 0, fmt.Errorf("journal: escribiendo entrada idx=%d: %w", nextIdx, err)
+	}
+	return entry.JCount, nil
+}
+
+// rewriteJournalEntry reemplaza el contenido de la entrada con JCount count
+// (se usa para entradas de progreso que se actualizan en su lugar).
+func rewriteJournalEntry(mp *mount.MountedPartition, sb ext2.SuperBloque, count int32, info structs.Information) error {
+	jOff, cap := journalRegion(sb)
+	entrySize := entrySize(sb)
+
+	for i := int64(0); i < cap; i++ {
+		off := mp.Start + jOff + i*entrySize
+		cur, err := readEntry(mp, sb, off)
+		if err != nil {
+			return fmt.Errorf("journal: leyendo entrada %d: %w", i, err)
+		}
+		if cur.JCount == count {
+			return writeEntry(mp, sb, off, structs.Journal{JCount: count, JContent: info})
+		}
 	}
-	return nil
+	return fmt.Errorf("journal: entrada %d no encontrada", count)
 }
 
 func AppendJournalIfExt3(reg *mount.Registry, id, op, pth, content string) error {
+	_, err := AppendJournalEntry(reg, id, op, pth, content, 0, 0, true)
+	return err
+}
+
+// AppendJournalEntry es AppendJournalIfExt3 con el usuario que hizo la
+// operación y su resultado; devuelve el JCount de la entrada escrita (0 si
+// la partición no es EXT3 o no tiene journal).
+func AppendJournalEntry(reg *mount.Registry, id, op, pth, content string, uid, gid int32, succeeded bool) (int32, error) {
 	mp, ok := reg.GetByID(id)
 	if !ok {
-		return nil
+		return 0, nil
 	}
+	defer lockW(&mp)()
 
-	var sb ext2.SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
-		return fmt.Errorf("journal: leyendo SB: %w", err)
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
+		return 0, fmt.Errorf("journal: leyendo SB: %w", err)
 	}
 	if sb.SFilesystemType != FileSystemTypeExt3 {
-		return nil // sólo aplica en EXT3
+		return 0, nil // sólo aplica en EXT3
 	}
 
-	info := structs.NewInformation(op, pth, content, time.Now())
+	info := structs.NewInformation(op, pth, content, time.Now()).WithActor(uid, gid, succeeded)
 	entry := structs.Journal{JContent: info}
 
 	return appendJournalEntry(mp, sb, entry)
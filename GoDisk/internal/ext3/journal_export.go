@@ -0,0 +1,127 @@
+package ext3
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportScript convierte las entradas del journal en líneas de comando de
+// GoDisk (un .smia) para re-aplicarlas sobre otra partición con una sesión
+// activa. Las que no se pueden expresar como comando (fallidas, DEFRAG,
+// contenido multilínea) quedan como comentario para que el script se pueda
+// revisar a mano.
+func ExportScript(id string, rows []JournalRow) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# journaling de %s exportado el %s (%d entradas)\n", id, time.Now().Format(time.RFC3339), len(rows))
+	b.WriteString("# Ejecutar con una sesión activa en la partición destino; las operaciones\n")
+	b.WriteString("# quedan a nombre del usuario de esa sesión.\n")
+
+	for _, r := range rows {
+		if r.Status == "error" {
+			fmt.Fprintf(&b, "# [%d] %s %s: falló originalmente\n", r.Count, r.Operation, r.Path)
+			continue
+		}
+		lines, err := commandLines(r)
+		if err != nil {
+			fmt.Fprintf(&b, "# [%d] %s %s: %v\n", r.Count, r.Operation, r.Path, err)
+			continue
+		}
+		for _, l := range lines {
+			b.WriteString(l)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func commandLines(r JournalRow) ([]string, error) {
+	kv := parseKV(r.Content)
+	path := "-path=" + quoteArg(r.Path)
+
+	switch r.Operation {
+	case "MKDIR":
+		return []string{"mkdir -p " + path}, nil
+
+	case "MKFILE":
+		mk := "mkfile -r -force " + path
+		switch {
+		case r.Content == "":
+			return []string{mk}, nil
+		case string(genData(len(r.Content))) == r.Content:
+			return []string{mk + " -size=" + strconv.Itoa(len(r.Content))}, nil
+		}
+		// mkfile sólo lee -cont de un archivo del SO: el texto va con edit.
+		if strings.Contains(r.Content, "\n") {
+			return nil, fmt.Errorf("contenido multilínea, no se puede expresar en una línea")
+		}
+		return []string{mk, "edit " + path + " -text=" + quoteArg(r.Content)}, nil
+
+	case "EDIT", "APPEND":
+		if strings.Contains(r.Content, "\n") {
+			return nil, fmt.Errorf("contenido multilínea, no se puede expresar en una línea")
+		}
+		// -text y no -cont: un contenido que coincida con una ruta del SO no
+		// debe leerse de ese archivo al re-aplicar el script.
+		return []string{strings.ToLower(r.Operation) + " " + path + " -text=" + quoteArg(r.Content)}, nil
+
+	case "TRUNCATE":
+		return []string{"truncate " + path + " -size=" + strconv.Itoa(pint(kv, "size", 0))}, nil
+
+	case "COPY", "MOVE":
+		if kv["dest"] == "" {
+			return nil, fmt.Errorf("falta dest=")
+		}
+		return []string{strings.ToLower(r.Operation) + " " + path + " -destino=" + quoteArg(kv["dest"])}, nil
+
+	case "REMOVE":
+		return []string{"remove " + path}, nil
+
+	case "RENAME":
+		if kv["name"] == "" {
+			return nil, fmt.Errorf("falta name=")
+		}
+		return []string{"rename " + path + " -name=" + quoteArg(kv["name"])}, nil
+
+	case "CHMOD":
+		if kv["ugo"] == "" {
+			return nil, fmt.Errorf("falta ugo=")
+		}
+		return []string{"chmod " + path + " -ugo=" + kv["ugo"] + recursiveFlag(kv)}, nil
+
+	case "CHATTR":
+		return []string{"chattr " + path + " -compress=" + strconv.FormatBool(pbool(kv, "compress", false))}, nil
+
+	case "CHOWN":
+		if kv["usuario"] == "" {
+			return nil, fmt.Errorf("falta usuario=")
+		}
+		return []string{"chown " + path + " -usuario=" + quoteArg(kv["usuario"]) + recursiveFlag(kv)}, nil
+
+	case "DEFRAG":
+		return nil, fmt.Errorf("no se exporta (sólo reubica bloques)")
+	}
+	return nil, fmt.Errorf("op desconocida")
+}
+
+// recursive lee la marca de recursión de CHMOD/CHOWN, que usersvc guarda
+// como recursive= (r= en entradas más antiguas).
+func recursive(kv map[string]string) bool {
+	return pbool(kv, "recursive", pbool(kv, "r", false))
+}
+
+func recursiveFlag(kv map[string]string) string {
+	if recursive(kv) {
+		return " -r"
+	}
+	return ""
+}
+
+// quoteArg entrecomilla un valor como lo entiende el tokenizador de
+// comandos (comillas dobles, \ escapa el siguiente carácter).
+func quoteArg(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
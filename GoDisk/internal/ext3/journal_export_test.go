@@ -0,0 +1,41 @@
+package ext3
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Un contenido que coincide con un archivo del SO se exporta como texto
+// literal: con -cont el script leería ese archivo al re-aplicarse.
+func TestExportContentIsLiteral(t *testing.T) {
+	host := filepath.Join(t.TempDir(), "secreto.txt")
+	if err := os.WriteFile(host, []byte("no debe leerse"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		op   string
+		want string
+	}{
+		{"MKFILE", `edit -path="/a.txt" -text="` + host + `"`},
+		{"EDIT", `edit -path="/a.txt" -text="` + host + `"`},
+		{"APPEND", `append -path="/a.txt" -text="` + host + `"`},
+	}
+	for _, c := range cases {
+		t.Run(c.op, func(t *testing.T) {
+			lines, err := commandLines(JournalRow{Count: 1, Operation: c.op, Path: "/a.txt", Content: host, Status: "ok"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lines[len(lines)-1]; got != c.want {
+				t.Fatalf("línea = %q, se esperaba %q", got, c.want)
+			}
+			for _, l := range lines {
+				if strings.Contains(l, "-cont") {
+					t.Fatalf("el contenido se exportó con -cont: %q", l)
+				}
+			}
+		})
+	}
+}
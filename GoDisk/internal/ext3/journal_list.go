@@ -3,11 +3,13 @@ package ext3
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
 )
 
 type JournalRow struct {
@@ -16,6 +18,11 @@ type JournalRow struct {
 	Path      string `json:"path"`
 	Content   string `json:"content"`
 	Date      string `json:"date"`
+	UID       int32  `json:"uid,omitempty"`
+	GID       int32  `json:"gid,omitempty"`
+	User      string `json:"user,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Status    string `json:"status"` // ok | error | "" (entrada sin resultado)
 }
 
 func ListJournal(reg *mount.Registry, id string) ([]JournalRow, error) {
@@ -23,9 +30,15 @@ func ListJournal(reg *mount.Registry, id string) ([]JournalRow, error) {
 	if !ok {
 		return nil, fmt.Errorf("journaling: id %s no está montado", id)
 	}
+	// Nombres para uid/gid; se leen antes de tomar el candado del journal.
+	users, groups := map[int32]string{}, map[int32]string{}
+	if txt, err := ext2.ReadUsersText(reg, id); err == nil {
+		users, groups = parseUsersNames(txt)
+	}
+	defer lockR(&mp)()
 
-	var sb ext2.SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
 		return nil, fmt.Errorf("journaling: leyendo SB: %w", err)
 	}
 	if sb.SFilesystemType != FileSystemTypeExt3 {
@@ -46,17 +59,55 @@ func ListJournal(reg *mount.Registry, id string) ([]JournalRow, error) {
 		if ts < 0 {
 			ts = 0
 		}
-		out = append(out, JournalRow{
+		row := JournalRow{
 			Count:     e.JCount,
 			Operation: op,
 			Path:      p,
 			Content:   c,
 			Date:      time.Unix(ts, 0).UTC().Format(time.RFC3339),
-		})
+			UID:       e.JContent.I_uid,
+			GID:       e.JContent.I_gid,
+			User:      users[e.JContent.I_uid],
+			Group:     groups[e.JContent.I_gid],
+		}
+		switch e.JContent.I_status {
+		case structs.JournalStatusOK:
+			row.Status = "ok"
+		case structs.JournalStatusFailed:
+			row.Status = "error"
+		}
+		out = append(out, row)
 	}
 	return out, nil
 }
 
+// parseUsersNames arma uid -> usuario y gid -> grupo a partir de users.txt
+// ("gid, G, grupo" y "uid, U, grupo, usuario, pass"; id 0 = eliminado).
+func parseUsersNames(txt string) (users, groups map[int32]string) {
+	users, groups = map[int32]string{}, map[int32]string{}
+	txt = strings.ReplaceAll(txt, "\r\n", "\n")
+	for _, line := range strings.Split(txt, "\n") {
+		parts := strings.Split(line, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		if len(parts) < 3 {
+			continue
+		}
+		n, err := strconv.Atoi(parts[0])
+		if err != nil || n <= 0 {
+			continue
+		}
+		switch {
+		case strings.EqualFold(parts[1], "G"):
+			groups[int32(n)] = parts[2]
+		case strings.EqualFold(parts[1], "U") && len(parts) >= 4:
+			users[int32(n)] = parts[3]
+		}
+	}
+	return users, groups
+}
+
 func ListJournalJSON(reg *mount.Registry, id string) (string, error) {
 	rows, err := ListJournal(reg, id)
 	if err != nil {
@@ -13,24 +13,28 @@ const (
 )
 
 func ComputeLayoutExt3(partSize int64) (int32, ext2.SuperBloque, int64, int64, error) {
+	return ComputeLayoutExt3With(partSize, ext2.MkfsOptions{})
+}
+
+func ComputeLayoutExt3With(partSize int64, opts ext2.MkfsOptions) (int32, ext2.SuperBloque, int64, int64, error) {
 	var sb ext2.SuperBloque
 
 	szSB := xbin.SizeOf[ext2.SuperBloque]()
 	szIn := xbin.SizeOf[ext2.Inodo]()
 	szBlk := int64(ext2.BlockSize)
 
-	den := JournalEntrySize + 1 + 3 + szIn + 3*szBlk
-	n64 := (partSize - szSB) / den
+	n64 := ext2.FitInodeCount(partSize, szSB+ext2.SuperBackupsLen(opts), JournalEntrySize+szIn+3*szBlk+ext2.ChecksumPerInode(opts)+ext2.RefcountPerInode(opts), opts)
 	if n64 < 2 {
 		return 0, sb, 0, 0, ext2.ErrPartTooSmall
 	}
 	n := int32(n64)
+	bmInLen, bmBlLen := ext2.BitmapRegionLens(n, opts)
 
 	sbOff := int64(0)
 	journalOff := sbOff + szSB
 	bmInOff := journalOff + int64(n)*JournalEntrySize
-	bmBlOff := bmInOff + int64(n)
-	inTblOff := bmBlOff + 3*int64(n)
+	bmBlOff := bmInOff + bmInLen
+	inTblOff := bmBlOff + bmBlLen
 	blkTblOff := inTblOff + int64(n)*szIn
 
 	sb = ext2.SuperBloque{
@@ -42,7 +46,7 @@ func ComputeLayoutExt3(partSize int64) (int32, ext2.SuperBloque, int64, int64, e
 		SMtime:           time.Now().Unix(),
 		SUmtime:          0,
 		SMntCount:        1,
-		SMagic:           ext2.MagicEXT2,
+		SMagic:           ext2.MagicEXT2v2,
 		SInodeS:          int32(szIn),
 		SBlockS:          int32(szBlk),
 		SFirtsIno:        0,
@@ -51,6 +55,7 @@ func ComputeLayoutExt3(partSize int64) (int32, ext2.SuperBloque, int64, int64, e
 		SBmBlockStart:    bmBlOff,
 		SInodeStart:      inTblOff,
 		SBlockStart:      blkTblOff,
+		SFeatures:        opts.Features() | ext2.FeatureJournalOwner | ext2.FeatureInodeFlags,
 	}
 
 	return n, sb, journalOff, int64(n) * JournalEntrySize, nil
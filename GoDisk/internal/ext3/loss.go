@@ -30,9 +30,10 @@ func Loss(reg *mount.Registry, id string) error {
 	if !ok {
 		return fmt.Errorf("loss: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 
-	var sb ext2.SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
 		return fmt.Errorf("loss: leyendo SB: %w", err)
 	}
 
@@ -45,8 +46,8 @@ func Loss(reg *mount.Registry, id string) error {
 	inTblOff := mp.Start + sb.SInodeStart
 	blkTblOff := mp.Start + sb.SBlockStart
 
-	bmInLen := int64(sb.SInodesCount)
-	bmBlLen := int64(sb.SBlocksCount)
+	bmInLen := ext2.BitmapDiskLen(sb, sb.SInodesCount)
+	bmBlLen := ext2.BitmapDiskLen(sb, sb.SBlocksCount)
 	inTblLen := int64(sb.SInodesCount) * int64(sb.SInodeS)
 	blkTblLen := int64(sb.SBlocksCount) * int64(ext2.BlockSize)
 
@@ -62,6 +63,13 @@ func Loss(reg *mount.Registry, id string) error {
 	if err := zeroRegion(mp.DiskPath, blkTblOff, blkTblLen); err != nil {
 		return fmt.Errorf("loss: limpiando área de bloques: %w", err)
 	}
+	// Sin esto todo lo borrado aparecería como corrupto.
+	if err := ext2.ResetChecksums(mp, sb); err != nil {
+		return fmt.Errorf("loss: limpiando checksums: %w", err)
+	}
+	if err := ext2.ResetRefcounts(mp, sb); err != nil {
+		return fmt.Errorf("loss: limpiando referencias: %w", err)
+	}
 
 	return nil
 }
@@ -12,22 +12,33 @@ type Formatter struct{ reg *mount.Registry }
 func NewFormatter(reg *mount.Registry) *Formatter { return &Formatter{reg: reg} }
 
 func (f *Formatter) MkfsFull(id string) error {
+	return f.MkfsWith(id, ext2.MkfsOptions{BackupSuperblocks: true, Refcounts: true})
+}
+
+func (f *Formatter) MkfsWith(id string, opts ext2.MkfsOptions) error {
 	mp, ok := f.reg.GetByID(id)
 	if !ok {
 		return fmt.Errorf("mkfs: id %s no está montado", id)
 	}
+	defer lockW(&mp)()
 	partStart := mp.Start
 	partSize := mp.Size
 
-	_, sb, jOff, jLen, err := ComputeLayoutExt3(partSize)
+	_, sb, jOff, jLen, err := ComputeLayoutExt3With(partSize, opts)
 	if err != nil {
 		return err
 	}
 
 	// Escribe SB
-	if err := writeAt(mp.DiskPath, partStart, sb); err != nil {
+	if err := ext2.WriteSuperBlock(mp, sb); err != nil {
 		return fmt.Errorf("mkfs: error escribiendo superbloque: %w", err)
 	}
+	if err := ext2.ResetChecksums(mp, sb); err != nil {
+		return fmt.Errorf("mkfs: inicializando checksums: %w", err)
+	}
+	if err := ext2.ResetRefcounts(mp, sb); err != nil {
+		return fmt.Errorf("mkfs: inicializando referencias: %w", err)
+	}
 
 	// Inicializa el área de journal (cero)
 	if err := writeBytes(mp.DiskPath, partStart+jOff, make([]byte, jLen)); err != nil {
@@ -44,17 +55,17 @@ func (f *Formatter) MkfsFull(id string) error {
 	ext2.MarkBlock(bmBl, 1, true)
 
 	// Persistir bitmaps
-	if err := writeBytes(mp.DiskPath, partStart+sb.SBmInodeStart, bmIn); err != nil {
+	if err := writeBytes(mp.DiskPath, partStart+sb.SBmInodeStart, ext2.EncodeBitmap(sb, bmIn)); err != nil {
 		return err
 	}
-	if err := writeBytes(mp.DiskPath, partStart+sb.SBmBlockStart, bmBl); err != nil {
+	if err := writeBytes(mp.DiskPath, partStart+sb.SBmBlockStart, ext2.EncodeBitmap(sb, bmBl)); err != nil {
 		return err
 	}
 
 	// Inodos iniciales
 	inoRoot := newInodoCarpeta()
 	inoRoot.IBlock[0] = 0
-	if err := writeAt(mp.DiskPath, partStart+sb.SInodeStart+0*int64(sb.SInodeS), inoRoot); err != nil {
+	if err := ext2.WriteInode(mp, sb, 0, inoRoot); err != nil {
 		return err
 	}
 
@@ -62,7 +73,7 @@ func (f *Formatter) MkfsFull(id string) error {
 	contentLen := len([]byte(usersBootstrap))
 	inoUsers := newInodoArchivo(contentLen)
 	inoUsers.IBlock[0] = 1
-	if err := writeAt(mp.DiskPath, partStart+sb.SInodeStart+1*int64(sb.SInodeS), inoUsers); err != nil {
+	if err := ext2.WriteInode(mp, sb, 1, inoUsers); err != nil {
 		return err
 	}
 
@@ -75,10 +86,10 @@ func (f *Formatter) MkfsFull(id string) error {
 	copy(rootBlk.BContent[2].BName[:], []byte("users.txt"))
 	rootBlk.BContent[2].BInodo = 1
 
-	if err := writeAt(mp.DiskPath, partStart+sb.SBlockStart+0*int64(ext2.BlockSize), rootBlk); err != nil {
+	if err := ext2.WriteBlock(mp, sb, 0, rootBlk); err != nil {
 		return err
 	}
-	if err := writeAt(mp.DiskPath, partStart+sb.SBlockStart+1*int64(ext2.BlockSize), users); err != nil {
+	if err := ext2.WriteBlock(mp, sb, 1, users); err != nil {
 		return err
 	}
 
@@ -88,5 +99,5 @@ func (f *Formatter) MkfsFull(id string) error {
 	sb.SFirtsIno = ext2.FirstFree(bmIn)
 	sb.SFirstBlo = ext2.FirstFree(bmBl)
 
-	return writeAt(mp.DiskPath, partStart, sb)
+	return ext2.WriteSuperBlock(mp, sb)
 }
@@ -6,20 +6,22 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/xbin"
 )
 
 type ReplayReport struct {
-	Total   int            `json:"total"`
-	Applied int            `json:"applied"`
-	Skipped int            `json:"skipped"`
-	Failed  int            `json:"failed"`
-	ByOp    map[string]int `json:"by_op"`
-	Details []string       `json:"details"`
+	Total    int            `json:"total"`
+	Applied  int            `json:"applied"`
+	Skipped  int            `json:"skipped"`
+	Failed   int            `json:"failed"`
+	Excluded int            `json:"excluded"` // posteriores al corte (-until/-upto)
+	DryRun   bool           `json:"dry_run"`
+	ByOp     map[string]int `json:"by_op"`
+	Details  []string       `json:"details"`
 }
 
 func trimNull(b []byte) string {
@@ -84,13 +86,13 @@ func readAllJournalEntries(mp *mount.MountedPartition, sb ext2.SuperBloque) ([]s
 	if cap <= 0 {
 		return nil, nil
 	}
-	entrySize := int64(xbin.SizeOf[structs.Journal]())
+	entrySize := entrySize(sb)
 
-	var cur structs.Journal
 	var out []structs.Journal
 	for i := int64(0); i < cap; i++ {
 		off := mp.Start + jOff + i*entrySize
-		if err := readAt(mp.DiskPath, off, &cur); err != nil {
+		cur, err := readEntry(mp, sb, off)
+		if err != nil {
 			return nil, fmt.Errorf("recovery: leyendo journal[%d]: %w", i, err)
 		}
 		if cur.JCount > 0 {
@@ -101,34 +103,158 @@ func readAllJournalEntries(mp *mount.MountedPartition, sb ext2.SuperBloque) ([]s
 	return out, nil
 }
 
+// loadJournalForRecovery lee el journal bajo candado compartido; el
+// re-formateo y la re-aplicación toman luego sus propios candados.
+func loadJournalForRecovery(mp *mount.MountedPartition) (ext2.SuperBloque, []structs.Journal, error) {
+	defer lockR(&mp)()
+
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
+		return sb, nil, fmt.Errorf("recovery: leyendo SB: %w", err)
+	}
+	if sb.SFilesystemType != FileSystemTypeExt3 {
+		return sb, nil, errors.New("recovery: solo aplica para particiones EXT3")
+	}
+	entries, err := readAllJournalEntries(mp, sb)
+	return sb, entries, err
+}
+
+// RecoverOptions limita la re-aplicación del journal. Con Until y/o UpTo
+// sólo se re-aplican las entradas hasta ese instante o ese JCount (las
+// posteriores se descartan, lo que deja la partición como estaba en ese
+// punto). DryRun arma el reporte sin tocar el disco.
+type RecoverOptions struct {
+	Until  time.Time
+	UpTo   int32
+	DryRun bool
+}
+
+func (o RecoverOptions) includes(e structs.Journal) bool {
+	if o.UpTo > 0 && e.JCount > o.UpTo {
+		return false
+	}
+	// Al segundo, como las muestra el reporte de journaling: -until con la
+	// fecha de una entrada la incluye.
+	if !o.Until.IsZero() && int64(e.JContent.I_date) > o.Until.Unix() {
+		return false
+	}
+	return true
+}
+
 func RecoverWithReport(reg *mount.Registry, id string) (ReplayReport, error) {
+	return RecoverWithOptions(reg, id, RecoverOptions{})
+}
+
+func RecoverWithOptions(reg *mount.Registry, id string, opts RecoverOptions) (ReplayReport, error) {
 	rep := ReplayReport{
-		ByOp: make(map[string]int),
+		ByOp:   make(map[string]int),
+		DryRun: opts.DryRun,
 	}
 
 	mp, ok := reg.GetByID(id)
 	if !ok {
 		return rep, fmt.Errorf("recovery: id %s no está montado", id)
 	}
-	var sb ext2.SuperBloque
-	if err := readAt(mp.DiskPath, mp.Start, &sb); err != nil {
-		return rep, fmt.Errorf("recovery: leyendo SB: %w", err)
+	sb, all, err := loadJournalForRecovery(mp)
+	if err != nil {
+		return rep, err
 	}
-	if sb.SFilesystemType != FileSystemTypeExt3 {
-		return rep, errors.New("recovery: solo aplica para particiones EXT3")
+	entries := make([]structs.Journal, 0, len(all))
+	for _, e := range all {
+		if opts.includes(e) {
+			entries = append(entries, e)
+		}
 	}
+	rep.Excluded = len(all) - len(entries)
 
-	entries, err := readAllJournalEntries(mp, sb)
+	if !opts.DryRun {
+		// users.txt no pasa por el journal: si sigue legible se conserva para
+		// que los UID/GID restaurados sigan apuntando a sus usuarios.
+		users, usersErr := ext2.ReadUsersText(reg, id)
+
+		// Re-formatear EXT3 con las mismas opciones
+		if err := NewFormatter(reg).MkfsWith(id, ext2.OptionsFromSuperBlock(sb)); err != nil {
+			return rep, fmt.Errorf("recovery: mkfs ext3: %w", err)
+		}
+		if usersErr == nil && strings.TrimSpace(users) != "" {
+			if err := ext2.RewriteUsers(reg, id, users); err != nil {
+				rep.Details = append(rep.Details, fmt.Sprintf("users.txt: no se pudo conservar: %v", err))
+			}
+		}
+		// El journal vuelve a describir la partición recuperada, así que
+		// una segunda recuperación (o un corte anterior) sigue funcionando.
+		if err := restoreJournal(mp, entries); err != nil {
+			rep.Details = append(rep.Details, fmt.Sprintf("journal: no se pudo reescribir: %v", err))
+		}
+	}
+
+	replay(reg, id, entries, opts.DryRun, &rep, nil)
+	return rep, nil
+}
+
+// ReplayOnto re-aplica el journal (EXT3) de srcID sobre destID, otra
+// partición EXT2/EXT3 ya formateada, sin re-formatearla: sirve para clonar
+// la historia de un sistema de archivos en otro. Si destID es EXT3 cada
+// operación aplicada queda también en su journal. users.txt se copia del
+// origen sólo si el destino no tiene más usuarios que root.
+func ReplayOnto(reg *mount.Registry, srcID, destID string, opts RecoverOptions) (ReplayReport, error) {
+	rep := ReplayReport{
+		ByOp:   make(map[string]int),
+		DryRun: opts.DryRun,
+	}
+	if strings.EqualFold(srcID, destID) {
+		return rep, fmt.Errorf("recovery: -src y -dest son la misma partición (usa -id)")
+	}
+	src, ok := reg.GetByID(srcID)
+	if !ok {
+		return rep, fmt.Errorf("recovery: id %s no está montado", srcID)
+	}
+	if _, ok := reg.GetByID(destID); !ok {
+		return rep, fmt.Errorf("recovery: id %s no está montado", destID)
+	}
+	_, all, err := loadJournalForRecovery(src)
 	if err != nil {
 		return rep, err
 	}
+	entries := make([]structs.Journal, 0, len(all))
+	for _, e := range all {
+		if opts.includes(e) {
+			entries = append(entries, e)
+		}
+	}
+	rep.Excluded = len(all) - len(entries)
+
+	destUsers, err := ext2.ReadUsersText(reg, destID)
+	if err != nil {
+		return rep, fmt.Errorf("recovery: destino %s sin EXT2/EXT3 legible: %w", destID, err)
+	}
 
-	// Re-formatear EXT3
-	if err := NewFormatter(reg).MkfsFull(id); err != nil {
-		return rep, fmt.Errorf("recovery: mkfs ext3: %w", err)
+	if !opts.DryRun {
+		if names, _ := parseUsersNames(destUsers); len(names) <= 1 {
+			if users, err := ext2.ReadUsersText(reg, srcID); err == nil && strings.TrimSpace(users) != "" {
+				if err := ext2.RewriteUsers(reg, destID, users); err != nil {
+					rep.Details = append(rep.Details, fmt.Sprintf("users.txt: no se pudo copiar: %v", err))
+				}
+			}
+		} else {
+			rep.Details = append(rep.Details, "users.txt: el destino ya tiene usuarios; se conserva")
+		}
 	}
 
-	// Re-aplicar como root
+	replay(reg, destID, entries, opts.DryRun, &rep, func(op, pth, raw string, uid, gid int) {
+		if _, err := AppendJournalEntry(reg, destID, op, pth, raw, int32(uid), int32(gid), true); err != nil {
+			rep.Details = append(rep.Details, fmt.Sprintf("%s %q: journal destino: %v", op, pth, err))
+		}
+	})
+	return rep, nil
+}
+
+// replay re-aplica entries sobre id y acumula el resultado en rep; en
+// dry-run sólo cuenta. applied (opcional) se llama tras cada operación
+// aplicada.
+func replay(reg *mount.Registry, id string, entries []structs.Journal, dryRun bool, rep *ReplayReport,
+	applied func(op, pth, raw string, uid, gid int)) {
+	// Las entradas sin dueño (formato anterior o DEFRAG) se aplican como root.
 	const rootUID, rootGID = 1, 1
 
 	for _, e := range entries {
@@ -138,11 +264,11 @@ func RecoverWithReport(reg *mount.Registry, id string) (ReplayReport, error) {
 		pth := strings.TrimSpace(trimNull(e.JContent.I_path[:]))
 		raw := strings.TrimSpace(trimNull(e.JContent.I_content[:]))
 		kv := parseKV(raw)
-
-		applyOK := func() {
-			rep.Applied++
-			rep.ByOp[op]++
+		uid, gid := int(e.JContent.I_uid), int(e.JContent.I_gid)
+		if uid <= 0 {
+			uid, gid = rootUID, rootGID
 		}
+
 		fail := func(format string, a ...any) {
 			rep.Failed++
 			rep.Details = append(rep.Details, fmt.Sprintf(format, a...))
@@ -151,34 +277,57 @@ func RecoverWithReport(reg *mount.Registry, id string) (ReplayReport, error) {
 			rep.Skipped++
 			rep.Details = append(rep.Details, fmt.Sprintf(format, a...))
 		}
+		// apply ejecuta la operación (en dry-run sólo la cuenta).
+		apply := func(desc string, fn func() error) {
+			if !dryRun {
+				if err := fn(); err != nil {
+					fail("%s: %v", desc, err)
+					return
+				}
+				if applied != nil {
+					applied(op, pth, raw, uid, gid)
+				}
+			}
+			rep.Applied++
+			rep.ByOp[op]++
+		}
+
+		if e.JContent.I_status == structs.JournalStatusFailed {
+			skip("%s %q: falló originalmente", op, pth)
+			continue
+		}
 
 		switch op {
 		case "MKDIR":
-			if err := ext2.MakeDir(reg, id, pth, true, rootUID, rootGID); err != nil {
-				fail("MKDIR %q: %v", pth, err)
-				continue
-			}
-			applyOK()
+			apply(fmt.Sprintf("MKDIR %q", pth), func() error {
+				return ext2.MakeDir(reg, id, pth, true, uid, gid)
+			})
 
 		case "MKFILE":
-			data := []byte(raw)
-			if len(data) == 0 {
-				size := pint(kv, "size", 0)
-				data = genData(size)
-			}
-			if err := ext2.CreateOrOverwriteFile(reg, id, pth, data, true, true, rootUID, rootGID); err != nil {
-				fail("MKFILE %q: %v", pth, err)
-				continue
-			}
-			applyOK()
+			apply(fmt.Sprintf("MKFILE %q", pth), func() error {
+				data := []byte(raw)
+				if len(data) == 0 {
+					data = genData(pint(kv, "size", 0))
+				}
+				return ext2.CreateOrOverwriteFile(reg, id, pth, data, true, true, false, uid, gid)
+			})
 
 		case "EDIT":
-			data := []byte(raw)
-			if err := ext2.EditFile(reg, id, pth, data, rootUID, rootGID, true); err != nil {
-				fail("EDIT %q: %v", pth, err)
-				continue
-			}
-			applyOK()
+			apply(fmt.Sprintf("EDIT %q", pth), func() error {
+				return ext2.EditFile(reg, id, pth, []byte(raw), uid, gid, true)
+			})
+
+		case "APPEND", "TRUNCATE":
+			apply(fmt.Sprintf("%s %q", op, pth), func() error {
+				f, err := ext2.OpenFile(reg, id, pth, uid, gid, true)
+				if err != nil {
+					return err
+				}
+				if op == "APPEND" {
+					return f.Append([]byte(raw))
+				}
+				return f.Truncate(int64(pint(kv, "size", 0)))
+			})
 
 		case "COPY":
 			dst := kv["dest"]
@@ -186,11 +335,9 @@ func RecoverWithReport(reg *mount.Registry, id string) (ReplayReport, error) {
 				skip("COPY %q: falta dest=", pth)
 				continue
 			}
-			if err := ext2.CopyNode(reg, id, pth, dst, rootUID, rootGID, true); err != nil {
-				fail("COPY %q->%q: %v", pth, dst, err)
-				continue
-			}
-			applyOK()
+			apply(fmt.Sprintf("COPY %q->%q", pth, dst), func() error {
+				return ext2.CopyNode(reg, id, pth, dst, uid, gid, true)
+			})
 
 		case "MOVE":
 			dst := kv["dest"]
@@ -198,18 +345,14 @@ func RecoverWithReport(reg *mount.Registry, id string) (ReplayReport, error) {
 				skip("MOVE %q: falta dest=", pth)
 				continue
 			}
-			if err := ext2.MoveNode(reg, id, pth, dst, rootUID, rootGID, true); err != nil {
-				fail("MOVE %q->%q: %v", pth, dst, err)
-				continue
-			}
-			applyOK()
+			apply(fmt.Sprintf("MOVE %q->%q", pth, dst), func() error {
+				return ext2.MoveNode(reg, id, pth, dst, uid, gid, true)
+			})
 
 		case "REMOVE":
-			if err := ext2.Remove(reg, id, pth, rootUID, rootGID); err != nil {
-				fail("REMOVE %q: %v", pth, err)
-				continue
-			}
-			applyOK()
+			apply(fmt.Sprintf("REMOVE %q", pth), func() error {
+				return ext2.Remove(reg, id, pth, uid, gid)
+			})
 
 		case "RENAME":
 			newName := kv["name"]
@@ -217,25 +360,26 @@ func RecoverWithReport(reg *mount.Registry, id string) (ReplayReport, error) {
 				skip("RENAME %q: falta name=", pth)
 				continue
 			}
-			if err := ext2.RenameNode(reg, id, pth, newName, rootUID, rootGID, true); err != nil {
-				fail("RENAME %q->%q: %v", pth, newName, err)
-				continue
-			}
-			applyOK()
+			apply(fmt.Sprintf("RENAME %q->%q", pth, newName), func() error {
+				return ext2.RenameNode(reg, id, pth, newName, uid, gid, true)
+			})
 
 		case "CHMOD":
-			ugo := kv["ugo"]
-			perms, perr := ext2.ParseUGO(ugo)
+			perms, perr := ext2.ParseUGO(kv["ugo"])
 			if perr != nil {
 				fail("CHMOD %q: %v", pth, perr)
 				continue
 			}
-			rec := pbool(kv, "r", false)
-			if err := ext2.Chmod(reg, id, pth, perms, rec, rootUID, rootGID, true); err != nil {
-				fail("CHMOD %q: %v", pth, err)
-				continue
-			}
-			applyOK()
+			rec := recursive(kv)
+			apply(fmt.Sprintf("CHMOD %q", pth), func() error {
+				return ext2.Chmod(reg, id, pth, perms, rec, uid, gid, true)
+			})
+
+		case "CHATTR":
+			on := pbool(kv, "compress", false)
+			apply(fmt.Sprintf("CHATTR %q", pth), func() error {
+				return ext2.SetCompression(reg, id, pth, on, uid, gid, true)
+			})
 
 		case "CHOWN":
 			user := kv["usuario"]
@@ -243,18 +387,49 @@ func RecoverWithReport(reg *mount.Registry, id string) (ReplayReport, error) {
 				skip("CHOWN %q: falta usuario=", pth)
 				continue
 			}
-			rec := pbool(kv, "r", false)
-			if err := ext2.Chown(reg, id, pth, user, rec, rootUID, rootGID, true); err != nil {
-				fail("CHOWN %q: %v", pth, err)
-				continue
-			}
-			applyOK()
+			rec := recursive(kv)
+			apply(fmt.Sprintf("CHOWN %q", pth), func() error {
+				return ext2.Chown(reg, id, pth, user, rec, uid, gid, true)
+			})
+
+		case "DEFRAG":
+			// Sólo reubica bloques; el contenido ya queda cubierto por el resto.
+			skip("DEFRAG %q: no se re-aplica", pth)
 
 		default:
 			skip("op desconocida %q (path=%q)", op, pth)
 		}
 	}
-	return rep, nil
+}
+
+// restoreJournal escribe entries (ordenadas por JCount) en el journal recién
+// formateado conservando su JCount, cada una en el slot (JCount-1)%cap que
+// espera appendJournalEntry. Si no caben se quedan las más recientes.
+func restoreJournal(mp *mount.MountedPartition, entries []structs.Journal) error {
+	defer lockW(&mp)()
+
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
+		return err
+	}
+	jOff, cap := journalRegion(sb)
+	if cap <= 0 || len(entries) == 0 {
+		return nil
+	}
+	// Se conserva la ventana de JCount que cabe: con huecos (p. ej. tras
+	// -until/-upto) contar entradas no basta y dos caerían en el mismo slot.
+	last := entries[len(entries)-1].JCount
+	size := entrySize(sb)
+	for _, e := range entries {
+		if int64(last-e.JCount) >= cap {
+			continue
+		}
+		slot := int64(e.JCount-1) % cap
+		if err := writeEntry(mp, sb, mp.Start+jOff+slot*size, e); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func Recover(reg *mount.Registry, id string) error {
@@ -0,0 +1,228 @@
+package ext3
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+func TestRecoverOptionsIncludes(t *testing.T) {
+	t0 := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(n int32, at time.Time) structs.Journal {
+		return structs.NewJournal(n, structs.NewInformation("MKDIR", "/a", "", at))
+	}
+	cases := []struct {
+		name string
+		opts RecoverOptions
+		e    structs.Journal
+		want bool
+	}{
+		{"sin límites", RecoverOptions{}, entry(9, t0), true},
+		{"until antes", RecoverOptions{Until: t0}, entry(1, t0.Add(-time.Second)), true},
+		{"until mismo segundo", RecoverOptions{Until: t0}, entry(1, t0.Add(900*time.Millisecond)), true},
+		{"until después", RecoverOptions{Until: t0}, entry(1, t0.Add(time.Second)), false},
+		{"upto igual", RecoverOptions{UpTo: 3}, entry(3, t0), true},
+		{"upto mayor", RecoverOptions{UpTo: 3}, entry(4, t0), false},
+		{"until y upto, corta upto", RecoverOptions{Until: t0, UpTo: 2}, entry(3, t0.Add(-time.Hour)), false},
+		{"until y upto, corta until", RecoverOptions{Until: t0, UpTo: 5}, entry(3, t0.Add(time.Hour)), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.opts.includes(c.e); got != c.want {
+				t.Fatalf("includes = %v, se esperaba %v", got, c.want)
+			}
+		})
+	}
+}
+
+// newExt3 crea un disco con una partición EXT3 montada y devuelve la
+// capacidad de su journal.
+func newExt3(t *testing.T) (*mount.Registry, string, int64) {
+	t.Helper()
+	const size = 512 << 10
+	path := filepath.Join(t.TempDir(), "j.mia")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, size); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { diskio.CloseHandle(path) })
+	if err := diskio.WriteMBR(path, structs.NewMBR(size, 'f', 1)); err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := diskio.OpenTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tbl.Create('P', "p1", 'F', 256<<10); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := mount.NewRegistry()
+	id, err := mount.NewService(reg).Mount(path, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFormatter(reg).MkfsWith(id, ext2.MkfsOptions{BackupSuperblocks: true, Refcounts: true}); err != nil {
+		t.Fatal(err)
+	}
+	mp, _ := reg.GetByID(id)
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, cap := journalRegion(sb)
+	if cap < 2 {
+		t.Fatalf("journal de %d entradas", cap)
+	}
+	return reg, id, cap
+}
+
+// journalCounts devuelve los JCount del journal en orden.
+func journalCounts(t *testing.T, reg *mount.Registry, id string) []int32 {
+	t.Helper()
+	mp, _ := reg.GetByID(id)
+	sb, err := ext2.ReadSuperBlock(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readAllJournalEntries(mp, sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]int32, len(entries))
+	for i, e := range entries {
+		out[i] = e.JCount
+	}
+	return out
+}
+
+func countRange(from, to int32) []int32 {
+	var out []int32
+	for c := from; c <= to; c++ {
+		out = append(out, c)
+	}
+	return out
+}
+
+func equalCounts(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRecoverFullJournalThenAppend(t *testing.T) {
+	reg, id, cap := newExt3(t)
+	// Con cap+4 entradas el anillo da la vuelta: quedan los JCount 5..cap+4.
+	for i := int64(0); i < cap+4; i++ {
+		// DEFRAG no se re-aplica: la recuperación sólo reescribe el journal.
+		if _, err := AppendJournalEntry(reg, id, "DEFRAG", "/", "", 1, 1, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := journalCounts(t, reg, id)
+	first, last := before[0], before[len(before)-1]
+	if int64(len(before)) != cap || int64(last-first+1) != cap {
+		t.Fatalf("journal antes de recuperar: %v", before)
+	}
+
+	if _, err := RecoverWithOptions(reg, id, RecoverOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := journalCounts(t, reg, id); !equalCounts(got, before) {
+		t.Fatalf("journal recuperado = %v, se esperaba %v", got, before)
+	}
+
+	n, err := AppendJournalEntry(reg, id, "DEFRAG", "/", "", 1, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != last+1 {
+		t.Fatalf("JCount nuevo = %d, se esperaba %d", n, last+1)
+	}
+	// Sólo se pisó la más antigua.
+	if got, want := journalCounts(t, reg, id), countRange(first+1, last+1); !equalCounts(got, want) {
+		t.Fatalf("journal tras agregar = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestRecoverUpToFullJournalThenAppend(t *testing.T) {
+	reg, id, cap := newExt3(t)
+	for i := int64(0); i < cap+4; i++ {
+		if _, err := AppendJournalEntry(reg, id, "DEFRAG", "/", "", 1, 1, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := journalCounts(t, reg, id)
+	first, last := before[0], before[len(before)-1]
+
+	// -upto descarta las dos últimas; los slots que quedan libres son los
+	// que les tocaban.
+	rep, err := RecoverWithOptions(reg, id, RecoverOptions{UpTo: last - 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Excluded != 2 {
+		t.Fatalf("Excluded = %d, se esperaba 2", rep.Excluded)
+	}
+	if got, want := journalCounts(t, reg, id), countRange(first, last-2); !equalCounts(got, want) {
+		t.Fatalf("journal recuperado = %v, se esperaba %v", got, want)
+	}
+
+	cases := []struct {
+		count    int32
+		from, to int32
+	}{
+		{last - 1, first, last - 1},
+		{last, first, last}, // lleno otra vez
+		{last + 1, first + 1, last + 1},
+		{last + 2, first + 2, last + 2},
+	}
+	for _, c := range cases {
+		n, err := AppendJournalEntry(reg, id, "DEFRAG", "/", "", 1, 1, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != c.count {
+			t.Fatalf("JCount nuevo = %d, se esperaba %d", n, c.count)
+		}
+		if got, want := journalCounts(t, reg, id), countRange(c.from, c.to); !equalCounts(got, want) {
+			t.Fatalf("tras agregar %d: journal = %v, se esperaba %v", n, got, want)
+		}
+	}
+}
+
+func TestRestoreJournalKeepsNewestWindow(t *testing.T) {
+	reg, id, cap := newExt3(t)
+	mp, _ := reg.GetByID(id)
+	c := int32(cap)
+
+	// Con huecos, cap entradas pueden abarcar más de cap JCount: sólo
+	// entran las de la ventana (last-cap, last].
+	var entries []structs.Journal
+	for _, n := range append([]int32{1}, countRange(c+2, 2*c)...) {
+		entries = append(entries, structs.NewJournal(n, structs.NewInformation("DEFRAG", "/", "", time.Now())))
+	}
+	if err := restoreJournal(mp, entries); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := journalCounts(t, reg, id), countRange(c+2, 2*c); !equalCounts(got, want) {
+		t.Fatalf("journal = %v, se esperaba %v", got, want)
+	}
+}
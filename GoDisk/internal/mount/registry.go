@@ -3,6 +3,9 @@ package mount
 import (
 	"sort"
 	"sync"
+	"sync/atomic"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
 )
 
 type MountedPartition struct {
@@ -13,6 +16,30 @@ type MountedPartition struct {
 	ID       string
 	Start    int64
 	Size     int64
+	Fit      byte // ajuste del disco (Dsk_fit): 'b', 'f' o 'w'
+
+	moved atomic.Pointer[MountedPartition] // entrada que la reemplazó (Relocate)
+}
+
+// Lock toma el candado de la partición *mp (exclusivo si write) y deja en
+// *mp la entrada vigente: si un fdisk -move la reubicó mientras se esperaba,
+// suelta y reintenta con la nueva. Devuelve la función de liberación.
+func Lock(mp **MountedPartition, write bool) func() {
+	for {
+		p := *mp
+		var unlock func()
+		if write {
+			unlock = diskio.LockPartition(p.DiskPath, p.Start)
+		} else {
+			unlock = diskio.RLockPartition(p.DiskPath, p.Start)
+		}
+		next := p.moved.Load()
+		if next == nil {
+			return unlock
+		}
+		unlock()
+		*mp = next
+	}
 }
 
 type MountedDisk struct {
@@ -109,6 +136,36 @@ func (r *Registry) AddMountedPartition(mp *MountedPartition) error {
 	return nil
 }
 
+// Relocate reemplaza la partición montada diskPath/partName por una copia
+// con el nuevo Start (tras un fdisk -move); quien tenga la entrada vieja la
+// vuelve a resolver en Lock. Devuelve false si no estaba montada.
+func (r *Registry) Relocate(diskPath, partName string, start int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	md, ok := r.disks[diskPath]
+	if !ok {
+		return false
+	}
+	mp, ok := md.byName[partName]
+	if !ok {
+		return false
+	}
+	nm := &MountedPartition{
+		DiskPath: mp.DiskPath,
+		PartName: mp.PartName,
+		Letter:   mp.Letter,
+		Number:   mp.Number,
+		ID:       mp.ID,
+		Start:    start,
+		Size:     mp.Size,
+		Fit:      mp.Fit,
+	}
+	md.byName[partName] = nm
+	md.byNum[mp.Number] = nm
+	mp.moved.Store(nm)
+	return true
+}
+
 func (r *Registry) GetByID(id string) (*MountedPartition, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -1,14 +1,12 @@
 package mount
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/catalog"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
 )
 
 func (r *Registry) RehydrateFromCatalog() error {
@@ -38,7 +36,7 @@ func (r *Registry) RehydrateFromDisks(diskPaths []string) error {
 			continue
 		}
 
-		mbr, err := diskio.ReadMBR(path)
+		table, err := diskio.OpenTable(path)
 		if err != nil {
 
 			if os.IsNotExist(err) {
@@ -47,7 +45,7 @@ func (r *Registry) RehydrateFromDisks(diskPaths []string) error {
 				continue
 			}
 
-			fmt.Printf("rehydrate: no se pudo leer MBR de %q: %v\n", path, err)
+			fmt.Printf("rehydrate: no se pudo leer la tabla de particiones de %q: %v\n", path, err)
 			continue
 		}
 
@@ -65,16 +63,15 @@ func (r *Registry) RehydrateFromDisks(diskPaths []string) error {
 		var maxCorrelative int
 		var foundLetter rune
 
-		for i := range mbr.Mbr_partitions {
-			p := &mbr.Mbr_partitions[i]
-			if !isPrimaryUsable(p) || p.Part_status != '1' {
+		for _, p := range table.Partitions() {
+			if !isPrimaryUsable(p) || p.Status != '1' {
 				continue
 			}
-			name := bytesToString(p.Part_name[:])
-			id := bytesToString(p.Part_id[:])
+			name := p.Name
+			id := p.ID
 
 			var (
-				num    = int(p.Part_correlative)
+				num    = int(p.Correlative)
 				hasNum = num > 0
 				letter rune
 				hasID  bool
@@ -103,8 +100,8 @@ func (r *Registry) RehydrateFromDisks(diskPaths []string) error {
 				name:      name,
 				id:        id,
 				number:    num,
-				start:     p.Part_start,
-				size:      p.Part_s,
+				start:     p.Start,
+				size:      p.Size,
 				idLetter:  letter,
 				hasID:     hasID,
 				hasNumber: hasNum,
@@ -165,6 +162,7 @@ func (r *Registry) RehydrateFromDisks(diskPaths []string) error {
 				ID:       id,
 				Start:    mi.start,
 				Size:     mi.size,
+				Fit:      table.Fit(),
 			}
 			_ = r.AddMountedPartition(mp)
 		}
@@ -180,13 +178,8 @@ func (r *Registry) RehydrateFromDisks(diskPaths []string) error {
 	return nil
 }
 
-func isPrimaryUsable(p *structs.Partition) bool {
-	return (p.Part_type == 'P' || p.Part_type == 'p') && p.Part_s > 0 && p.Part_start >= 0
-}
-
-func bytesToString(b []byte) string {
-	b = bytes.TrimRight(b, "\x00")
-	return string(b)
+func isPrimaryUsable(p diskio.PartInfo) bool {
+	return p.Type == 'P' && p.Usable()
 }
 
 func parseNumberFromID(id string) (int, bool) {
@@ -1,7 +1,6 @@
 package mount
 
 import (
-	"bytes"
 	"fmt"
 	"strings"
 
@@ -27,13 +26,13 @@ func (s *Service) Mount(diskPath, partName string) (string, error) {
 		return "", Wrap(ErrAlreadyMounted, "ya montada: id=%s", mp.ID)
 	}
 
-	mbr, err := diskio.ReadMBR(diskPath)
+	table, err := diskio.OpenTable(diskPath)
 	if err != nil {
 		return "", Wrap(ErrMBRRead, "path=%s: %v", diskPath, err)
 	}
 
-	idx, p := diskio.FindPrimaryByName(&mbr, partName)
-	if idx < 0 || p == nil {
+	p, found := table.FindPrimary(partName)
+	if !found {
 		return "", Wrap(ErrPartitionNotFound, "path=%s name=%s", diskPath, partName)
 	}
 
@@ -47,8 +46,8 @@ func (s *Service) Mount(diskPath, partName string) (string, error) {
 	}
 	id := BuildID(number, letter)
 
-	start := p.Part_start
-	size := p.Part_s
+	start := p.Start
+	size := p.Size
 	mp := &MountedPartition{
 		DiskPath: diskPath,
 		PartName: partName,
@@ -57,18 +56,17 @@ func (s *Service) Mount(diskPath, partName string) (string, error) {
 		ID:       id,
 		Start:    start,
 		Size:     size,
+		Fit:      table.Fit(),
 	}
 	if err := s.reg.AddMountedPartition(mp); err != nil {
 		return "", err
 	}
 
-	p.Part_status = '1'
-	p.Part_correlative = int32(number)
-	copy(p.Part_id[:], []byte(id))
-
-	mbr.Mbr_partitions[idx] = *p
-
-	if err := diskio.WriteMBR(diskPath, mbr); err != nil {
+	err = table.SetMountMeta(partName, id, int32(number))
+	if err == nil {
+		err = table.Save()
+	}
+	if err != nil {
 		if removed, _ := s.reg.RemoveByID(id); removed == nil {
 
 		}
@@ -110,21 +108,13 @@ func (s *Service) UnmountByPathName(path, name string) error {
 }
 
 func clearMBRMountMeta(diskPath, partName string) error {
-	mbr, err := diskio.ReadMBR(diskPath)
+	table, err := diskio.OpenTable(diskPath)
 	if err != nil {
 		return err
 	}
-	for i := range mbr.Mbr_partitions {
-		p := &mbr.Mbr_partitions[i]
-		name := string(bytes.TrimRight(p.Part_name[:], "\x00"))
-		if name == partName {
-			// borrar ID y correlativo para que no se "rehidrate" como montada
-			for i := range p.Part_id {
-				p.Part_id[i] = 0
-			}
-			p.Part_correlative = 0
-			return diskio.WriteMBR(diskPath, mbr)
-		}
+	// borrar ID y correlativo para que no se "rehidrate" como montada
+	if err := table.SetMountMeta(partName, "", 0); err != nil {
+		return ErrPartitionNotFound
 	}
-	return ErrPartitionNotFound
+	return table.Save()
 }
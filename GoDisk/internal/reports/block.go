@@ -3,13 +3,13 @@ package reports
 import (
 	"encoding/binary"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"unicode/utf8"
 
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
@@ -45,8 +45,9 @@ type DirEntry struct {
 }
 
 type FileBlockView struct {
-	Size    int    `json:"size"`
-	Preview string `json:"preview"`
+	Size       int    `json:"size"`
+	Preview    string `json:"preview"`
+	Compressed bool   `json:"compressed,omitempty"` // Preview es el archivo ya descomprimido
 }
 
 type PtrBlockView struct {
@@ -65,6 +66,7 @@ func GenerateBlock(reg *mount.Registry, id, outPath string) error {
 		return fmt.Errorf("rep block: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return fmt.Errorf("rep block: leyendo super bloque: %w", err)
@@ -79,6 +81,11 @@ func GenerateBlock(reg *mount.Registry, id, outPath string) error {
 	if err != nil {
 		return fmt.Errorf("rep block: indexando inodos: %w", err)
 	}
+	comp := compressedFileBlocks(mp, sb, bmIn)
+	refs, err := ext2.ReadRefcounts(mp, sb)
+	if err != nil {
+		return fmt.Errorf("rep block: %w", err)
+	}
 
 	usedIdxs := make([]int32, 0, int(sb.SBlocksCount))
 	for i := int32(0); i < sb.SBlocksCount; i++ {
@@ -104,6 +111,9 @@ func GenerateBlock(reg *mount.Registry, id, outPath string) error {
 			tp = "unknown"
 		}
 		cnt := refCount[bi]
+		if refs != nil {
+			cnt = 1 + int(refs[bi])
+		}
 
 		item := BlockItem{
 			Index:    bi,
@@ -122,6 +132,10 @@ func GenerateBlock(reg *mount.Registry, id, outPath string) error {
 		case "dir":
 			item.Dir = &DirBlockView{Entries: parseDirEntries(raw)}
 		case "file":
+			if v, ok := comp[bi]; ok {
+				item.File = v
+				break
+			}
 			size, prev := parseFilePreview(raw)
 			item.File = &FileBlockView{Size: size, Preview: prev}
 		case "ptr":
@@ -173,37 +187,34 @@ func GenerateBlock(reg *mount.Registry, id, outPath string) error {
 // ---------------------- Lectura / Bitmaps ----------------------
 
 func loadBitmapsForReport(mp *mount.MountedPartition, sb ext2.SuperBloque) ([]byte, []byte, error) {
-	szIn := int(sb.SInodesCount)
-	szBl := int(sb.SBlocksCount)
-
-	bmIn, err := readBytesAt(mp.DiskPath, mp.Start+sb.SBmInodeStart, szIn)
+	bmIn, err := readBitmapAt(mp, sb, sb.SBmInodeStart, sb.SInodesCount)
 	if err != nil {
 		return nil, nil, err
 	}
-	bmBl, err := readBytesAt(mp.DiskPath, mp.Start+sb.SBmBlockStart, szBl)
+	bmBl, err := readBitmapAt(mp, sb, sb.SBmBlockStart, sb.SBlocksCount)
 	if err != nil {
 		return nil, nil, err
 	}
 	return bmIn, bmBl, nil
 }
 
+// readBitmapAt lee un bitmap en el formato del SB (bytes o bits) y lo
+// devuelve expandido a 1 byte por entrada.
+func readBitmapAt(mp *mount.MountedPartition, sb ext2.SuperBloque, rel int64, n int32) ([]byte, error) {
+	raw, err := readBytesAt(mp.DiskPath, mp.Start+rel, int(ext2.BitmapDiskLen(sb, n)))
+	if err != nil {
+		return nil, err
+	}
+	return ext2.DecodeBitmap(sb, raw, n), nil
+}
+
 func readBlockBytes(mp *mount.MountedPartition, sb ext2.SuperBloque, blk int32) ([]byte, error) {
 	off := mp.Start + sb.SBlockStart + int64(blk)*int64(ext2.BlockSize)
 	return readBytesAt(mp.DiskPath, off, int(ext2.BlockSize))
 }
 
 func readBytesAt(path string, off int64, n int) ([]byte, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return nil, err
-	}
-	buf := make([]byte, n)
-	_, err = io.ReadFull(f, buf)
-	return buf, err
+	return diskio.ReadBytes(path, off, n)
 }
 
 // ---------------------- Clasificación por inodos ----------------------
@@ -242,6 +253,47 @@ func indexBlocksFromInodes(mp *mount.MountedPartition, sb ext2.SuperBloque, bmIn
 	return refType, refCnt, nil
 }
 
+// compressedFileBlocks arma la vista de los bloques de archivos comprimidos:
+// el contenido de un bloque suelto no se puede descomprimir, así que cada
+// uno muestra lo que guarda y el archivo completo ya descomprimido.
+func compressedFileBlocks(mp *mount.MountedPartition, sb ext2.SuperBloque, bmIn []byte) map[int32]*FileBlockView {
+	out := make(map[int32]*FileBlockView)
+	for idx := int32(0); idx < sb.SInodesCount; idx++ {
+		if bmIn[idx] == 0 {
+			continue
+		}
+		ino, err := readInodeAt(mp, sb, idx)
+		if err != nil || ino.IType != 1 || !ino.Compressed() {
+			continue
+		}
+		var raw []byte
+		var blks []int32
+		for _, b := range ino.IBlock {
+			if b < 0 || b >= sb.SBlocksCount {
+				continue
+			}
+			rb, err := readBlockBytes(mp, sb, b)
+			if err != nil {
+				break
+			}
+			raw = append(raw, rb...)
+			blks = append(blks, b)
+		}
+		data, err := ext2.DecodeFileData(ino, raw)
+		if err != nil {
+			continue
+		}
+		_, prev := parseFilePreview(data)
+		rest := ino.StoredSize()
+		for _, b := range blks {
+			n := min(rest, int(ext2.BlockSize))
+			out[b] = &FileBlockView{Size: n, Preview: prev, Compressed: true}
+			rest -= n
+		}
+	}
+	return out
+}
+
 // ---------------------- Decodificadores de bloque ----------------------
 
 func parseDirEntries(raw []byte) []DirEntry {
@@ -377,6 +429,9 @@ code{background:#f7f7f7;padding:.15rem .3rem;border-radius:4px}
 				b.WriteString("(sin preview)")
 			} else {
 				fmt.Fprintf(&b, "size=%d preview=<code>%s</code>", it.File.Size, escape(it.File.Preview))
+				if it.File.Compressed {
+					b.WriteString(" (comprimido)")
+				}
 			}
 		case "ptr":
 			if it.Ptr == nil || len(it.Ptr.Pointers) == 0 {
@@ -408,6 +463,7 @@ func BuildBlock(reg *mount.Registry, id string) (BlockReport, error) {
 		return BlockReport{}, fmt.Errorf("rep block: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return BlockReport{}, fmt.Errorf("rep block: leyendo super bloque: %w", err)
@@ -422,6 +478,11 @@ func BuildBlock(reg *mount.Registry, id string) (BlockReport, error) {
 	if err != nil {
 		return BlockReport{}, fmt.Errorf("rep block: indexando inodos: %w", err)
 	}
+	comp := compressedFileBlocks(mp, sb, bmIn)
+	refs, err := ext2.ReadRefcounts(mp, sb)
+	if err != nil {
+		return BlockReport{}, fmt.Errorf("rep block: %w", err)
+	}
 
 	usedIdxs := make([]int32, 0, int(sb.SBlocksCount))
 	for i := int32(0); i < sb.SBlocksCount; i++ {
@@ -446,6 +507,9 @@ func BuildBlock(reg *mount.Registry, id string) (BlockReport, error) {
 			tp = "unknown"
 		}
 		cnt := refCount[bi]
+		if refs != nil {
+			cnt = 1 + int(refs[bi])
+		}
 
 		item := BlockItem{
 			Index:    bi,
@@ -463,6 +527,10 @@ func BuildBlock(reg *mount.Registry, id string) (BlockReport, error) {
 		case "dir":
 			item.Dir = &DirBlockView{Entries: parseDirEntries(raw)}
 		case "file":
+			if v, ok := comp[bi]; ok {
+				item.File = v
+				break
+			}
 			size, prev := parseFilePreview(raw)
 			item.File = &FileBlockView{Size: size, Preview: prev}
 		case "ptr":
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -19,6 +20,7 @@ func GenerateBmBlock(reg *mount.Registry, id, outPath string) error {
 		return fmt.Errorf("rep bm_block: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return fmt.Errorf("rep bm_block: leyendo super bloque: %w", err)
@@ -28,16 +30,20 @@ func GenerateBmBlock(reg *mount.Registry, id, outPath string) error {
 	if n <= 0 {
 		return fmt.Errorf("rep bm_block: SBlocksCount inválido: %d", n)
 	}
-	bmBl, err := readBytesAt(mp.DiskPath, mp.Start+sb.SBmBlockStart, n)
+	bmBl, err := readBitmapAt(mp, sb, sb.SBmBlockStart, sb.SBlocksCount)
 	if err != nil {
 		return fmt.Errorf("rep bm_block: leyendo bitmap: %w", err)
 	}
+	refs, err := ext2.ReadRefcounts(mp, sb)
+	if err != nil {
+		return fmt.Errorf("rep bm_block: %w", err)
+	}
 
 	const perLine = 20
 	var b strings.Builder
 	for i := 0; i < n; i++ {
 		if bmBl[i] != 0 {
-			b.WriteByte('1')
+			b.WriteByte(bmBlockCell(refs, i))
 		} else {
 			b.WriteByte('0')
 		}
@@ -60,6 +66,18 @@ func GenerateBmBlock(reg *mount.Registry, id, outPath string) error {
 
 // ---- helpers locales ----
 
+// bmBlockCell marca un bloque en uso con '1', o con su número de
+// referencias si está compartido por copy ('+' si pasa de 9).
+func bmBlockCell(refs []uint16, i int) byte {
+	if refs == nil || refs[i] == 0 {
+		return '1'
+	}
+	if n := 1 + int(refs[i]); n <= 9 {
+		return byte('0' + n)
+	}
+	return '+'
+}
+
 func resolveOutPathBmBlock(out, id string) string {
 	out = strings.TrimSpace(out)
 	def := fmt.Sprintf("bm_block_%s.txt", id)
@@ -89,6 +107,7 @@ func BuildBmBlockText(reg *mount.Registry, id string) (string, error) {
 		return "", fmt.Errorf("rep bm_block: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return "", fmt.Errorf("rep bm_block: leyendo super bloque: %w", err)
@@ -98,16 +117,20 @@ func BuildBmBlockText(reg *mount.Registry, id string) (string, error) {
 	if n <= 0 {
 		return "", fmt.Errorf("rep bm_block: SBlocksCount inválido: %d", n)
 	}
-	bmBl, err := readBytesAt(mp.DiskPath, mp.Start+sb.SBmBlockStart, n)
+	bmBl, err := readBitmapAt(mp, sb, sb.SBmBlockStart, sb.SBlocksCount)
 	if err != nil {
 		return "", fmt.Errorf("rep bm_block: leyendo bitmap: %w", err)
 	}
+	refs, err := ext2.ReadRefcounts(mp, sb)
+	if err != nil {
+		return "", fmt.Errorf("rep bm_block: %w", err)
+	}
 
 	const perLine = 20
 	var b strings.Builder
 	for i := 0; i < n; i++ {
 		if bmBl[i] != 0 {
-			b.WriteByte('1')
+			b.WriteByte(bmBlockCell(refs, i))
 		} else {
 			b.WriteByte('0')
 		}
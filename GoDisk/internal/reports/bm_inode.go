@@ -19,6 +19,7 @@ func GenerateBmInode(reg *mount.Registry, id, outPath string) error {
 		return fmt.Errorf("rep bm_inode: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return fmt.Errorf("rep bm_inode: leyendo super bloque: %w", err)
@@ -28,7 +29,7 @@ func GenerateBmInode(reg *mount.Registry, id, outPath string) error {
 	if n <= 0 {
 		return fmt.Errorf("rep bm_inode: SInodesCount inválido: %d", n)
 	}
-	bmIn, err := readBytesAt(mp.DiskPath, mp.Start+sb.SBmInodeStart, n)
+	bmIn, err := readBitmapAt(mp, sb, sb.SBmInodeStart, sb.SInodesCount)
 	if err != nil {
 		return fmt.Errorf("rep bm_inode: leyendo bitmap: %w", err)
 	}
@@ -87,6 +88,7 @@ func BuildBmInodeText(reg *mount.Registry, id string) (string, error) {
 		return "", fmt.Errorf("rep bm_inode: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return "", fmt.Errorf("rep bm_inode: leyendo super bloque: %w", err)
@@ -96,7 +98,7 @@ func BuildBmInodeText(reg *mount.Registry, id string) (string, error) {
 		return "", fmt.Errorf("rep bm_inode: SInodesCount inválido: %d", n)
 	}
 
-	bmIn, err := readBytesAt(mp.DiskPath, mp.Start+sb.SBmInodeStart, n)
+	bmIn, err := readBitmapAt(mp, sb, sb.SBmInodeStart, sb.SInodesCount)
 	if err != nil {
 		return "", fmt.Errorf("rep bm_inode: leyendo bitmap: %w", err)
 	}
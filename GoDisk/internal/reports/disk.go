@@ -15,12 +15,15 @@ import (
 )
 
 type DiskReport struct {
-	Kind     string        `json:"kind"`
-	DiskPath string        `json:"diskPath"`
-	Size     int64         `json:"sizeBytes"`
-	MBRSize  int64         `json:"mbrBytes"`
-	Segments []DiskSegment `json:"segments"`
-	Extended *ExtendedView `json:"extended,omitempty"`
+	Kind           string        `json:"kind"`
+	Table          string        `json:"table"` // "mbr" | "gpt"
+	DiskPath       string        `json:"diskPath"`
+	Size           int64         `json:"sizeBytes"`
+	MBRSize        int64         `json:"mbrBytes"`
+	AllocatedBytes int64         `json:"allocatedBytes"` // lo que el .mia ocupa en el host
+	Encrypted      bool          `json:"encrypted"`
+	Segments       []DiskSegment `json:"segments"`
+	Extended       *ExtendedView `json:"extended,omitempty"`
 }
 
 type DiskSegment struct {
@@ -39,101 +42,12 @@ type ExtendedView struct {
 }
 
 func GenerateDisk(reg *mount.Registry, id, outPath string) error {
-	id = strings.TrimSpace(id)
-	if id == "" {
-		return fmt.Errorf("rep disk: -id requerido")
-	}
-
-	mp, ok := reg.GetByID(id)
-	if !ok {
-		return fmt.Errorf("rep disk: id %q no está montado", id)
-	}
-
-	mbr, err := diskio.ReadMBR(mp.DiskPath)
+	rep, err := BuildDisk(reg, id)
 	if err != nil {
-		return fmt.Errorf("rep disk: leyendo MBR: %w", err)
-	}
-
-	var m structs.MBR
-	mbrSize := int64(binary.Size(m))
-	if mbrSize <= 0 {
-		mbrSize = 512
-	}
-
-	total := mbr.Mbr_tamano
-
-	if total <= 0 {
-		if st, err := os.Stat(mp.DiskPath); err == nil {
-			total = st.Size()
-		} else {
-			return fmt.Errorf("rep disk: leyendo MBR: tamaño total inválido y no se pudo stat: %w", err)
-		}
-	}
-
-	rep := DiskReport{
-		Kind:     "disk",
-		DiskPath: mp.DiskPath,
-		Size:     total,
-		MBRSize:  mbrSize,
-	}
-
-	topSegs := []DiskSegment{
-		makeSeg("MBR", "MBR", 0, mbrSize, total),
-	}
-
-	type pseg struct {
-		kind  string
-		label string
-		start int64
-		size  int64
-	}
-	var prim []pseg
-	var ext *pseg
-
-	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		p := mbr.Mbr_partitions[i]
-		if p.Part_s <= 0 || p.Part_start < 0 {
-			continue
-		}
-		name := trimName(p.Part_name[:])
-		if name == "" {
-			name = fmt.Sprintf("part%d", i+1)
-		}
-		switch p.Part_type {
-		case 'p', 'P':
-			prim = append(prim, pseg{"P", name, p.Part_start, p.Part_s})
-		case 'e', 'E':
-			tmp := pseg{"E", "extendida", p.Part_start, p.Part_s}
-			ext = &tmp
-
-		default:
-
-			prim = append(prim, pseg{"P", name, p.Part_start, p.Part_s})
-		}
-	}
-
-	for _, x := range prim {
-		topSegs = append(topSegs, makeSeg(x.kind, x.label, x.start, x.size, total))
-	}
-	if ext != nil {
-		topSegs = append(topSegs, makeSeg(ext.kind, ext.label, ext.start, ext.size, total))
-	}
-
-	topSegs = fillTopLevelFree(rep.Size, topSegs)
-
-	sort.Slice(topSegs, func(i, j int) bool { return topSegs[i].Start < topSegs[j].Start })
-	rep.Segments = normalizePercents(topSegs, total, 2)
-
-	if ext != nil {
-		extView, err := buildExtendedView(mp.DiskPath, ext.start, ext.size, total)
-		if err != nil {
-			fmt.Printf("rep disk: WARN extendida: %v\n", err)
-		} else {
-			rep.Extended = &extView
-		}
+		return err
 	}
 
-	finalPath, format := resolveOutPathDisk(outPath, id)
+	finalPath, format := resolveOutPathDisk(outPath, strings.TrimSpace(id))
 	if err := os.MkdirAll(filepath.Dir(finalPath), 0o755); err != nil {
 		return fmt.Errorf("rep disk: creando carpeta destino: %w", err)
 	}
@@ -198,68 +112,36 @@ func fillTopLevelFree(total int64, segs []DiskSegment) []DiskSegment {
 	return out
 }
 
-func buildExtendedView(diskPath string, extStart, extSize, total int64) (ExtendedView, error) {
-	if extSize <= 0 {
+func buildExtendedView(table *diskio.MBRTable, ext diskio.PartInfo, total int64) (ExtendedView, error) {
+	if ext.Size <= 0 {
 		return ExtendedView{}, fmt.Errorf("extendida con tamaño inválido")
 	}
 
-	var ev ExtendedView
-	ev.Start = extStart
-	ev.Size = extSize
-
-	var ebrZero structs.EBR
-	ebrSize := int64(binary.Size(ebrZero))
-	if ebrSize <= 0 {
-		ebrSize = 512
-	}
-
-	const maxEBRs = 128
-	off := extStart
+	ev := ExtendedView{Start: ext.Start, Size: ext.Size}
+	ebrSize := int64(binary.Size(structs.EBR{}))
 
-	type inner struct {
-		seg DiskSegment
+	var occupied []DiskSegment
+	for _, off := range table.EBRs() {
+		occupied = append(occupied, makeSeg("EBR", "ebr", off, ebrSize, total))
 	}
-	var occupied []inner
-
-	for i := 0; i < maxEBRs; i++ {
-		ebr, err := readEBRAt(diskPath, off)
-		if err != nil {
-			if i == 0 {
-
-				break
-			}
-
-			break
-		}
-
-		ebrSeg := makeSeg("EBR", "ebr", off, ebrSize, total)
-		occupied = append(occupied, inner{ebrSeg})
-
-		if ebr.Part_s > 0 {
-			logicalStart := extStart + ebr.Part_start
-			label := trimName(ebr.Part_name[:])
-			if label == "" {
-				label = "logica"
-			}
-			lSeg := makeSeg("L", label, logicalStart, ebr.Part_s, total)
-			occupied = append(occupied, inner{lSeg})
+	for _, p := range table.Partitions() {
+		if p.Type != 'L' {
+			continue
 		}
-
-		if ebr.Part_next <= 0 {
-			break
+		label := p.Name
+		if label == "" {
+			label = "logica"
 		}
-		off = extStart + ebr.Part_next
+		occupied = append(occupied, makeSeg("L", label, p.Start, p.Size, total))
 	}
-
 	if len(occupied) == 0 {
 		return ev, nil
 	}
 
-	sort.Slice(occupied, func(i, j int) bool { return occupied[i].seg.Start < occupied[j].seg.Start })
+	sort.Slice(occupied, func(i, j int) bool { return occupied[i].Start < occupied[j].Start })
 
-	cur := extStart
-	for _, it := range occupied {
-		s := it.seg
+	cur := ext.Start
+	for _, s := range occupied {
 		if s.Start > cur {
 			ev.Segments = append(ev.Segments, makeSeg("FREE", "libre", cur, s.Start-cur, total))
 		}
@@ -269,9 +151,8 @@ func buildExtendedView(diskPath string, extStart, extSize, total int64) (Extende
 		}
 	}
 
-	extEnd := extStart + extSize
-	if extEnd > cur {
-		ev.Segments = append(ev.Segments, makeSeg("FREE", "libre", cur, extEnd-cur, total))
+	if ext.End() > cur {
+		ev.Segments = append(ev.Segments, makeSeg("FREE", "libre", cur, ext.End()-cur, total))
 	}
 
 	ev.Segments = normalizePercents(ev.Segments, total, 2)
@@ -332,8 +213,8 @@ h2{margin:8px 0}
 .small{color:#555;font-size:12px}
 </style>`)
 	b.WriteString("<h2>DISK</h2>")
-	fmt.Fprintf(&b, `<p class="small"><b>Disco:</b> %s &nbsp; <b>Tamaño:</b> %d bytes &nbsp; <b>MBR:</b> %d bytes</p>`,
-		escape(rep.DiskPath), rep.Size, rep.MBRSize)
+	fmt.Fprintf(&b, `<p class="small"><b>Disco:</b> %s &nbsp; <b>Tamaño:</b> %d bytes &nbsp; <b>MBR:</b> %d bytes &nbsp; <b>En el host:</b> %d bytes &nbsp; <b>Cifrado:</b> %v</p>`,
+		escape(rep.DiskPath), rep.Size, rep.MBRSize, rep.AllocatedBytes, rep.Encrypted)
 
 	b.WriteString(`<div class="wrap"><div class="bar">`)
 	for _, s := range rep.Segments {
@@ -387,22 +268,21 @@ func BuildDisk(reg *mount.Registry, id string) (DiskReport, error) {
 	if !ok {
 		return DiskReport{}, fmt.Errorf("rep disk: id %q no está montado", id)
 	}
+	return BuildDiskAt(mp.DiskPath)
+}
 
-	mbr, err := diskio.ReadMBR(mp.DiskPath)
+// BuildDiskAt arma el reporte DISK de un disco por su ruta, sin montarlo.
+func BuildDiskAt(diskPath string) (DiskReport, error) {
+	table, err := diskio.OpenTable(diskPath)
 	if err != nil {
-		return DiskReport{}, fmt.Errorf("rep disk: leyendo MBR: %w", err)
+		return DiskReport{}, fmt.Errorf("rep disk: leyendo tabla de particiones: %w", err)
 	}
 
-	var m structs.MBR
-	mbrSize := int64(binary.Size(m))
-	if mbrSize <= 0 {
-		mbrSize = 512
-	}
-
-	total := mbr.Mbr_tamano
+	mbrSize := int64(binary.Size(structs.MBR{}))
+	total := table.DiskSize()
 
 	if total <= 0 {
-		if st, err2 := os.Stat(mp.DiskPath); err2 == nil {
+		if st, err2 := os.Stat(diskPath); err2 == nil {
 			total = st.Size()
 		} else {
 			return DiskReport{}, fmt.Errorf("rep disk: leyendo MBR: tamaño total inválido y no se pudo stat: %w", err2)
@@ -411,50 +291,42 @@ func BuildDisk(reg *mount.Registry, id string) (DiskReport, error) {
 
 	rep := DiskReport{
 		Kind:     "disk",
-		DiskPath: mp.DiskPath,
+		Table:    table.Kind(),
+		DiskPath: diskPath,
 		Size:     total,
 		MBRSize:  mbrSize,
 	}
-
-	topSegs := []DiskSegment{
-		makeSeg("MBR", "MBR", 0, mbrSize, total),
+	rep.Encrypted = diskio.IsEncrypted(diskPath)
+	if n, err := diskio.Allocated(diskPath); err == nil {
+		rep.AllocatedBytes = n
 	}
 
-	type pseg struct {
-		kind  string
-		label string
-		start int64
-		size  int64
+	// Metadatos de la tabla: el MBR y, en GPT, encabezados y entradas.
+	var topSegs []DiskSegment
+	for _, r := range table.Reserved() {
+		kind := "MBR"
+		if strings.HasPrefix(r.Label, "GPT") {
+			kind = "GPT"
+		}
+		topSegs = append(topSegs, makeSeg(kind, r.Label, r.Start, r.Size, total))
 	}
-	var prim []pseg
-	var ext *pseg
 
-	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		p := mbr.Mbr_partitions[i]
-		if p.Part_s <= 0 || p.Part_start < 0 {
+	var ext *diskio.PartInfo
+	for _, p := range table.Partitions() {
+		if !p.Usable() || p.Type == 'L' {
 			continue
 		}
-		name := trimName(p.Part_name[:])
+		name := p.Name
 		if name == "" {
-			name = fmt.Sprintf("part%d", i+1)
+			name = fmt.Sprintf("part%d", p.Index+1)
 		}
-		switch p.Part_type {
-		case 'p', 'P':
-			prim = append(prim, pseg{"P", name, p.Part_start, p.Part_s})
-		case 'e', 'E':
-			tmp := pseg{"E", "extendida", p.Part_start, p.Part_s}
+		if p.Type == 'E' {
+			tmp := p
 			ext = &tmp
-		default:
-
-			prim = append(prim, pseg{"P", name, p.Part_start, p.Part_s})
+			topSegs = append(topSegs, makeSeg("E", "extendida", p.Start, p.Size, total))
+			continue
 		}
-	}
-
-	for _, x := range prim {
-		topSegs = append(topSegs, makeSeg(x.kind, x.label, x.start, x.size, total))
-	}
-	if ext != nil {
-		topSegs = append(topSegs, makeSeg(ext.kind, ext.label, ext.start, ext.size, total))
+		topSegs = append(topSegs, makeSeg("P", name, p.Start, p.Size, total))
 	}
 
 	topSegs = fillTopLevelFree(rep.Size, topSegs)
@@ -462,8 +334,11 @@ func BuildDisk(reg *mount.Registry, id string) (DiskReport, error) {
 	sort.Slice(topSegs, func(i, j int) bool { return topSegs[i].Start < topSegs[j].Start })
 	rep.Segments = normalizePercents(topSegs, total, 2)
 
-	if ext != nil {
-		extView, err := buildExtendedView(mp.DiskPath, ext.start, ext.size, total)
+	if err := table.Check(); err != nil {
+		fmt.Printf("rep disk: WARN %v\n", err)
+	}
+	if mt, ok := table.(*diskio.MBRTable); ok && ext != nil {
+		extView, err := buildExtendedView(mt, *ext, total)
 		if err != nil {
 			fmt.Printf("rep disk: WARN extendida: %v\n", err)
 		} else {
@@ -3,6 +3,7 @@ package reports
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,6 +27,7 @@ func BuildFile(reg *mount.Registry, id, ruta string) ([]byte, error) {
 		return nil, fmt.Errorf("rep file: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return nil, fmt.Errorf("rep file: leyendo super bloque: %w", err)
@@ -50,8 +52,9 @@ func BuildFile(reg *mount.Registry, id, ruta string) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("rep file: leyendo contenido: %w", err)
 	}
-	if int64(len(data)) > int64(ino.ISize) && ino.ISize >= 0 {
-		data = data[:ino.ISize]
+	data, err = ext2.DecodeFileData(ino, data)
+	if err != nil {
+		return nil, fmt.Errorf("rep file: %w", err)
 	}
 
 	t := decodeType(ino.IType)
@@ -62,6 +65,18 @@ func BuildFile(reg *mount.Registry, id, ruta string) ([]byte, error) {
 	return data, nil
 }
 
+// OpenFileRange abre el archivo del reporte como io.ReadSeeker para servir
+// peticiones con Range: cada lectura toca sólo los bloques del rango. Lee
+// como root, igual que BuildFile.
+func OpenFileRange(reg *mount.Registry, id, ruta string) (io.ReadSeeker, error) {
+	ruta = normalizePath(ruta)
+	f, err := ext2.OpenFile(reg, strings.TrimSpace(id), ruta, 1, 1, true)
+	if err != nil {
+		return nil, fmt.Errorf("rep file: %w", err)
+	}
+	return f.Reader()
+}
+
 func GenerateFile(reg *mount.Registry, id, ruta, outPath string) error {
 	data, err := BuildFile(reg, id, ruta)
 	if err != nil {
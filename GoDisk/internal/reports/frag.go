@@ -0,0 +1,241 @@
+package reports
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// ===================== Modelo JSON =====================
+
+type FragItem struct {
+	Path       string  `json:"path"`
+	Inode      int32   `json:"inode"`
+	Type       string  `json:"type"` // "file" | "dir"
+	Size       int32   `json:"size"`
+	Blocks     []int32 `json:"blocks"`
+	Extents    int     `json:"extents"`
+	Fragmented bool    `json:"fragmented"`
+}
+
+type FragRun struct {
+	Start  int32 `json:"start"`
+	Length int32 `json:"length"`
+}
+
+type FragReport struct {
+	Kind            string     `json:"kind"` // "frag"
+	DiskPath        string     `json:"diskPath"`
+	ID              string     `json:"id"`
+	Fit             string     `json:"fit"`
+	Files           int        `json:"files"`
+	FragmentedFiles int        `json:"fragmentedFiles"`
+	FragmentedPct   float64    `json:"fragmentedPercent"`
+	FreeBlocks      int32      `json:"freeBlocks"`
+	FreeRuns        int        `json:"freeRuns"`
+	LargestFreeRun  int32      `json:"largestFreeRun"`
+	Runs            []FragRun  `json:"runs"`
+	Items           []FragItem `json:"items"`
+}
+
+// ===================== Build / Generate =====================
+
+func BuildFrag(reg *mount.Registry, id string) (FragReport, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return FragReport{}, fmt.Errorf("rep frag: -id requerido")
+	}
+	mp, ok := reg.GetByID(id)
+	if !ok {
+		return FragReport{}, fmt.Errorf("rep frag: id %q no está montado", id)
+	}
+
+	defer rlockPartition(&mp)()
+	sb, err := readSuperBlock(mp)
+	if err != nil {
+		return FragReport{}, fmt.Errorf("rep frag: leyendo super bloque: %w", err)
+	}
+	if sb.SInodesCount <= 0 || sb.SBlocksCount <= 0 {
+		return FragReport{}, fmt.Errorf("rep frag: partición no formateada; ejecute mkfs")
+	}
+	_, bmBl, err := loadBitmapsForReport(mp, sb)
+	if err != nil {
+		return FragReport{}, fmt.Errorf("rep frag: leyendo bitmaps: %w", err)
+	}
+
+	rep := FragReport{
+		Kind:       "frag",
+		DiskPath:   mp.DiskPath,
+		ID:         id,
+		Fit:        mapFit(mp.Fit),
+		FreeBlocks: sb.SFreeBlocksCount,
+		Items:      []FragItem{},
+	}
+
+	// Recorrido desde la raíz; ext2 usa los 15 IBlock como directos.
+	seen := map[int32]bool{}
+	var walk func(idx int32, path string)
+	walk = func(idx int32, path string) {
+		if idx < 0 || idx >= sb.SInodesCount || seen[idx] {
+			return
+		}
+		seen[idx] = true
+		ino, err := readInodeAt(mp, sb, idx)
+		if err != nil {
+			return
+		}
+		isDir := ino.IType == 0
+
+		blocks := make([]int32, 0, len(ino.IBlock))
+		for _, b := range ino.IBlock {
+			if b >= 0 && b < sb.SBlocksCount {
+				blocks = append(blocks, b)
+			}
+		}
+		ext := countExtents(blocks)
+		it := FragItem{
+			Path:       path,
+			Inode:      idx,
+			Type:       "file",
+			Size:       ino.ISize,
+			Blocks:     blocks,
+			Extents:    ext,
+			Fragmented: ext > 1,
+		}
+		if isDir {
+			it.Type = "dir"
+		}
+		rep.Items = append(rep.Items, it)
+		rep.Files++
+		if it.Fragmented {
+			rep.FragmentedFiles++
+		}
+
+		if !isDir {
+			return
+		}
+		for _, b := range blocks {
+			raw, err := readBlockBytes(mp, sb, b)
+			if err != nil {
+				continue
+			}
+			for _, e := range parseDirEntries(raw) {
+				if e.Name == "." || e.Name == ".." || e.Inode < 0 {
+					continue
+				}
+				walk(e.Inode, strings.TrimSuffix(path, "/")+"/"+e.Name)
+			}
+		}
+	}
+	walk(0, "/")
+
+	runs := ext2.FreeRuns(bmBl)
+	rep.FreeRuns = len(runs)
+	rep.Runs = make([]FragRun, 0, len(runs))
+	for _, r := range runs {
+		rep.Runs = append(rep.Runs, FragRun{Start: r.Start, Length: r.Length})
+		if r.Length > rep.LargestFreeRun {
+			rep.LargestFreeRun = r.Length
+		}
+	}
+	if rep.Files > 0 {
+		rep.FragmentedPct = float64(rep.FragmentedFiles) * 100 / float64(rep.Files)
+	}
+	return rep, nil
+}
+
+// countExtents cuenta las rachas de bloques consecutivos en el orden lógico.
+func countExtents(blocks []int32) int {
+	if len(blocks) == 0 {
+		return 0
+	}
+	n := 1
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i] != blocks[i-1]+1 {
+			n++
+		}
+	}
+	return n
+}
+
+func GenerateFrag(reg *mount.Registry, id, outPath string) error {
+	rep, err := BuildFrag(reg, id)
+	if err != nil {
+		return err
+	}
+	finalPath, format := resolveOutPathFrag(outPath, id)
+	if err := os.MkdirAll(filepath.Dir(finalPath), 0o755); err != nil {
+		return fmt.Errorf("rep frag: creando carpeta destino: %w", err)
+	}
+	switch format {
+	case "json":
+		return writeJSON(finalPath, rep)
+	case "html":
+		return writeHTML_FRAG(finalPath, rep)
+	default:
+		return fmt.Errorf("rep frag: formato no soportado")
+	}
+}
+
+func resolveOutPathFrag(out, id string) (string, string) {
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return fmt.Sprintf("frag_%s.json", id), "json"
+	}
+	ext := strings.ToLower(filepath.Ext(out))
+	if ext == ".json" {
+		return out, "json"
+	}
+	if ext == ".html" || ext == ".htm" {
+		return out, "html"
+	}
+	st, err := os.Stat(out)
+	if err == nil && st.IsDir() {
+		return filepath.Join(out, fmt.Sprintf("frag_%s.json", id)), "json"
+	}
+	if ext == "" {
+		return out + ".json", "json"
+	}
+	return out, "json"
+}
+
+func writeHTML_FRAG(path string, rep FragReport) error {
+	var b strings.Builder
+	b.WriteString("<!doctype html><meta charset=\"utf-8\"><title>FRAG Report</title>")
+	b.WriteString(`<style>
+body{font-family:system-ui,Segoe UI,Roboto,Arial;margin:16px}
+h2{margin:8px 0}
+table{border-collapse:collapse;width:100%}
+th,td{border:1px solid #ccc;padding:.4rem .6rem}
+th{background:#f7f7f7}
+.small{color:#555;font-size:12px}
+.frag{background:#fff3f0}
+</style>`)
+	fmt.Fprintf(&b, "<h2>FRAGMENTACIÓN</h2>")
+	fmt.Fprintf(&b, `<p class="small"><b>Disco:</b> %s &nbsp; <b>Partición:</b> %s &nbsp; <b>Ajuste:</b> %s</p>`,
+		escape(rep.DiskPath), escape(rep.ID), escape(rep.Fit))
+	fmt.Fprintf(&b, `<p class="small"><b>Fragmentados:</b> %d de %d (%.1f%%) &nbsp; <b>Bloques libres:</b> %d &nbsp; <b>Rachas libres:</b> %d &nbsp; <b>Racha mayor:</b> %d</p>`,
+		rep.FragmentedFiles, rep.Files, rep.FragmentedPct, rep.FreeBlocks, rep.FreeRuns, rep.LargestFreeRun)
+
+	b.WriteString("<table><thead><tr>")
+	b.WriteString("<th>Ruta</th><th>Tipo</th><th>Inodo</th><th>Tamaño</th><th>Extents</th><th>Bloques</th>")
+	b.WriteString("</tr></thead><tbody>")
+	for _, it := range rep.Items {
+		cls := ""
+		if it.Fragmented {
+			cls = ` class="frag"`
+		}
+		blocks := make([]string, len(it.Blocks))
+		for i, x := range it.Blocks {
+			blocks[i] = fmt.Sprint(x)
+		}
+		fmt.Fprintf(&b, "<tr%s><td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%s</td></tr>",
+			cls, escape(it.Path), escape(it.Type), it.Inode, it.Size, it.Extents, escape(strings.Join(blocks, ", ")))
+	}
+	b.WriteString("</tbody></table>")
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
@@ -1,9 +1,7 @@
 package reports
 
 import (
-	"encoding/binary"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -25,6 +23,8 @@ type InodeReport struct {
 	Type       string  `json:"type"`
 	RawType    byte    `json:"rawType"`
 	Size       int32   `json:"size"`
+	Compressed bool    `json:"compressed"`
+	StoredSize int32   `json:"storedSize"` // bytes en bloques
 	UID        int32   `json:"uid"`
 	GID        int32   `json:"gid"`
 	Perm       string  `json:"perm"`
@@ -47,6 +47,7 @@ func GenerateInode(reg *mount.Registry, id, ruta, outPath string) error {
 		return fmt.Errorf("rep inode: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return fmt.Errorf("rep inode: leyendo super bloque: %w", err)
@@ -94,56 +95,40 @@ func GenerateInode(reg *mount.Registry, id, ruta, outPath string) error {
 }
 
 func readSuperBlock(mp *mount.MountedPartition) (ext2.SuperBloque, error) {
-	var sb ext2.SuperBloque
-	f, err := os.Open(mp.DiskPath)
-	if err != nil {
-		return sb, err
-	}
-	defer f.Close()
-	if _, err := f.Seek(mp.Start, io.SeekStart); err != nil {
-		return sb, err
-	}
-	if err := binary.Read(f, binary.LittleEndian, &sb); err != nil {
-		return sb, err
-	}
-	return sb, nil
+	return ext2.ReadSuperBlock(mp)
 }
 
 func readInodeAt(mp *mount.MountedPartition, sb ext2.SuperBloque, idx int32) (ext2.Inodo, error) {
-	var ino ext2.Inodo
-	off := mp.Start + sb.SInodeStart + int64(idx)*int64(sb.SInodeS)
-	f, err := os.Open(mp.DiskPath)
-	if err != nil {
-		return ino, err
-	}
-	defer f.Close()
-	if _, err := f.Seek(off, io.SeekStart); err != nil {
-		return ino, err
-	}
-	if err := binary.Read(f, binary.LittleEndian, &ino); err != nil {
-		return ino, err
-	}
-	return ino, nil
+	return ext2.ReadInode(mp, sb, idx)
+}
+
+// rlockPartition toma el candado compartido de la partición mientras se
+// construye un reporte (mp queda con la entrada vigente); las mutaciones
+// (ext2/ext3) toman el exclusivo.
+func rlockPartition(mp **mount.MountedPartition) func() {
+	return mount.Lock(mp, false)
 }
 
 // ---------- Transformación a JSON ----------
 
 func buildInodeReport(mp *mount.MountedPartition, id string, idx int32, ino ext2.Inodo) InodeReport {
 	rep := InodeReport{
-		Kind:     "inode",
-		DiskPath: mp.DiskPath,
-		ID:       id,
-		Index:    idx,
-		Type:     decodeType(ino.IType),
-		RawType:  ino.IType,
-		Size:     ino.ISize,
-		UID:      ino.IUid,
-		GID:      ino.IGid,
-		Perm:     decodePerm(ino.IPerm[:]),
-		PermRaw:  bytesCopy(ino.IPerm[:]),
-		ATime:    toRFC3339(ino.IAtime),
-		MTime:    toRFC3339(ino.IMtime),
-		CTime:    toRFC3339(ino.ICtime),
+		Kind:       "inode",
+		DiskPath:   mp.DiskPath,
+		ID:         id,
+		Index:      idx,
+		Type:       decodeType(ino.IType),
+		RawType:    ino.IType,
+		Size:       ino.ISize,
+		UID:        ino.IUid,
+		Compressed: ino.Compressed(),
+		StoredSize: int32(ino.StoredSize()),
+		GID:        ino.IGid,
+		Perm:       decodePerm(ino.IPerm[:]),
+		PermRaw:    bytesCopy(ino.IPerm[:]),
+		ATime:      toRFC3339(ino.IAtime),
+		MTime:      toRFC3339(ino.IMtime),
+		CTime:      toRFC3339(ino.ICtime),
 	}
 
 	blocks := make([]int32, 0, len(ino.IBlock))
@@ -240,8 +225,8 @@ func writeHTML_INODE(path string, rep InodeReport) error {
 	b.WriteString("<!doctype html><meta charset=\"utf-8\"><title>INODE Report</title>")
 	b.WriteString(`<style>body{font-family:system-ui,Segoe UI,Roboto,Arial}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:.4rem .6rem}th{background:#f5f5f5}</style>`)
 	b.WriteString("<h2>INODE</h2>")
-	fmt.Fprintf(&b, "<p><b>Disco:</b> %s<br><b>ID:</b> %s<br><b>Inodo #</b> %d<br><b>Tipo:</b> %s (raw=%d)<br><b>Tamaño:</b> %d<br><b>UID/GID:</b> %d/%d<br><b>Perm:</b> %s</p>",
-		escape(rep.DiskPath), escape(rep.ID), rep.Index, escape(rep.Type), rep.RawType, rep.Size, rep.UID, rep.GID, escape(rep.Perm))
+	fmt.Fprintf(&b, "<p><b>Disco:</b> %s<br><b>ID:</b> %s<br><b>Inodo #</b> %d<br><b>Tipo:</b> %s (raw=%d)<br><b>Tamaño:</b> %d (guardado %d, comprimido=%v)<br><b>UID/GID:</b> %d/%d<br><b>Perm:</b> %s</p>",
+		escape(rep.DiskPath), escape(rep.ID), rep.Index, escape(rep.Type), rep.RawType, rep.Size, rep.StoredSize, rep.Compressed, rep.UID, rep.GID, escape(rep.Perm))
 
 	b.WriteString("<table><thead><tr><th>Bloques (IBlock)</th></tr></thead><tbody><tr><td>")
 	if len(rep.Blocks) == 0 {
@@ -274,6 +259,7 @@ func BuildInode(reg *mount.Registry, id string, ruta string) (InodeReport, error
 		return InodeReport{}, fmt.Errorf("rep inode: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return InodeReport{}, fmt.Errorf("rep inode: leyendo super bloque: %w", err)
@@ -37,6 +37,7 @@ func BuildInodes(reg *mount.Registry, id string, maxItems int) (InodesReport, er
 		return InodesReport{}, fmt.Errorf("rep inodes: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return InodesReport{}, fmt.Errorf("rep inodes: leyendo super bloque: %w", err)
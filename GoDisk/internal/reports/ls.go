@@ -60,6 +60,7 @@ func BuildLS(reg *mount.Registry, id, pathLS string) (LSReport, error) {
 	}
 	dirPath = normalizePath(dirPath)
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return LSReport{}, fmt.Errorf("rep ls: leyendo super bloque: %w", err)
@@ -1,10 +1,8 @@
 package reports
 
 import (
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,6 +17,7 @@ import (
 
 type MBRReport struct {
 	Kind      string          `json:"kind"`
+	Table     string          `json:"table"` // "mbr" | "gpt"
 	DiskPath  string          `json:"diskPath"`
 	Created   string          `json:"created"`
 	Size      int64           `json:"sizeBytes"`
@@ -26,6 +25,19 @@ type MBRReport struct {
 	Fit       string          `json:"fit"`
 	RawFit    byte            `json:"rawFit"`
 	Parts     []MBRPartReport `json:"partitions"`
+	GPT       *GPTView        `json:"gpt,omitempty"`
+}
+
+type GPTView struct {
+	DiskGUID      string `json:"diskGuid"`
+	FirstUsable   int64  `json:"firstUsable"`
+	LastUsable    int64  `json:"lastUsable"`
+	PrimaryOffset int64  `json:"primaryOffset"`
+	BackupOffset  int64  `json:"backupOffset"`
+	Entries       int    `json:"entries"`
+	EntrySize     int    `json:"entrySize"`
+	HeaderCRC     string `json:"headerCrc"`
+	EntriesCRC    string `json:"entriesCrc"`
 }
 
 type MBRPartReport struct {
@@ -44,73 +56,6 @@ type MBRPartReport struct {
 	Usable      bool   `json:"usable"`
 }
 
-func readEBRAt(diskPath string, offset int64) (structs.EBR, error) {
-	f, err := os.Open(diskPath)
-	if err != nil {
-		return structs.EBR{}, err
-	}
-	defer f.Close()
-
-	if _, err := f.Seek(offset, io.SeekStart); err != nil {
-		return structs.EBR{}, err
-	}
-
-	var ebr structs.EBR
-	if err := binary.Read(f, binary.LittleEndian, &ebr); err != nil {
-		return structs.EBR{}, err
-	}
-	return ebr, nil
-}
-
-func appendLogicalFromEBR(rep *MBRReport, diskPath string, extStart int64) error {
-	if extStart <= 0 {
-		return fmt.Errorf("extendida con start inválido: %d", extStart)
-	}
-
-	const maxEBRs = 128
-	off := extStart
-
-	for i := 0; i < maxEBRs; i++ {
-		ebr, err := readEBRAt(diskPath, off)
-		if err != nil {
-			if i == 0 {
-
-				return err
-			}
-
-			return nil
-		}
-
-		if ebr.Part_s <= 0 {
-			return nil
-		}
-
-		logicalStart := extStart + ebr.Part_start
-
-		rep.Parts = append(rep.Parts, MBRPartReport{
-			Index:     len(rep.Parts),
-			Status:    string([]byte{ebr.Part_status}),
-			Type:      "l",
-			Fit:       mapFit(ebr.Part_fit),
-			RawStatus: ebr.Part_status,
-			RawType:   'l',
-			RawFit:    ebr.Part_fit,
-			Start:     logicalStart,
-			Size:      ebr.Part_s,
-			Name:      trimName(ebr.Part_name[:]),
-			Usable:    (ebr.Part_s > 0 && logicalStart >= 0),
-		})
-
-		if ebr.Part_next <= 0 {
-			return nil
-		}
-
-		off = extStart + ebr.Part_next
-	}
-
-	return fmt.Errorf("cadena EBR demasiado larga o con ciclo")
-}
-
 func BuildMBR(reg *mount.Registry, id string) (MBRReport, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
@@ -120,111 +65,72 @@ func BuildMBR(reg *mount.Registry, id string) (MBRReport, error) {
 	if !ok {
 		return MBRReport{}, fmt.Errorf("rep mbr: id %q no está montado", id)
 	}
+	return BuildMBRAt(mp.DiskPath)
+}
 
-	mbr, err := diskio.ReadMBR(mp.DiskPath)
+// BuildMBRAt arma el reporte MBR de un disco por su ruta, sin montarlo.
+func BuildMBRAt(diskPath string) (MBRReport, error) {
+	table, err := diskio.OpenTable(diskPath)
 	if err != nil {
-		return MBRReport{}, fmt.Errorf("rep mbr: leyendo MBR: %w", err)
+		return MBRReport{}, fmt.Errorf("rep mbr: leyendo tabla de particiones: %w", err)
 	}
 
 	rep := MBRReport{
 		Kind:      "mbr",
-		DiskPath:  mp.DiskPath,
-		Created:   time.Unix(mbr.Mbr_fecha_creacion, 0).Format(time.RFC3339),
-		Size:      mbr.Mbr_tamano,
-		Signature: mbr.Mbr_dsk_signature,
-		Fit:       mapFit(mbr.Dsk_fit),
-		RawFit:    mbr.Dsk_fit,
-	}
-
-	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		p := mbr.Mbr_partitions[i]
-		pr := MBRPartReport{
-			Index:       i,
-			Status:      string([]byte{p.Part_status}),
-			Type:        string([]byte{p.Part_type}),
-			Fit:         mapFit(p.Part_fit),
-			RawStatus:   p.Part_status,
-			RawType:     p.Part_type,
-			RawFit:      p.Part_fit,
-			Start:       p.Part_start,
-			Size:        p.Part_s,
-			Name:        trimName(p.Part_name[:]),
-			Usable:      (p.Part_s > 0 && p.Part_start >= 0),
-			ID:          trimName(p.Part_id[:]),
-			Correlative: int(p.Part_correlative),
+		Table:     table.Kind(),
+		DiskPath:  diskPath,
+		Created:   time.Unix(table.Created(), 0).Format(time.RFC3339),
+		Size:      table.DiskSize(),
+		Signature: table.Signature(),
+		Fit:       mapFit(table.Fit()),
+		RawFit:    table.Fit(),
+	}
+	if gt, ok := table.(*diskio.GPTTable); ok {
+		h := gt.Header()
+		rep.GPT = &GPTView{
+			DiskGUID:      formatGUID(h.Gpt_disk_guid),
+			FirstUsable:   h.Gpt_first_usable,
+			LastUsable:    h.Gpt_last_usable,
+			PrimaryOffset: structs.GPTHeaderOffset,
+			BackupOffset:  h.Gpt_alt_offset,
+			Entries:       int(h.Gpt_num_entries),
+			EntrySize:     int(h.Gpt_entry_size),
+			HeaderCRC:     fmt.Sprintf("%08x", h.Gpt_header_crc),
+			EntriesCRC:    fmt.Sprintf("%08x", h.Gpt_entries_crc),
 		}
-		rep.Parts = append(rep.Parts, pr)
+	}
 
-		if p.Part_type == 'e' || p.Part_type == 'E' {
-			if err := appendLogicalFromEBR(&rep, mp.DiskPath, p.Part_start); err != nil {
-				fmt.Println("WARN: leyendo EBR:", err)
-			}
-		}
+	for _, p := range table.Partitions() {
+		rep.Parts = append(rep.Parts, MBRPartReport{
+			Index:       p.Index,
+			Status:      string([]byte{p.Status}),
+			Type:        string([]byte{p.Type}),
+			Fit:         mapFit(p.Fit),
+			RawStatus:   p.Status,
+			RawType:     p.Type,
+			RawFit:      p.Fit,
+			Start:       p.Start,
+			Size:        p.Size,
+			Name:        p.Name,
+			Usable:      p.Usable(),
+			ID:          p.ID,
+			Correlative: int(p.Correlative),
+		})
+	}
+	if err := table.Check(); err != nil {
+		fmt.Println("WARN: rep mbr:", err)
 	}
 
 	return rep, nil
 }
 
 func GenerateMBR(reg *mount.Registry, id, outPath string) error {
-	id = strings.TrimSpace(id)
-	if id == "" {
-		return fmt.Errorf("rep mbr: -id requerido")
-	}
-	mp, ok := reg.GetByID(id)
-	if !ok {
-		return fmt.Errorf("rep mbr: id %q no está montado", id)
-	}
-
-	mbr, err := diskio.ReadMBR(mp.DiskPath)
+	rep, err := BuildMBR(reg, id)
 	if err != nil {
-		return fmt.Errorf("rep mbr: leyendo MBR: %w", err)
-	}
-
-	rep := MBRReport{
-		Kind:      "mbr",
-		DiskPath:  mp.DiskPath,
-		Created:   time.Unix(mbr.Mbr_fecha_creacion, 0).Format(time.RFC3339),
-		Size:      mbr.Mbr_tamano,
-		Signature: mbr.Mbr_dsk_signature,
-		Fit:       mapFit(mbr.Dsk_fit),
-		RawFit:    mbr.Dsk_fit,
-	}
-
-	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		p := mbr.Mbr_partitions[i]
-		pr := MBRPartReport{
-			Index:     i,
-			Status:    string([]byte{p.Part_status}),
-			Type:      string([]byte{p.Part_type}),
-			Fit:       mapFit(p.Part_fit),
-			RawStatus: p.Part_status,
-			RawType:   p.Part_type,
-			RawFit:    p.Part_fit,
-			Start:     p.Part_start,
-			Size:      p.Part_s,
-			Name:      trimName(p.Part_name[:]),
-			Usable:    (p.Part_s > 0 && p.Part_start >= 0),
-		}
-
-		if _, has := any(p).(structs.Partition); has {
-
-			pr.ID = trimName(p.Part_id[:])
-			pr.Correlative = int(p.Part_correlative)
-		}
-		pr.ID = trimName(p.Part_id[:])
-		pr.Correlative = int(p.Part_correlative)
-
-		rep.Parts = append(rep.Parts, pr)
-
-		if p.Part_type == 'e' || p.Part_type == 'E' {
-			if err := appendLogicalFromEBR(&rep, mp.DiskPath, p.Part_start); err != nil {
-				fmt.Println("WARN: leyendo EBR:", err)
-			}
-		}
-
+		return err
 	}
 
-	finalPath, format := resolveOutPath(outPath, id)
+	finalPath, format := resolveOutPath(outPath, strings.TrimSpace(id))
 
 	if err := os.MkdirAll(filepath.Dir(finalPath), 0o755); err != nil {
 		return fmt.Errorf("rep mbr: creando carpeta destino: %w", err)
@@ -239,6 +145,12 @@ func GenerateMBR(reg *mount.Registry, id, outPath string) error {
 	}
 }
 
+// formatGUID muestra un GUID en la forma habitual (orden de bytes mixto).
+func formatGUID(g [16]byte) string {
+	return fmt.Sprintf("%02X%02X%02X%02X-%02X%02X-%02X%02X-%02X%02X-%02X%02X%02X%02X%02X%02X",
+		g[3], g[2], g[1], g[0], g[5], g[4], g[7], g[6], g[8], g[9], g[10], g[11], g[12], g[13], g[14], g[15])
+}
+
 // ---------------- Helpers de salida ----------------
 
 func writeJSON(path string, v any) error {
@@ -258,8 +170,12 @@ func writeHTML_MBR(path string, rep MBRReport) error {
 	b.WriteString("<!doctype html><meta charset=\"utf-8\"><title>MBR Report</title>")
 	b.WriteString(`<style>body{font-family:system-ui,Segoe UI,Roboto,Arial}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:.4rem .6rem}th{background:#f5f5f5}</style>`)
 	b.WriteString("<h2>MBR</h2>")
-	fmt.Fprintf(&b, "<p><b>Disk:</b> %s<br><b>Created:</b> %s<br><b>Size:</b> %d bytes<br><b>Signature:</b> %d<br><b>Fit:</b> %s (0x%02X)</p>",
-		escape(rep.DiskPath), escape(rep.Created), rep.Size, rep.Signature, escape(rep.Fit), rep.RawFit)
+	fmt.Fprintf(&b, "<p><b>Disk:</b> %s<br><b>Table:</b> %s<br><b>Created:</b> %s<br><b>Size:</b> %d bytes<br><b>Signature:</b> %d<br><b>Fit:</b> %s (0x%02X)</p>",
+		escape(rep.DiskPath), escape(strings.ToUpper(rep.Table)), escape(rep.Created), rep.Size, rep.Signature, escape(rep.Fit), rep.RawFit)
+	if g := rep.GPT; g != nil {
+		fmt.Fprintf(&b, "<p><b>GPT GUID:</b> %s<br><b>Usable:</b> %d – %d<br><b>Primary/Backup:</b> %d / %d<br><b>CRC header/entries:</b> %s / %s</p>",
+			escape(g.DiskGUID), g.FirstUsable, g.LastUsable, g.PrimaryOffset, g.BackupOffset, escape(g.HeaderCRC), escape(g.EntriesCRC))
+	}
 
 	b.WriteString("<table><thead><tr>")
 	b.WriteString("<th>#</th><th>Status</th><th>Type</th><th>Fit</th><th>Start</th><th>Size</th><th>Name</th><th>Usable</th>")
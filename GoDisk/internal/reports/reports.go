@@ -23,6 +23,7 @@ const (
 	ReportSB      Name = "sb"
 	ReportFile    Name = "file"
 	ReportLS      Name = "ls"
+	ReportFrag    Name = "frag"
 )
 
 type Params struct {
@@ -70,10 +71,14 @@ func Generate(reg *mount.Registry, p Params) error {
 		return GenerateBmBlock(reg, p.ID, p.Path)
 	case ReportTree:
 		return GenerateTree(reg, p.ID, p.Path)
+	case ReportSB:
+		return GenerateSB(reg, p.ID, p.Path)
 	case ReportFile:
 		return GenerateFile(reg, p.ID, p.Ruta, p.Path)
 	case ReportLS:
 		return GenerateLS(reg, p.ID, p.Ruta, p.Path)
+	case ReportFrag:
+		return GenerateFrag(reg, p.ID, p.Path)
 	default:
 		return errors.New("rep: reporte no soportado: " + string(p.Name))
 	}
@@ -17,16 +17,25 @@ type SBReport struct {
 	DiskPath string `json:"diskPath"`
 	ID       string `json:"id"`
 
-	BlockSize       int32 `json:"blockSize"`
-	InodesCount     int32 `json:"inodesCount"`
-	BlocksCount     int32 `json:"blocksCount"`
-	FreeInodes      int32 `json:"freeInodes"`
-	FreeBlocks      int32 `json:"freeBlocks"`
-	InodeSize       int32 `json:"inodeSize"`
-	BmInodeStart    int64 `json:"bmInodeStart"`
-	BmBlockStart    int64 `json:"bmBlockStart"`
-	InodeTableStart int64 `json:"inodeTableStart"`
-	BlockStart      int64 `json:"blockStart"`
+	BlockSize       int32  `json:"blockSize"`
+	InodesCount     int32  `json:"inodesCount"`
+	BlocksCount     int32  `json:"blocksCount"`
+	FreeInodes      int32  `json:"freeInodes"`
+	FreeBlocks      int32  `json:"freeBlocks"`
+	InodeSize       int32  `json:"inodeSize"`
+	BmInodeStart    int64  `json:"bmInodeStart"`
+	BmBlockStart    int64  `json:"bmBlockStart"`
+	InodeTableStart int64  `json:"inodeTableStart"`
+	BlockStart      int64  `json:"blockStart"`
+	BitmapFormat    string `json:"bitmapFormat"`
+	Checksums       bool   `json:"checksums"`
+	Refcounts       bool   `json:"refcounts"`
+	JournalOwner    bool   `json:"journalOwner"`
+
+	// Copia del superbloque que se usó ("primario" o "respaldo N") y estado
+	// de cada una.
+	Source string           `json:"source"`
+	Copies []ext2.SuperCopy `json:"copies"`
 
 	BitmapUsedInodes int `json:"bitmapUsedInodes"`
 	BitmapFreeInodes int `json:"bitmapFreeInodes"`
@@ -34,6 +43,20 @@ type SBReport struct {
 	BitmapFreeBlocks int `json:"bitmapFreeBlocks"`
 }
 
+func bitmapFormatName(sb ext2.SuperBloque) string {
+	if sb.HasFeature(ext2.FeaturePackedBitmaps) {
+		return "packed"
+	}
+	return "byte"
+}
+
+func sbSourceName(copy int) string {
+	if copy == 0 {
+		return "primario"
+	}
+	return fmt.Sprintf("respaldo %d", copy)
+}
+
 // ===================== Build / Generate =====================
 
 func BuildSB(reg *mount.Registry, id string) (SBReport, error) {
@@ -46,10 +69,14 @@ func BuildSB(reg *mount.Registry, id string) (SBReport, error) {
 		return SBReport{}, fmt.Errorf("rep sb: id %q no está montado", id)
 	}
 
-	sb, err := readSuperBlock(mp)
+	defer rlockPartition(&mp)()
+	sb, used, err := ext2.LoadSuperBlock(mp)
 	if err != nil {
 		return SBReport{}, fmt.Errorf("rep sb: leyendo super bloque: %w", err)
 	}
+	if !ext2.SuperBlockOK(sb) {
+		return SBReport{}, fmt.Errorf("rep sb: superbloque inválido y sin respaldo utilizable")
+	}
 
 	bmIn, bmBl, err := loadBitmapsForReport(mp, sb)
 	if err != nil {
@@ -89,11 +116,18 @@ func BuildSB(reg *mount.Registry, id string) (SBReport, error) {
 		BmBlockStart:    sb.SBmBlockStart,
 		InodeTableStart: sb.SInodeStart,
 		BlockStart:      sb.SBlockStart,
+		BitmapFormat:    bitmapFormatName(sb),
+		Checksums:       sb.HasFeature(ext2.FeatureChecksums),
+		Refcounts:       sb.HasFeature(ext2.FeatureRefcounts),
+		JournalOwner:    sb.HasFeature(ext2.FeatureJournalOwner),
 
 		BitmapUsedInodes: usedIn,
 		BitmapFreeInodes: freeIn,
 		BitmapUsedBlocks: usedBl,
 		BitmapFreeBlocks: freeBl,
+
+		Source: sbSourceName(used),
+		Copies: ext2.SuperBlockCopies(mp, sb),
 	}
 
 	rep.FreeInodes = int32(freeIn)
@@ -169,6 +203,18 @@ func writeHTML_SB(path string, rep SBReport) error {
 	row("BmBlockStart", rep.BmBlockStart)
 	row("InodeTableStart", rep.InodeTableStart)
 	row("BlockStart", rep.BlockStart)
+	row("BitmapFormat", rep.BitmapFormat)
+	row("Checksums", rep.Checksums)
+	row("Refcounts", rep.Refcounts)
+	row("JournalOwner", rep.JournalOwner)
+	row("Copia usada", rep.Source)
+	for _, c := range rep.Copies {
+		state := "ok"
+		if !c.OK {
+			state = "dañada"
+		}
+		row(fmt.Sprintf("%s @%d", sbSourceName(c.Index), c.Offset), state)
+	}
 
 	b.WriteString("</tbody></table>")
 	return os.WriteFile(path, []byte(b.String()), 0o644)
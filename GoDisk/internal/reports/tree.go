@@ -96,6 +96,7 @@ func BuildTree(reg *mount.Registry, id string) (TreeReport, error) {
 		return TreeReport{}, fmt.Errorf("rep tree: id %q no está montado", id)
 	}
 
+	defer rlockPartition(&mp)()
 	sb, err := readSuperBlock(mp)
 	if err != nil {
 		return TreeReport{}, fmt.Errorf("rep tree: leyendo super bloque: %w", err)
@@ -0,0 +1,474 @@
+package rescue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/structs"
+)
+
+// Rescue reconstruye la tabla de particiones de un disco cuyo MBR se dañó.
+// Recorre la imagen buscando superbloques EXT2/EXT3 (SMagic 0xEF53 con un
+// layout que coincida con el de mkfs) y EBRs coherentes (Part_start justo
+// después del propio EBR); con eso arma una tabla propuesta que sólo se
+// escribe con Apply.
+
+// Found es algo reconocido durante el escaneo.
+type Found struct {
+	Kind   string `json:"kind"` // "ext2" | "ext3" | "ebr" | "gpt"
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+	Name   string `json:"name,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Part es una partición de la tabla propuesta.
+type Part struct {
+	Type  byte   `json:"type"`
+	Name  string `json:"name"`
+	Start int64  `json:"start"`
+	Size  int64  `json:"size"`
+	FS    string `json:"fs,omitempty"`
+}
+
+type Proposal struct {
+	DiskPath string   `json:"diskPath"`
+	DiskSize int64    `json:"diskSize"`
+	Table    string   `json:"table"`    // "mbr" | "gpt"
+	TableOK  bool     `json:"tableOk"`  // la tabla actual se pudo leer
+	TableErr string   `json:"tableErr"` // por qué no
+	Found    []Found  `json:"found"`
+	Parts    []Part   `json:"partitions"`
+	Warnings []string `json:"warnings"`
+
+	mbr structs.MBR
+	gpt *diskio.GPT
+}
+
+const scanChunk = 1 << 20
+
+var (
+	sbSize   = int64(binary.Size(ext2.SuperBloque{}))
+	ebrSize  = int64(binary.Size(structs.EBR{}))
+	mbrSize  = int64(binary.Size(structs.MBR{}))
+	magicOff = int64(40) // offset de SMagic dentro del superbloque
+	// MagicEXT2 y MagicEXT2v2
+	magicsLE  = [][]byte{{0x53, 0xEF, 0x00, 0x00}, {0x54, 0xEF, 0x00, 0x00}}
+	errNoDisk = errors.New("rescue: disco no existe")
+)
+
+// Scan analiza el disco y arma la propuesta sin escribir nada.
+func Scan(path string) (Proposal, error) {
+	p := Proposal{DiskPath: path}
+	if err := diskio.Flush(path); err != nil {
+		return p, err
+	}
+	// En discos cifrados el tamaño no incluye el trailer y la lectura pasa
+	// por diskio para descifrar.
+	size, err := diskio.DataSize(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, errNoDisk
+		}
+		return p, err
+	}
+	p.DiskSize = size
+
+	if t, err := diskio.OpenTable(path); err != nil {
+		p.TableErr = err.Error()
+	} else if err := t.Check(); err != nil {
+		p.TableErr = err.Error()
+	} else {
+		p.TableOK = t.DiskSize() == p.DiskSize
+		if !p.TableOK {
+			p.TableErr = fmt.Sprintf("Mbr_tamano=%d no coincide con el archivo (%d)", t.DiskSize(), p.DiskSize)
+		}
+	}
+
+	// GPT: si alguna copia sigue bien basta con rehacer el MBR protector.
+	if g, err := diskio.ReadGPT(path, p.DiskSize); err == nil {
+		p.Table = "gpt"
+		p.gpt = &g
+		p.Found = append(p.Found, Found{Kind: "gpt", Offset: g.Header.Gpt_my_offset, Size: structs.GPTSectorSize})
+		for _, e := range g.Entries {
+			if e.Part_status == '1' && e.Part_s > 0 {
+				p.Parts = append(p.Parts, Part{Type: 'P', Name: trimName(e.Part_name[:]), Start: e.Part_start, Size: e.Part_s})
+			}
+		}
+		p.mbr = baseMBR(path, p.DiskSize)
+		return p, nil
+	}
+
+	p.Table = "mbr"
+	sbs, ebrs, err := scanImage(path, p.DiskSize)
+	if err != nil {
+		return p, err
+	}
+	p.mbr = baseMBR(path, p.DiskSize)
+	p.build(sbs, ebrs)
+	return p, nil
+}
+
+type sbHit struct {
+	off int64
+	sb  ext2.SuperBloque
+}
+
+type ebrHit struct {
+	off int64
+	e   structs.EBR
+}
+
+// scanImage recorre el archivo por trozos (con solape para no perder
+// estructuras partidas entre dos lecturas).
+func scanImage(path string, size int64) ([]sbHit, map[int64]structs.EBR, error) {
+	var sbs []sbHit
+	ebrs := map[int64]structs.EBR{}
+
+	for base := int64(0); base < size; base += scanChunk {
+		n := int(min(scanChunk+sbSize, size-base))
+		win, err := diskio.ReadBytes(path, base, n)
+		if err != nil {
+			return nil, nil, err
+		}
+		limit := min(int64(n), scanChunk)
+
+		for _, magic := range magicsLE {
+			for i := int64(0); i < limit; {
+				k := bytes.Index(win[i:], magic)
+				if k < 0 {
+					break
+				}
+				at := i + int64(k)
+				i = at + 1
+				start := base + at - magicOff
+				if at < magicOff || at >= limit || start < mbrSize {
+					continue
+				}
+				sb, err := ext2.DecodeSuperBlock(win[at-magicOff:])
+				if err != nil {
+					continue
+				}
+				if plausibleSB(sb) {
+					sbs = append(sbs, sbHit{start, sb})
+				}
+			}
+		}
+
+		for i := int64(0); i < limit && i+ebrSize <= int64(n); i++ {
+			if win[i] != '1' && win[i] != '0' {
+				continue
+			}
+			var e structs.EBR
+			if binary.Read(bytes.NewReader(win[i:i+ebrSize]), binary.LittleEndian, &e) != nil {
+				continue
+			}
+			if plausibleEBR(base+i, e, size) {
+				ebrs[base+i] = e
+			}
+		}
+	}
+	return sbs, ebrs, nil
+}
+
+// layoutFor recalcula el layout que mkfs habría usado para size bytes.
+func layoutFor(sb ext2.SuperBloque, size int64) (ext2.SuperBloque, bool) {
+	opts := ext2.OptionsFromSuperBlock(sb)
+	if sb.SFilesystemType == ext3.FileSystemTypeExt3 {
+		_, got, _, _, err := ext3.ComputeLayoutExt3With(size, opts)
+		return got, err == nil
+	}
+	_, got, err := ext2.ComputeLayoutWith(size, opts)
+	return got, err == nil
+}
+
+func plausibleSB(sb ext2.SuperBloque) bool {
+	if !ext2.ValidMagic(sb.SMagic) || sb.SInodesCount < 2 || sb.SBlocksCount != 3*sb.SInodesCount {
+		return false
+	}
+	if sb.Legacy() {
+		// mkfs ya no genera este formato: basta con que el layout sea
+		// coherente consigo mismo.
+		return ext2.SuperBlockOK(sb)
+	}
+	if sb.SFilesystemType != ext2.FileSystemType && sb.SFilesystemType != ext2.FileSystemTypeEXT3 {
+		return false
+	}
+	if sb.SBlockS != ext2.BlockSize || int64(sb.SInodeS) != ext2.InodeSize(sb) {
+		return false
+	}
+	want, ok := layoutFor(sb, minPartSize(sb))
+	return ok && want.SInodesCount == sb.SInodesCount &&
+		want.SBmInodeStart == sb.SBmInodeStart && want.SBmBlockStart == sb.SBmBlockStart &&
+		want.SInodeStart == sb.SInodeStart && want.SBlockStart == sb.SBlockStart
+}
+
+func minPartSize(sb ext2.SuperBloque) int64 {
+	return ext2.FSEnd(sb)
+}
+
+// primaries separa los superbloques primarios de sus copias de respaldo.
+// Si el primario se perdió, la primera copia ubica el inicio de la
+// partición restando su offset.
+func primaries(sbs []sbHit) []sbHit {
+	sort.Slice(sbs, func(i, j int) bool { return sbs[i].off < sbs[j].off })
+	at := map[int64]bool{}
+	for _, h := range sbs {
+		at[h.off] = true
+	}
+	var out []sbHit
+	seen := map[int64]bool{}
+	for _, h := range sbs {
+		if seen[h.off] {
+			continue
+		}
+		offs := ext2.SuperBackupOffsets(h.sb)
+		start := h.off
+		if len(offs) > 1 && at[h.off+offs[1]-offs[0]] && !at[h.off+offs[0]] {
+			start = h.off - offs[0] // h es el respaldo 1
+		}
+		seen[start] = true
+		for _, off := range offs {
+			seen[start+off] = true
+		}
+		if start >= mbrSize {
+			out = append(out, sbHit{start, h.sb})
+		}
+	}
+	return out
+}
+
+// partSizeFor busca el mayor tamaño <= limit con el que mkfs habría
+// calculado el mismo número de inodos.
+func partSizeFor(sb ext2.SuperBloque, limit int64) int64 {
+	lo, hi := minPartSize(sb), limit
+	if hi <= lo {
+		return lo
+	}
+	if got, ok := layoutFor(sb, hi); ok && got.SInodesCount == sb.SInodesCount {
+		return hi
+	}
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		if got, ok := layoutFor(sb, mid); ok && got.SInodesCount == sb.SInodesCount {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+func plausibleEBR(off int64, e structs.EBR, size int64) bool {
+	switch e.Part_status {
+	case '1':
+		fit := e.Part_fit | 0x20
+		if fit != 'b' && fit != 'f' && fit != 'w' {
+			return false
+		}
+		if e.Part_start != off+ebrSize || e.Part_s <= 0 || e.Part_start+e.Part_s > size {
+			return false
+		}
+		if e.Part_next != -1 && (e.Part_next < e.Part_start+e.Part_s || e.Part_next+ebrSize > size) {
+			return false
+		}
+		return printableName(e.Part_name[:])
+	case '0':
+		// Cabeza inactiva que todavía encadena lógicas.
+		return e.Part_start == -1 && e.Part_s == 0 && e.Part_next > off && e.Part_next+ebrSize <= size
+	}
+	return false
+}
+
+func printableName(b []byte) bool {
+	n := trimName(b)
+	if n == "" {
+		return false
+	}
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if i < len(n) && (c < 0x20 || c > 0x7e) {
+			return false
+		}
+		if i >= len(n) && c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func trimName(b []byte) string {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return string(b)
+}
+
+// baseMBR conserva fecha, firma y ajuste del MBR actual si parece de este
+// disco; si no, arma uno nuevo.
+func baseMBR(path string, size int64) structs.MBR {
+	if m, err := diskio.ReadMBR(path); err == nil && m.Mbr_tamano == size {
+		m.Mbr_partitions = structs.NewMBR(size, m.Dsk_fit, 0).Mbr_partitions
+		return m
+	}
+	return structs.NewMBR(size, 'f', rand.New(rand.NewSource(time.Now().UnixNano())).Int63())
+}
+
+// build arma la propuesta MBR: la cadena EBR más larga define la extendida y
+// cada superbloque fuera de ella una primaria.
+func (p *Proposal) build(sbs []sbHit, ebrs map[int64]structs.EBR) {
+	var ext *Part
+	var chainEnd int64
+	if head, chain := longestChain(ebrs); len(chain) > 0 {
+		ext = &Part{Type: 'E', Name: "rescate-ext", Start: head}
+		for _, h := range chain {
+			if h.e.Part_status != '1' {
+				continue
+			}
+			chainEnd = max(chainEnd, h.e.Part_start+h.e.Part_s)
+			name := trimName(h.e.Part_name[:])
+			p.Found = append(p.Found, Found{Kind: "ebr", Offset: h.off, Size: h.e.Part_s, Name: name})
+			p.Parts = append(p.Parts, Part{Type: 'L', Name: name, Start: h.e.Part_start, Size: h.e.Part_s})
+		}
+		ext.Size = max(chainEnd, head+ebrSize) - head
+		if orphans := len(ebrs) - len(chain); orphans > 0 {
+			p.Warnings = append(p.Warnings, fmt.Sprintf("%d EBR(s) sueltos fuera de la cadena principal", orphans))
+		}
+	}
+
+	// Primarias: superbloques fuera de la extendida, sin solaparse.
+	sbs = primaries(sbs)
+	var prim []Part
+	for i, h := range sbs {
+		if ext != nil && h.off >= ext.Start && h.off < ext.Start+ext.Size {
+			continue
+		}
+		if n := len(prim); n > 0 && h.off < prim[n-1].Start+prim[n-1].Size {
+			continue
+		}
+		limit := p.DiskSize
+		for _, nx := range sbs[i+1:] {
+			if nx.off > h.off {
+				limit = nx.off
+				break
+			}
+		}
+		if ext != nil && ext.Start > h.off {
+			limit = min(limit, ext.Start)
+		}
+		fs := "ext2"
+		if h.sb.SFilesystemType == ext3.FileSystemTypeExt3 {
+			fs = "ext3"
+		}
+		size := partSizeFor(h.sb, limit-h.off)
+		p.Found = append(p.Found, Found{Kind: fs, Offset: h.off, Size: size,
+			Detail: fmt.Sprintf("inodos=%d bloques=%d", h.sb.SInodesCount, h.sb.SBlocksCount)})
+		prim = append(prim, Part{Type: 'P', Start: h.off, Size: size, FS: fs})
+	}
+
+	maxPrim := 4
+	if ext != nil {
+		maxPrim = 3
+	}
+	if len(prim) > maxPrim {
+		p.Warnings = append(p.Warnings, fmt.Sprintf("se encontraron %d primarias; sólo caben %d en el MBR", len(prim), maxPrim))
+		prim = prim[:maxPrim]
+	}
+	for i := range prim {
+		prim[i].Name = fmt.Sprintf("rescate%d", i+1)
+	}
+	parts := prim
+	if ext != nil {
+		parts = append(parts, *ext)
+	}
+	p.Parts = append(parts, p.Parts...)
+}
+
+// longestChain devuelve la cabeza y los nodos de la cadena EBR más larga.
+func longestChain(ebrs map[int64]structs.EBR) (int64, []ebrHit) {
+	pointed := map[int64]bool{}
+	for _, e := range ebrs {
+		if e.Part_next > 0 {
+			pointed[e.Part_next] = true
+		}
+	}
+	var bestHead int64 = -1
+	var best []ebrHit
+	for off := range ebrs {
+		if pointed[off] {
+			continue
+		}
+		var chain []ebrHit
+		seen := map[int64]bool{}
+		for cur := off; cur > 0 && !seen[cur]; {
+			e, ok := ebrs[cur]
+			if !ok {
+				break
+			}
+			seen[cur] = true
+			chain = append(chain, ebrHit{cur, e})
+			cur = e.Part_next
+		}
+		if len(chain) > len(best) || (len(chain) == len(best) && off < bestHead) {
+			bestHead, best = off, chain
+		}
+	}
+	return bestHead, best
+}
+
+// Apply escribe la tabla propuesta. En GPT rehace el MBR protector y las
+// dos copias; en MBR escribe primarias y extendida (los EBR encontrados ya
+// están en el disco). La tabla se valida antes de escribir nada y, si la
+// actual se lee bien, sólo se reemplaza con force.
+func Apply(p Proposal, force bool) error {
+	if p.TableOK && !force {
+		return errors.New("la tabla actual se lee bien; usa -force para reemplazarla")
+	}
+
+	m := p.mbr
+	var g *diskio.GPT
+	if p.Table == "gpt" {
+		diskio.ProtectiveMBR(&m)
+		g = p.gpt
+	} else {
+		slot := 0
+		for _, part := range p.Parts {
+			if part.Type == 'L' {
+				continue
+			}
+			s := &m.Mbr_partitions[slot]
+			s.Part_status = '1'
+			s.Part_type = part.Type
+			s.Part_fit = 'W'
+			s.Part_start = part.Start
+			s.Part_s = part.Size
+			copy(s.Part_name[:], part.Name)
+			slot++
+		}
+	}
+	if err := diskio.NewTable(p.DiskPath, m, g).Check(); err != nil {
+		return fmt.Errorf("la tabla propuesta no es válida: %w", err)
+	}
+
+	if err := diskio.Invalidate(p.DiskPath); err != nil {
+		return err
+	}
+	defer diskio.Invalidate(p.DiskPath)
+
+	if err := diskio.WriteMBR(p.DiskPath, m); err != nil {
+		return err
+	}
+	if g != nil {
+		return diskio.WriteGPT(p.DiskPath, *g)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package structs
+
+// Tabla de particiones estilo GPT (simplificada). El disco conserva el MBR
+// en el offset 0 como MBR "protector" (una sola partición tipo 'G' que cubre
+// todo el disco) para que las herramientas que sólo entienden MBR no vean
+// espacio libre. El encabezado va en el offset GPTHeaderOffset, seguido del
+// arreglo de entradas; al final del disco hay una copia de respaldo con el
+// orden invertido (entradas y luego encabezado en el último sector).
+
+const (
+	GPTSignature    = "EFI PART"
+	GPTRevision     = 0x00010000
+	GPTHeaderOffset = 512 // sector 1
+	GPTSectorSize   = 512
+	GPTEntryCount   = 128
+	GPTProtective   = 'G' // Part_type de la entrada protectora en el MBR
+)
+
+// GPTHeader es el encabezado de la tabla. Los offsets se guardan en bytes
+// (no en LBA) igual que Part_start en el MBR.
+type GPTHeader struct {
+	// Gpt_signature: "EFI PART"
+	Gpt_signature [8]byte
+	Gpt_revision  uint32
+	// Gpt_header_size: bytes del encabezado serializado
+	Gpt_header_size uint32
+	// Gpt_header_crc: CRC32 del encabezado calculado con este campo en 0
+	Gpt_header_crc uint32
+	// Gpt_my_offset / Gpt_alt_offset: dónde está esta copia y la otra
+	Gpt_my_offset  int64
+	Gpt_alt_offset int64
+	// Rango utilizable para particiones [first, last)
+	Gpt_first_usable int64
+	Gpt_last_usable  int64
+	Gpt_disk_guid    [16]byte
+	// Gpt_entries_offset: inicio del arreglo de entradas de esta copia
+	Gpt_entries_offset int64
+	Gpt_num_entries    uint32
+	Gpt_entry_size     uint32
+	// Gpt_entries_crc: CRC32 del arreglo completo de entradas
+	Gpt_entries_crc uint32
+}
+
+// GPTEntry es una entrada del arreglo. Conserva los campos de Partition que
+// usan mount y los reportes (estado, ajuste, id y correlativo).
+type GPTEntry struct {
+	Gpt_type_guid    [16]byte
+	Gpt_unique_guid  [16]byte
+	Part_status      byte
+	Part_fit         byte
+	Part_start       int64
+	Part_s           int64
+	Part_name        [16]byte
+	Part_correlative int32
+	Part_id          [4]byte
+}
@@ -9,13 +9,25 @@ import (
 // i_path: ruta donde se realizó (máx 32 chars)
 // i_content: contenido (si aplica, máx 64 chars)
 // i_date: fecha/hora en float64 (por ej. epoch seconds con fracción)
+// i_uid, i_gid: usuario y grupo que la hizo (0 = desconocido)
+// i_status: resultado (JournalStatus*)
 type Information struct {
 	I_operation [10]byte
 	I_path      [32]byte
 	I_content   [64]byte
 	I_date      float64
+	I_uid       int32
+	I_gid       int32
+	I_status    byte
 }
 
+// Valores de I_status.
+const (
+	JournalStatusUnknown byte = 0 // entradas con formato anterior
+	JournalStatusOK      byte = 1
+	JournalStatusFailed  byte = 2
+)
+
 // Journal es una entrada de la bitácora.
 // j_count: contador (ordinal) de la entrada
 // j_content: información asociada
@@ -24,6 +36,43 @@ type Journal struct {
 	JContent Information
 }
 
+// InformationV1 y JournalV1 son el formato de entrada anterior, sin
+// usuario ni resultado; lo usan los EXT3 formateados antes de
+// FeatureJournalOwner.
+type InformationV1 struct {
+	I_operation [10]byte
+	I_path      [32]byte
+	I_content   [64]byte
+	I_date      float64
+}
+
+type JournalV1 struct {
+	JCount   int32
+	JContent InformationV1
+}
+
+// Upgrade convierte la entrada al formato actual (dueño y resultado
+// desconocidos).
+func (j JournalV1) Upgrade() Journal {
+	return Journal{JCount: j.JCount, JContent: Information{
+		I_operation: j.JContent.I_operation,
+		I_path:      j.JContent.I_path,
+		I_content:   j.JContent.I_content,
+		I_date:      j.JContent.I_date,
+	}}
+}
+
+// Downgrade convierte la entrada al formato anterior, perdiendo dueño y
+// resultado.
+func (j Journal) Downgrade() JournalV1 {
+	return JournalV1{JCount: j.JCount, JContent: InformationV1{
+		I_operation: j.JContent.I_operation,
+		I_path:      j.JContent.I_path,
+		I_content:   j.JContent.I_content,
+		I_date:      j.JContent.I_date,
+	}}
+}
+
 // ----------------- Helpers opcionales -----------------
 
 // NewInformation construye Information truncando/padding los campos de texto.
@@ -36,6 +85,17 @@ func NewInformation(operation, path, content string, when time.Time) Information
 	return info
 }
 
+// WithActor devuelve info con el usuario y grupo que hicieron la operación
+// y su resultado.
+func (info Information) WithActor(uid, gid int32, ok bool) Information {
+	info.I_uid, info.I_gid = uid, gid
+	info.I_status = JournalStatusOK
+	if !ok {
+		info.I_status = JournalStatusFailed
+	}
+	return info
+}
+
 // NewJournal crea una entrada Journal con contador y contenido dados.
 func NewJournal(count int32, info Information) Journal {
 	return Journal{
@@ -0,0 +1,55 @@
+package structs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestJournalUpgradeDowngrade(t *testing.T) {
+	when := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		info Information
+	}{
+		{"vacía", Information{}},
+		{"mkdir", NewInformation("MKDIR", "/home/user", "", when)},
+		{"edit con contenido", NewInformation("EDIT", "/a.txt", "hola mundo", when)},
+		{"campos al límite", NewInformation("TRUNCATE10", "/una/ruta/de/exactamente/32/b.tx", string(bytes.Repeat([]byte("x"), 64)), when)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v2 := NewJournal(7, c.info.WithActor(3, 2, false))
+
+			// V2 -> V1 pierde dueño y resultado; V1 -> V2 los deja en cero.
+			up := v2.Downgrade().Upgrade()
+			want := NewJournal(7, c.info)
+			if up != want {
+				t.Fatalf("Downgrade().Upgrade() = %+v, se esperaba %+v", up, want)
+			}
+			if up.JContent.I_status != JournalStatusUnknown {
+				t.Fatalf("I_status = %d, se esperaba desconocido", up.JContent.I_status)
+			}
+
+			// V1 -> V2 -> V1 es exacto.
+			v1 := v2.Downgrade()
+			if back := v1.Upgrade().Downgrade(); back != v1 {
+				t.Fatalf("Upgrade().Downgrade() = %+v, se esperaba %+v", back, v1)
+			}
+
+			// El V1 serializado coincide byte a byte con los campos que
+			// comparte con V2.
+			var b1, b2 bytes.Buffer
+			if err := binary.Write(&b1, binary.LittleEndian, v1); err != nil {
+				t.Fatal(err)
+			}
+			if err := binary.Write(&b2, binary.LittleEndian, v2); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(b2.Bytes(), b1.Bytes()) {
+				t.Fatal("V2 no empieza con la codificación de V1")
+			}
+		})
+	}
+}
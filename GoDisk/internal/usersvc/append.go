@@ -0,0 +1,45 @@
+package usersvc
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+func Append(reg *mount.Registry, path string, cont string) error {
+	return appendTo(reg, path, cont, false)
+}
+
+// AppendText es Append con el contenido tomado siempre como texto literal.
+func AppendText(reg *mount.Registry, path string, text string) error {
+	return appendTo(reg, path, text, true)
+}
+
+func appendTo(reg *mount.Registry, path string, cont string, literal bool) error {
+	path = strings.TrimSpace(path)
+	if path == "" || !strings.HasPrefix(path, "/") {
+		return errors.New("append: -path inválido (debe ser absoluto)")
+	}
+
+	s, err := auth.Require()
+	if err != nil {
+		return errors.New("append: requiere sesión (login)")
+	}
+
+	data := []byte(cont)
+	if !literal {
+		if data, err = resolveEditContent(cont); err != nil {
+			return err
+		}
+	}
+
+	f, err := ext2.OpenFile(reg, s.ID, path, s.UID, s.GID, s.IsRoot)
+	if err == nil {
+		err = f.Append(data)
+	}
+	record(reg, s, "APPEND", path, string(data), err)
+	return err
+}
@@ -0,0 +1,22 @@
+package usersvc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/audit"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+)
+
+// Audit consulta el registro de auditoría; sólo root puede leerlo.
+func Audit(f audit.Filter) ([]audit.Record, error) {
+	s, err := auth.Require()
+	if err != nil {
+		return nil, errors.New("audit: requiere sesión (login)")
+	}
+	if !s.IsRoot {
+		return nil, fmt.Errorf("audit: %w: operación permitida solo para root", ext2.ErrPermission)
+	}
+	return audit.Query(f)
+}
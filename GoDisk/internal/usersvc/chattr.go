@@ -0,0 +1,27 @@
+package usersvc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+func Chattr(reg *mount.Registry, path string, compress bool) error {
+	path = strings.TrimSpace(path)
+	if path == "" || !strings.HasPrefix(path, "/") {
+		return errors.New("chattr: -path inválido (debe ser absoluto)")
+	}
+
+	s, err := auth.Require()
+	if err != nil {
+		return errors.New("chattr: requiere sesión (login)")
+	}
+
+	err = ext2.SetCompression(reg, s.ID, path, compress, s.UID, s.GID, s.IsRoot)
+	record(reg, s, "CHATTR", path, fmt.Sprintf("compress=%t", compress), err)
+	return err
+}
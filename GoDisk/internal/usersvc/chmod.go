@@ -7,7 +7,6 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -28,7 +27,9 @@ func Chmod(reg *mount.Registry, path, ugo string, recursive bool) error {
 	}
 	// Solo root puede ejecutar chmod
 	if !s.IsRoot {
-		return errors.New("chmod: operación permitida solo para root")
+		err := fmt.Errorf("chmod: %w: operación permitida solo para root", ext2.ErrPermission)
+		record(reg, s, "CHMOD", path, fmt.Sprintf("ugo=%s recursive=%t", ugo, recursive), err)
+		return err
 	}
 
 	perms, err := ext2.ParseUGO(ugo)
@@ -36,12 +37,7 @@ func Chmod(reg *mount.Registry, path, ugo string, recursive bool) error {
 		return fmt.Errorf("chmod: %w", err)
 	}
 
-	if err := ext2.Chmod(reg, s.ID, path, perms, recursive, s.UID, s.GID, s.IsRoot); err != nil {
-		return err
-	}
-
-	// Journal
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "CHMOD", path, fmt.Sprintf("ugo=%s recursive=%t", ugo, recursive))
-
-	return nil
+	err = ext2.Chmod(reg, s.ID, path, perms, recursive, s.UID, s.GID, s.IsRoot)
+	record(reg, s, "CHMOD", path, fmt.Sprintf("ugo=%s recursive=%t", ugo, recursive), err)
+	return err
 }
@@ -7,7 +7,6 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -26,11 +25,7 @@ func Chown(reg *mount.Registry, path, newUser string, recursive bool) error {
 		return errors.New("chown: requiere sesión (login)")
 	}
 
-	if err := ext2.Chown(reg, s.ID, path, newUser, recursive, s.UID, s.GID, s.IsRoot); err != nil {
-		return err
-	}
-
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "CHOWN", path, fmt.Sprintf("usuario=%s recursive=%t", newUser, recursive))
-
-	return nil
+	err = ext2.Chown(reg, s.ID, path, newUser, recursive, s.UID, s.GID, s.IsRoot)
+	record(reg, s, "CHOWN", path, fmt.Sprintf("usuario=%s recursive=%t", newUser, recursive), err)
+	return err
 }
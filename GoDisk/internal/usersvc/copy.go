@@ -6,7 +6,6 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -26,11 +25,8 @@ func Copy(reg *mount.Registry, path, destino string) error {
 		return errors.New("copy: requiere sesión (login)")
 	}
 
-	if err := ext2.CopyNode(reg, s.ID, path, destino, s.UID, s.GID, s.IsRoot); err != nil {
-		return err
-	}
-
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "COPY", path, "dest="+destino)
-	return nil
+	err = ext2.CopyNode(reg, s.ID, path, destino, s.UID, s.GID, s.IsRoot)
+	record(reg, s, "COPY", path, "dest="+destino, err)
+	return err
 
 }
@@ -7,11 +7,20 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
 func Edit(reg *mount.Registry, path string, cont string) error {
+	return edit(reg, path, cont, false)
+}
+
+// EditText es Edit con el contenido tomado siempre como texto literal,
+// aunque coincida con una ruta del SO (lo usa el script de journal export).
+func EditText(reg *mount.Registry, path string, text string) error {
+	return edit(reg, path, text, true)
+}
+
+func edit(reg *mount.Registry, path string, cont string, literal bool) error {
 	path = strings.TrimSpace(path)
 	if path == "" || !strings.HasPrefix(path, "/") {
 		return errors.New("edit: -path inválido (debe ser absoluto)")
@@ -22,19 +31,17 @@ func Edit(reg *mount.Registry, path string, cont string) error {
 		return errors.New("edit: requiere sesión (login)")
 	}
 
-	data, err := resolveEditContent(cont)
-	if err != nil {
-		return err
+	data := []byte(cont)
+	if !literal {
+		if data, err = resolveEditContent(cont); err != nil {
+			return err
+		}
 	}
 
 	// Editar el archivo (requiere rw o root, validado en ext2.EditFile)
-	if err := ext2.EditFile(reg, s.ID, path, data, s.UID, s.GID, s.IsRoot); err != nil {
-		return err
-	}
-
-	// Journaling
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "EDIT", path, string(data))
-	return nil
+	err = ext2.EditFile(reg, s.ID, path, data, s.UID, s.GID, s.IsRoot)
+	record(reg, s, "EDIT", path, string(data), err)
+	return err
 }
 
 func resolveEditContent(cont string) ([]byte, error) {
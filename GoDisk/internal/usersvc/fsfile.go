@@ -0,0 +1,92 @@
+package usersvc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// OpenRead abre un archivo de la sesión para descargarlo: valida lectura con
+// ext2.CanRead y devuelve un lector por rangos y el tamaño.
+func OpenRead(reg *mount.Registry, p string) (io.ReadSeeker, int64, error) {
+	s, err := auth.Require()
+	if err != nil {
+		return nil, 0, errors.New("fs/file: requiere sesión (login)")
+	}
+	p = path.Clean("/" + strings.TrimSpace(p))
+	ino, err := ext2.StatPath(reg, s.ID, p)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ino.IType != 1 {
+		return nil, 0, fmt.Errorf("fs/file: %s no es un archivo", p)
+	}
+	if !ext2.CanRead(ino, s.UID, s.GID, s.IsRoot) {
+		return nil, 0, fmt.Errorf("fs/file: %s: %w", p, ext2.ErrPermission)
+	}
+	f, err := ext2.OpenFile(reg, s.ID, p, s.UID, s.GID, s.IsRoot)
+	if err != nil {
+		return nil, 0, err
+	}
+	rs, err := f.Reader()
+	if err != nil {
+		return nil, 0, err
+	}
+	return rs, int64(ino.ISize), nil
+}
+
+// WriteFile crea o reemplaza p con data (subida por HTTP). Si el archivo
+// existe exige ext2.CanWrite sobre él; si no, sobre la carpeta destino.
+// Devuelve true si lo creó.
+func WriteFile(reg *mount.Registry, p string, data []byte, recursive bool) (bool, error) {
+	s, err := auth.Require()
+	if err != nil {
+		return false, errors.New("fs/file: requiere sesión (login)")
+	}
+	p = path.Clean("/" + strings.TrimSpace(p))
+	if p == "/" {
+		return false, errors.New("fs/file: ruta apunta a '/' (no es archivo)")
+	}
+
+	// target es el inodo cuyo permiso de escritura se exige: el archivo o,
+	// si es nuevo, su carpeta (nil si la carpeta también se va a crear).
+	var target *ext2.Inodo
+	created := false
+	ino, err := ext2.StatPath(reg, s.ID, p)
+	switch {
+	case err == nil:
+		if ino.IType != 1 {
+			return false, fmt.Errorf("fs/file: %s no es un archivo", p)
+		}
+		target = &ino
+	case errors.Is(err, ext2.ErrNotExist):
+		created = true
+		dir, err := ext2.StatPath(reg, s.ID, path.Dir(p))
+		switch {
+		case err == nil:
+			target = &dir
+		case !errors.Is(err, ext2.ErrNotExist) || !recursive:
+			return false, err
+		}
+	default:
+		return false, err
+	}
+	if target != nil && !ext2.CanWrite(*target, s.UID, s.GID, s.IsRoot) {
+		err := fmt.Errorf("fs/file: %s: %w", p, ext2.ErrPermission)
+		record(reg, s, "MKFILE", p, string(data), err)
+		return false, err
+	}
+
+	err = ext2.CreateOrOverwriteFile(reg, s.ID, p, data, recursive, true, false, s.UID, s.GID)
+	record(reg, s, "MKFILE", p, string(data), err)
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
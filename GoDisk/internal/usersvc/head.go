@@ -0,0 +1,48 @@
+package usersvc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// Head devuelve los primeros n bytes del archivo.
+func Head(reg *mount.Registry, path string, n int) ([]byte, error) {
+	f, err := openSessionFile(reg, "head", path, n)
+	if err != nil {
+		return nil, err
+	}
+	return f.ReadAt(0, n)
+}
+
+// Tail devuelve los últimos n bytes del archivo.
+func Tail(reg *mount.Registry, path string, n int) ([]byte, error) {
+	f, err := openSessionFile(reg, "tail", path, n)
+	if err != nil {
+		return nil, err
+	}
+	size, err := f.Size()
+	if err != nil {
+		return nil, err
+	}
+	return f.ReadAt(max(size-int64(n), 0), n)
+}
+
+func openSessionFile(reg *mount.Registry, op, path string, n int) (*ext2.File, error) {
+	path = strings.TrimSpace(path)
+	if path == "" || !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("%s: -path inválido (debe ser absoluto)", op)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("%s: -n no puede ser negativo", op)
+	}
+	s, err := auth.Require()
+	if err != nil {
+		return nil, errors.New(op + ": requiere sesión (login)")
+	}
+	return ext2.OpenFile(reg, s.ID, path, s.UID, s.GID, s.IsRoot)
+}
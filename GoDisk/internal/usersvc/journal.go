@@ -0,0 +1,34 @@
+package usersvc
+
+import (
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/events"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+// record es el punto común por el que pasan las mutaciones: agrega la
+// entrada al journal (si la partición es EXT3) con el usuario de la sesión y
+// si opErr fue nil, y publica el cambio cuando se aplicó, más un evento
+// "journal" cuando la entrada se escribió.
+func record(reg *mount.Registry, s *auth.Session, op, path, content string, opErr error) {
+	jc, _ := ext3.AppendJournalEntry(reg, s.ID, op, path, content, int32(s.UID), int32(s.GID), opErr == nil)
+
+	// El contenido de MKFILE/EDIT/APPEND son los datos del archivo.
+	detail := content
+	switch op {
+	case "MKFILE", "EDIT", "APPEND":
+		detail = ""
+	}
+
+	ev := events.Event{Kind: events.KindOf(op), ID: s.ID, Op: op, Path: path, Detail: detail, User: s.User}
+	if opErr == nil {
+		events.Publish(ev)
+	} else {
+		ev.Error = opErr.Error()
+	}
+	if jc > 0 {
+		ev.Kind, ev.JCount = events.KindJournal, jc
+		events.Publish(ev)
+	}
+}
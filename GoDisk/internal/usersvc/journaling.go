@@ -2,6 +2,9 @@ package usersvc
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
@@ -10,6 +13,34 @@ import (
 )
 
 func JournalingJSON(reg *mount.Registry, id string) (string, error) {
+	id, err := journalingID(id)
+	if err != nil {
+		return "", err
+	}
+	return ext3.ListJournalJSON(reg, id)
+}
+
+// JournalingExport escribe en dest (archivo del SO) el journal de id como
+// script de comandos y devuelve cuántas entradas tenía.
+func JournalingExport(reg *mount.Registry, id, dest string) (int, error) {
+	id, err := journalingID(id)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := ext3.ListJournal(reg, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return 0, fmt.Errorf("journaling: creando carpeta de %s: %w", dest, err)
+	}
+	if err := os.WriteFile(dest, []byte(ext3.ExportScript(id, rows)), 0o644); err != nil {
+		return 0, fmt.Errorf("journaling: escribiendo %s: %w", dest, err)
+	}
+	return len(rows), nil
+}
+
+func journalingID(id string) (string, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
 
@@ -19,5 +50,5 @@ func JournalingJSON(reg *mount.Registry, id string) (string, error) {
 			return "", errors.New("journaling: especifica -id o inicia sesión")
 		}
 	}
-	return ext3.ListJournalJSON(reg, id)
+	return id, nil
 }
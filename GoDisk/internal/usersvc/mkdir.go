@@ -2,11 +2,11 @@ package usersvc
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3" // <-- añadir
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -22,14 +22,12 @@ func Mkdir(reg *mount.Registry, path string, p bool) error {
 	}
 
 	if !s.IsRoot {
-		return errors.New("mkdir: operación permitida solo para root")
-	}
-
-	if err := ext2.MakeDir(reg, s.ID, path, p, s.UID, s.GID); err != nil {
+		err := fmt.Errorf("mkdir: %w: operación permitida solo para root", ext2.ErrPermission)
+		record(reg, s, "MKDIR", path, "", err)
 		return err
 	}
 
-	_ = ext3.TryAppendJournal(reg, s.ID, "MKDIR", path, "")
-
-	return nil
+	err = ext2.MakeDir(reg, s.ID, path, p, s.UID, s.GID)
+	record(reg, s, "MKDIR", path, "", err)
+	return err
 }
@@ -8,11 +8,10 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
-func Mkfile(reg *mount.Registry, path string, recursive bool, size int, cont string, force bool) error {
+func Mkfile(reg *mount.Registry, path string, recursive bool, size int, cont string, force, compress bool) error {
 	path = strings.TrimSpace(path)
 	if path == "" || !strings.HasPrefix(path, "/") {
 		return errors.New("mkfile: -path inválido (debe ser absoluto)")
@@ -77,11 +76,14 @@ func Mkfile(reg *mount.Registry, path string, recursive bool, size int, cont str
 		}
 	}
 
-	if err := ext2.CreateOrOverwriteFile(reg, s.ID, path, data, recursive, force, s.UID, s.GID); err != nil {
+	err = ext2.CreateOrOverwriteFile(reg, s.ID, path, data, recursive, force, compress, s.UID, s.GID)
+	record(reg, s, "MKFILE", path, string(data), err)
+	if err != nil {
 		return err
 	}
-
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "MKFILE", path, string(data))
+	if compress {
+		record(reg, s, "CHATTR", path, "compress=true", nil)
+	}
 
 	return nil
 }
@@ -6,7 +6,6 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -25,11 +24,7 @@ func Move(reg *mount.Registry, src, dst string) error {
 		return errors.New("move: requiere sesión (login)")
 	}
 
-	if err := ext2.MoveNode(reg, s.ID, src, dst, s.UID, s.GID, s.IsRoot); err != nil {
-		return err
-	}
-
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "MOVE", src, "dest="+dst)
-
-	return nil
+	err = ext2.MoveNode(reg, s.ID, src, dst, s.UID, s.GID, s.IsRoot)
+	record(reg, s, "MOVE", src, "dest="+dst, err)
+	return err
 }
@@ -6,7 +6,6 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -21,11 +20,7 @@ func Remove(reg *mount.Registry, path string) error {
 		return errors.New("remove: requiere sesión (login)")
 	}
 
-	if err := ext2.Remove(reg, s.ID, path, s.UID, s.GID); err != nil {
-		return err
-	}
-
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "REMOVE", path, "")
-
-	return nil
+	err = ext2.Remove(reg, s.ID, path, s.UID, s.GID)
+	record(reg, s, "REMOVE", path, "", err)
+	return err
 }
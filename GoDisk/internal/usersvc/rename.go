@@ -6,7 +6,6 @@ import (
 
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
-	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
 )
 
@@ -25,10 +24,8 @@ func Rename(reg *mount.Registry, path, newName string) error {
 		return errors.New("rename: requiere sesión (login)")
 	}
 
-	if err := ext2.RenameNode(reg, s.ID, path, newName, s.UID, s.GID, s.IsRoot); err != nil {
-		return err
-	}
-	_ = ext3.AppendJournalIfExt3(reg, s.ID, "RENAME", path, "name="+newName)
-	return nil
+	err = ext2.RenameNode(reg, s.ID, path, newName, s.UID, s.GID, s.IsRoot)
+	record(reg, s, "RENAME", path, "name="+newName, err)
+	return err
 
 }
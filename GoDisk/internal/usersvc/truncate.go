@@ -0,0 +1,33 @@
+package usersvc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
+)
+
+func Truncate(reg *mount.Registry, path string, size int64) error {
+	path = strings.TrimSpace(path)
+	if path == "" || !strings.HasPrefix(path, "/") {
+		return errors.New("truncate: -path inválido (debe ser absoluto)")
+	}
+	if size < 0 {
+		return errors.New("truncate: -size no puede ser negativo")
+	}
+
+	s, err := auth.Require()
+	if err != nil {
+		return errors.New("truncate: requiere sesión (login)")
+	}
+
+	f, err := ext2.OpenFile(reg, s.ID, path, s.UID, s.GID, s.IsRoot)
+	if err == nil {
+		err = f.Truncate(size)
+	}
+	record(reg, s, "TRUNCATE", path, fmt.Sprintf("size=%d", size), err)
+	return err
+}
@@ -3,21 +3,33 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/apidoc"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/audit"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/auth"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/catalog"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/commands"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/diskio"
+	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/events"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext2"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/ext3"
 	"github.com/AGODOYV37/MIA_2S2025_P2_202113539/internal/mount"
@@ -29,10 +41,9 @@ import (
 // ---------------------- Infra de aplicación ----------------------
 
 type App struct {
-	reg       *mount.Registry
-	svc       *mount.Service
-	formatter *ext2.Formatter
-	mu        sync.Mutex // serializa ejecuciones (estado compartido)
+	reg *mount.Registry
+	svc *mount.Service
+	mu  sync.Mutex // serializa ejecuciones (estado compartido)
 }
 
 type mountDTO struct {
@@ -46,9 +57,8 @@ type mountDTO struct {
 func NewApp() *App {
 	reg := mount.NewRegistry()
 	svc := mount.NewService(reg)
-	formatter := ext2.NewFormatter(reg)
 	_ = reg.RehydrateFromCatalog()
-	return &App{reg: reg, svc: svc, formatter: formatter}
+	return &App{reg: reg, svc: svc}
 
 }
 
@@ -77,7 +87,7 @@ func (a *App) handleListMounts(w http.ResponseWriter, r *http.Request) {
 
 func (a *App) handleFSLS(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "solo GET", http.StatusMethodNotAllowed)
+		writeJSONError(w, http.StatusMethodNotAllowed, "solo GET")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -108,12 +118,10 @@ func (a *App) handleFSLS(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		msg := strings.ToLower(err.Error())
 		if strings.Contains(msg, "permiso") || strings.Contains(msg, "sin permiso") {
-			w.WriteHeader(http.StatusForbidden)
-			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			writeJSONError(w, http.StatusForbidden, err.Error())
 			return
 		}
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -129,7 +137,7 @@ type fsFindResp struct {
 
 func (a *App) handleFSFind(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "solo GET", http.StatusMethodNotAllowed)
+		writeJSONError(w, http.StatusMethodNotAllowed, "solo GET")
 		return
 	}
 	id := strings.TrimSpace(r.URL.Query().Get("id"))
@@ -145,18 +153,18 @@ func (a *App) handleFSFind(w http.ResponseWriter, r *http.Request) {
 	// ===== Permisos de sesión =====
 	sess, err := auth.Require()
 	if err != nil {
-		http.Error(w, "requiere login", http.StatusUnauthorized)
+		writeJSONError(w, http.StatusUnauthorized, "requiere login")
 		return
 	}
 	// Solo puede explorar el ID con el que inició sesión
 	if !strings.EqualFold(sess.ID, id) {
-		http.Error(w, "acceso denegado: ID no coincide con la sesión", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "acceso denegado: ID no coincide con la sesión")
 		return
 	}
 
 	items, err := ext2.Find(a.reg, id, ruta, "*", sess.UID, sess.GID, sess.IsRoot)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -244,6 +252,789 @@ func (a *App) handleFSFind(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// maxUpload limita el cuerpo de PUT/POST /api/fs/file.
+const maxUpload = 1 << 20
+
+type fsWriteRes struct {
+	Ruta string `json:"ruta"`
+	Size int    `json:"size"`
+}
+
+// handleFSFile descarga (GET/HEAD) o sube (PUT/POST, cuerpo crudo o
+// multipart) un archivo de la partición de la sesión.
+func (a *App) handleFSFile(w http.ResponseWriter, r *http.Request) {
+	sess, err := auth.Require()
+	if err != nil {
+		writeJSONErrorCode(w, http.StatusUnauthorized, "unauthorized", "requiere login")
+		return
+	}
+	id := strings.TrimSpace(r.URL.Query().Get("id"))
+	ruta := strings.TrimSpace(r.URL.Query().Get("ruta"))
+	if id == "" || ruta == "" {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "fs/file: se requieren ?id= y ?ruta=")
+		return
+	}
+	if id != strings.TrimSpace(sess.ID) {
+		writeJSONErrorCode(w, http.StatusForbidden, "forbidden", "fs/file: id no coincide con la sesión activa")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		rs, _, err := usersvc.OpenRead(a.reg, ruta)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(ruta)))
+		w.Header().Set("Cache-Control", "no-store")
+		// ServeContent pone Content-Length y atiende Range.
+		http.ServeContent(w, r, filepath.Base(ruta), time.Time{}, rs)
+
+	case http.MethodPut, http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+		data, name, err := readUpload(r)
+		if err != nil {
+			writeAPIError(w, fmt.Errorf("fs/file: %w", err))
+			return
+		}
+		if strings.HasSuffix(ruta, "/") && name != "" {
+			ruta += filepath.Base(name)
+		}
+		a.mu.Lock()
+		created, err := usersvc.WriteFile(a.reg, ruta, data, r.URL.Query().Get("r") == "true")
+		if err == nil {
+			err = flushDirty()
+		}
+		a.mu.Unlock()
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		code := http.StatusOK
+		if created {
+			code = http.StatusCreated
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(fsWriteRes{Ruta: ruta, Size: len(data)})
+
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		writeJSONErrorCode(w, http.StatusMethodNotAllowed, "method_not_allowed", "fs/file: solo GET, HEAD, PUT o POST")
+	}
+}
+
+// flushDirty escribe las páginas sucias del page cache; los handlers que
+// mutan la llaman (con a.mu tomado) antes de responder, como ProcessLine al
+// terminar cada comando.
+func flushDirty() error {
+	if err := diskio.FlushAll(); err != nil {
+		return fmt.Errorf("%w: %w", errFlush, err)
+	}
+	return nil
+}
+
+var errFlush = errors.New("flush")
+
+// readUpload lee el contenido subido: el primer archivo de un
+// multipart/form-data (campo "file" o cualquier parte con nombre de
+// archivo) o el cuerpo crudo. name es el nombre del archivo si vino.
+func readUpload(r *http.Request) ([]byte, string, error) {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "multipart/form-data" {
+		data, err := io.ReadAll(r.Body)
+		return data, "", err
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, "", errors.New("multipart sin archivo (campo \"file\")")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if part.FormName() == "file" || part.FileName() != "" {
+			data, err := io.ReadAll(part)
+			return data, part.FileName(), err
+		}
+	}
+}
+
+// apiErrorCode elige el código HTTP y el código de error JSON para un error
+// del FS, de la tabla de particiones o del montaje.
+func apiErrorCode(err error) (int, string) {
+	var tooBig *http.MaxBytesError
+	switch {
+	case errors.Is(err, ext2.ErrPermission):
+		return http.StatusForbidden, "permission_denied"
+	case errors.Is(err, ext2.ErrNotExist), errors.Is(err, os.ErrNotExist),
+		errors.Is(err, diskio.ErrPartNotFound), mount.IsPartitionNotFound(err), mount.IsIDNotFound(err):
+		return http.StatusNotFound, "not_found"
+	case errors.Is(err, ext2.ErrExist), errors.Is(err, os.ErrExist), mount.IsAlreadyMounted(err):
+		return http.StatusConflict, "already_exists"
+	case errors.Is(err, diskio.ErrNoSpace), mount.IsLetterExhausted(err):
+		return http.StatusConflict, "no_space"
+	case errors.Is(err, diskio.ErrLocked):
+		return http.StatusLocked, "locked"
+	case errors.Is(err, diskio.ErrBadPassphrase):
+		return http.StatusForbidden, "bad_passphrase"
+	case errors.As(err, &tooBig):
+		return http.StatusRequestEntityTooLarge, "too_large"
+	case errors.Is(err, errFlush):
+		return http.StatusInternalServerError, "internal"
+	}
+	return http.StatusBadRequest, "invalid"
+}
+
+func writeAPIError(w http.ResponseWriter, err error) {
+	status, code := apiErrorCode(err)
+	writeJSONErrorCode(w, status, code, err.Error())
+}
+
+// ---------------------- Mutaciones del FS (JSON) ----------------------
+
+// fsMutReq es el cuerpo de las mutaciones; los campos siguen los flags del
+// CLI. id y ruta también se aceptan en la query (útil para DELETE).
+type fsMutReq struct {
+	ID      string `json:"id"`
+	Ruta    string `json:"ruta"`
+	Destino string `json:"destino,omitempty"`
+	Name    string `json:"name,omitempty"`
+	UGO     string `json:"ugo,omitempty"`
+	Usuario string `json:"usuario,omitempty"`
+	R       bool   `json:"r,omitempty"`
+	P       bool   `json:"p,omitempty"`
+}
+
+type fsMutRes struct {
+	OK   bool   `json:"ok"`
+	Op   string `json:"op"`
+	Ruta string `json:"ruta"`
+}
+
+// fsMutation valida método, sesión y cuerpo, y ejecuta run con el estado
+// serializado. Los errores salen como {"error": ..., "code": ...}.
+func (a *App) fsMutation(w http.ResponseWriter, r *http.Request, method, op string, run func(req fsMutReq) error) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeJSONErrorCode(w, http.StatusMethodNotAllowed, "method_not_allowed", "fs/"+op+": solo "+method)
+		return
+	}
+	sess, err := auth.Require()
+	if err != nil {
+		writeJSONErrorCode(w, http.StatusUnauthorized, "unauthorized", "requiere login")
+		return
+	}
+
+	var req fsMutReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpload)).Decode(&req); err != nil && err != io.EOF {
+			writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "fs/"+op+": json inválido")
+			return
+		}
+	}
+	q := r.URL.Query()
+	if req.ID == "" {
+		req.ID = q.Get("id")
+	}
+	if req.Ruta == "" {
+		req.Ruta = q.Get("ruta")
+	}
+	req.ID = strings.TrimSpace(req.ID)
+	req.Ruta = strings.TrimSpace(req.Ruta)
+	if req.ID == "" || req.Ruta == "" {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "fs/"+op+": se requieren id y ruta")
+		return
+	}
+	if req.ID != strings.TrimSpace(sess.ID) {
+		writeJSONErrorCode(w, http.StatusForbidden, "forbidden", "fs/"+op+": id no coincide con la sesión activa")
+		return
+	}
+
+	a.mu.Lock()
+	err = run(req)
+	if err == nil {
+		err = flushDirty()
+	}
+	a.mu.Unlock()
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, fsMutRes{OK: true, Op: op, Ruta: req.Ruta})
+}
+
+func (a *App) handleFSMkdir(w http.ResponseWriter, r *http.Request) {
+	a.fsMutation(w, r, http.MethodPost, "mkdir", func(req fsMutReq) error {
+		return usersvc.Mkdir(a.reg, req.Ruta, req.P)
+	})
+}
+
+func (a *App) handleFSNode(w http.ResponseWriter, r *http.Request) {
+	a.fsMutation(w, r, http.MethodDelete, "node", func(req fsMutReq) error {
+		return usersvc.Remove(a.reg, req.Ruta)
+	})
+}
+
+func (a *App) handleFSRename(w http.ResponseWriter, r *http.Request) {
+	a.fsMutation(w, r, http.MethodPost, "rename", func(req fsMutReq) error {
+		return usersvc.Rename(a.reg, req.Ruta, req.Name)
+	})
+}
+
+func (a *App) handleFSMove(w http.ResponseWriter, r *http.Request) {
+	a.fsMutation(w, r, http.MethodPost, "move", func(req fsMutReq) error {
+		return usersvc.Move(a.reg, req.Ruta, req.Destino)
+	})
+}
+
+func (a *App) handleFSCopy(w http.ResponseWriter, r *http.Request) {
+	a.fsMutation(w, r, http.MethodPost, "copy", func(req fsMutReq) error {
+		return usersvc.Copy(a.reg, req.Ruta, req.Destino)
+	})
+}
+
+func (a *App) handleFSChmod(w http.ResponseWriter, r *http.Request) {
+	a.fsMutation(w, r, http.MethodPost, "chmod", func(req fsMutReq) error {
+		return usersvc.Chmod(a.reg, req.Ruta, req.UGO, req.R)
+	})
+}
+
+func (a *App) handleFSChown(w http.ResponseWriter, r *http.Request) {
+	a.fsMutation(w, r, http.MethodPost, "chown", func(req fsMutReq) error {
+		return usersvc.Chown(a.reg, req.Ruta, req.Usuario, req.R)
+	})
+}
+
+// ---------------------------- Eventos (SSE) ----------------------------
+
+// eventsHeartbeat mantiene viva la conexión detrás de proxies.
+const eventsHeartbeat = 15 * time.Second
+
+// handleEvents envía como server-sent events los cambios que publica usersvc
+// sobre la partición ?id= ("event: <kind>" + el Event en JSON). El stream se
+// corta si la sesión cambia o se cierra.
+func (a *App) handleEvents(w http.ResponseWriter, r *http.Request) {
+	sess, err := auth.Require()
+	if err != nil {
+		writeJSONErrorCode(w, http.StatusUnauthorized, "unauthorized", "requiere login")
+		return
+	}
+	id := strings.TrimSpace(r.URL.Query().Get("id"))
+	if id == "" {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "events: falta ?id=")
+		return
+	}
+	if id != strings.TrimSpace(sess.ID) {
+		writeJSONErrorCode(w, http.StatusForbidden, "forbidden", "events: id no coincide con la sesión activa")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "events: streaming no soportado")
+		return
+	}
+
+	ch, cancel := events.Subscribe(id)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": ok\n\n")
+	flusher.Flush()
+
+	tick := time.NewTicker(eventsHeartbeat)
+	defer tick.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-ch:
+			b, _ := json.Marshal(ev)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.Kind, b)
+		case <-tick.C:
+			if cur, ok := auth.Current(); !ok || cur == nil || strings.TrimSpace(cur.ID) != id {
+				return
+			}
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// ------------------------------- Auditoría -------------------------------
+
+func (a *App) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.Require(); err != nil {
+		writeJSONErrorCode(w, http.StatusUnauthorized, "unauthorized", "requiere login")
+		return
+	}
+	q := r.URL.Query()
+	f := audit.Filter{
+		User:    strings.TrimSpace(q.Get("user")),
+		ID:      strings.TrimSpace(q.Get("id")),
+		Command: strings.TrimSpace(q.Get("cmd")),
+		Source:  strings.ToLower(strings.TrimSpace(q.Get("source"))),
+		Status:  strings.ToLower(strings.TrimSpace(q.Get("status"))),
+	}
+	var err error
+	if f.Since, err = audit.ParseTime(q.Get("since")); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "audit: since: "+err.Error())
+		return
+	}
+	if f.Until, err = audit.ParseTime(q.Get("until")); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "audit: until: "+err.Error())
+		return
+	}
+	if v := strings.TrimSpace(q.Get("limit")); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 0 {
+			writeJSONError(w, http.StatusBadRequest, "audit: limit inválido")
+			return
+		}
+	}
+
+	recs, err := usersvc.Audit(f)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, recs)
+}
+
+// ---------------------- Discos, particiones y montajes ----------------------
+
+type diskDTO struct {
+	DiskPath  string             `json:"diskPath"`
+	Exists    bool               `json:"exists"`
+	Encrypted bool               `json:"encrypted"`
+	MBR       *reports.MBRReport `json:"mbr,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+type diskCreateReq struct {
+	Path       string `json:"path"`
+	Size       int    `json:"size"`
+	Unit       string `json:"unit"`
+	Fit        string `json:"fit"`
+	Table      string `json:"table"`
+	Encrypt    bool   `json:"encrypt"`
+	Passphrase string `json:"passphrase"`
+}
+
+type partReq struct {
+	Name       string `json:"name"`
+	Size       int64  `json:"size"`
+	Unit       string `json:"unit"`
+	Type       string `json:"type"`
+	Fit        string `json:"fit"`
+	Add        int64  `json:"add"`
+	Start      string `json:"start"`
+	Delete     string `json:"delete"`
+	Passphrase string `json:"passphrase"`
+}
+
+type mountReq struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	Passphrase string `json:"passphrase"`
+}
+
+type formatReq struct {
+	FS       string `json:"fs"`
+	Bitmap   string `json:"bitmap"`
+	Backup   *bool  `json:"backup"`
+	Checksum bool   `json:"checksum"`
+	Cow      *bool  `json:"cow"`
+}
+
+type diskDeleteRes struct {
+	OK       bool   `json:"ok"`
+	DiskPath string `json:"diskPath"`
+	Purged   int    `json:"purged"`
+}
+
+type unmountRes struct {
+	OK bool   `json:"ok"`
+	ID string `json:"id"`
+}
+
+type formatRes struct {
+	OK bool   `json:"ok"`
+	ID string `json:"id"`
+	FS string `json:"fs"`
+}
+
+// decodeBody lee el cuerpo JSON en v; un cuerpo vacío deja v como está.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpload)).Decode(v)
+	if err != nil && err != io.EOF {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "json inválido")
+		return false
+	}
+	return true
+}
+
+// diskPathValue toma {path} (la ruta del .mia escapada, p. ej.
+// %2Ftmp%2Fd1.mia) y verifica que sea un disco del catálogo y que exista:
+// la API no toca otros archivos del host.
+func diskPathValue(w http.ResponseWriter, r *http.Request) (string, bool) {
+	p := filepath.Clean(strings.TrimSpace(r.PathValue("path")))
+	known, err := catalog.All()
+	if err != nil {
+		writeJSONErrorCode(w, http.StatusInternalServerError, "internal", "disks: leyendo catálogo: "+err.Error())
+		return "", false
+	}
+	if !strings.HasSuffix(strings.ToLower(p), ".mia") || !slices.Contains(known, p) {
+		writeJSONErrorCode(w, http.StatusNotFound, "not_found", "disks: "+p+" no es un disco del catálogo")
+		return "", false
+	}
+	if _, err := os.Stat(p); err != nil {
+		writeAPIError(w, fmt.Errorf("disks: %s: %w", p, err))
+		return "", false
+	}
+	return p, true
+}
+
+// requireLogin responde 401 si no hay sesión; las rutas de discos y
+// particiones la exigen como las del FS.
+func requireLogin(w http.ResponseWriter) bool {
+	if _, err := auth.Require(); err != nil {
+		writeJSONErrorCode(w, http.StatusUnauthorized, "unauthorized", "requiere login")
+		return false
+	}
+	return true
+}
+
+func (a *App) handleListDisks(w http.ResponseWriter, r *http.Request) {
+	if !requireLogin(w) {
+		return
+	}
+	paths, err := catalog.All()
+	if err != nil {
+		writeJSONErrorCode(w, http.StatusInternalServerError, "internal", "disks: leyendo catálogo: "+err.Error())
+		return
+	}
+	out := make([]diskDTO, 0, len(paths))
+	for _, p := range paths {
+		d := diskDTO{DiskPath: p}
+		if _, err := os.Stat(p); err != nil {
+			d.Error = err.Error()
+			out = append(out, d)
+			continue
+		}
+		d.Exists = true
+		d.Encrypted = diskio.IsEncrypted(p)
+		if rep, err := reports.BuildMBRAt(p); err != nil {
+			d.Error = err.Error()
+		} else {
+			d.MBR = &rep
+		}
+		out = append(out, d)
+	}
+	writeJSON(w, out)
+}
+
+func (a *App) handleCreateDisk(w http.ResponseWriter, r *http.Request) {
+	if !requireLogin(w) {
+		return
+	}
+	var req diskCreateReq
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	path := strings.TrimSpace(req.Path)
+	if path == "" || req.Size <= 0 {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "mkdisk: se requieren path y size > 0")
+		return
+	}
+	if req.Encrypt && req.Passphrase == "" {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "mkdisk: encrypt requiere passphrase")
+		return
+	}
+	if !strings.HasSuffix(strings.ToLower(path), ".mia") {
+		path += ".mia"
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := commands.ExecuteMkdisk(req.Size, defaultStr(req.Unit, "m"), defaultStr(req.Fit, "ff"), path, req.Table); err != nil {
+		writeAPIError(w, fmt.Errorf("mkdisk: %w", err))
+		return
+	}
+	if req.Encrypt {
+		if err := commands.ExecuteEncrypt(path, req.Passphrase); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+	}
+	if err := flushDirty(); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	_ = catalog.Add(path)
+	_ = a.reg.RehydrateFromCatalog()
+
+	rep, err := reports.BuildMBRAt(path)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, rep)
+}
+
+func (a *App) handleGetDisk(w http.ResponseWriter, r *http.Request) {
+	if !requireLogin(w) {
+		return
+	}
+	path, ok := diskPathValue(w, r)
+	if !ok {
+		return
+	}
+	if !a.unlockFromQuery(w, r, "disks", path) {
+		return
+	}
+	rep, err := reports.BuildDiskAt(path)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, rep)
+}
+
+func (a *App) handleDeleteDisk(w http.ResponseWriter, r *http.Request) {
+	if !requireLogin(w) {
+		return
+	}
+	path, ok := diskPathValue(w, r)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !commands.ExecuteRmdisk(path) {
+		writeJSONErrorCode(w, http.StatusInternalServerError, "internal", "rmdisk: no se pudo eliminar "+path)
+		return
+	}
+	_ = catalog.Remove(path)
+	purged := a.reg.PurgeDisk(path)
+	_ = a.reg.RehydrateFromCatalog()
+	writeJSON(w, diskDeleteRes{OK: true, DiskPath: path, Purged: purged})
+}
+
+// unlockFromQuery aplica ?passphrase= si vino (discos cifrados).
+func (a *App) unlockFromQuery(w http.ResponseWriter, r *http.Request, op, path string) bool {
+	if err := commands.UnlockDisk(op, path, r.URL.Query().Get("passphrase")); err != nil {
+		writeAPIError(w, err)
+		return false
+	}
+	return true
+}
+
+func (a *App) handleListPartitions(w http.ResponseWriter, r *http.Request) {
+	if !requireLogin(w) {
+		return
+	}
+	path, ok := diskPathValue(w, r)
+	if !ok {
+		return
+	}
+	if !a.unlockFromQuery(w, r, "fdisk", path) {
+		return
+	}
+	rep, err := reports.BuildMBRAt(path)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, rep)
+}
+
+// partitionOp ejecuta una operación de fdisk sobre el disco {path} y
+// responde con la tabla resultante.
+func (a *App) partitionOp(w http.ResponseWriter, r *http.Request, code int, run func(path string, req partReq) error) {
+	if !requireLogin(w) {
+		return
+	}
+	path, ok := diskPathValue(w, r)
+	if !ok {
+		return
+	}
+	var req partReq
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if n := r.PathValue("name"); n != "" {
+		req.Name = n
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := commands.UnlockDisk("fdisk", path, req.Passphrase); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	if err := run(path, req); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	if err := flushDirty(); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	_ = catalog.Add(path)
+	_ = a.reg.RehydrateFromCatalog()
+
+	rep, err := reports.BuildMBRAt(path)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	writeJSON(w, rep)
+}
+
+func (a *App) handleCreatePartition(w http.ResponseWriter, r *http.Request) {
+	a.partitionOp(w, r, http.StatusCreated, func(path string, req partReq) error {
+		return commands.ExecuteFdisk(commands.FdiskOptions{
+			Path: path, Name: req.Name, Unit: req.Unit, Type: req.Type, Fit: req.Fit, Size: req.Size,
+		})
+	})
+}
+
+// handleResizePartition cambia el tamaño (add, en unit) o, con start
+// ("auto" o byte absoluto), reubica la partición.
+func (a *App) handleResizePartition(w http.ResponseWriter, r *http.Request) {
+	a.partitionOp(w, r, http.StatusOK, func(path string, req partReq) error {
+		if strings.TrimSpace(req.Start) != "" {
+			return commands.ExecuteFdiskMove(a.reg, commands.FdiskOptions{Path: path, Name: req.Name, Start: req.Start})
+		}
+		if req.Add == 0 {
+			return errors.New("fdisk add: se requiere add != 0 o start")
+		}
+		return commands.ExecuteFdisk(commands.FdiskOptions{Path: path, Name: req.Name, Unit: req.Unit, Add: req.Add})
+	})
+}
+
+// handleDeletePartition borra la partición; ?delete=full rellena con ceros.
+func (a *App) handleDeletePartition(w http.ResponseWriter, r *http.Request) {
+	a.partitionOp(w, r, http.StatusOK, func(path string, req partReq) error {
+		mode := defaultStr(req.Delete, defaultStr(r.URL.Query().Get("delete"), "fast"))
+		return commands.ExecuteFdisk(commands.FdiskOptions{Path: path, Name: req.Name, Delete: mode})
+	})
+}
+
+func (a *App) handleMount(w http.ResponseWriter, r *http.Request) {
+	var req mountReq
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	path := strings.TrimSpace(req.Path)
+	name := strings.TrimSpace(req.Name)
+	if path == "" || name == "" {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", "mount: se requieren path y name")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := commands.UnlockDisk("mount", path, req.Passphrase); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	id, err := a.svc.Mount(path, name)
+	if err == nil {
+		err = flushDirty()
+	}
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	_ = catalog.Add(path)
+	_ = a.reg.RehydrateFromCatalog()
+
+	dto := mountDTO{ID: id, DiskPath: path, Name: name}
+	if mp, ok := a.reg.GetByID(id); ok {
+		dto.Start, dto.Size = mp.Start, mp.Size
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, dto)
+}
+
+func (a *App) handleUnmount(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(r.PathValue("id"))
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.svc.UnmountByID(id); err != nil {
+		writeAPIError(w, fmt.Errorf("unmount: %s: %w", id, err))
+		return
+	}
+	if err := flushDirty(); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, unmountRes{OK: true, ID: id})
+}
+
+// handleFormat es mkfs sobre el ID montado; los campos siguen los flags.
+func (a *App) handleFormat(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(r.PathValue("id"))
+	var req formatReq
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	backup, cow := true, true
+	if req.Backup != nil {
+		backup = *req.Backup
+	}
+	if req.Cow != nil {
+		cow = *req.Cow
+	}
+	mk, err := commands.NewMkfsRequest(id, req.FS, req.Bitmap, backup, req.Checksum, cow)
+	if err != nil {
+		writeJSONErrorCode(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.reg.GetByID(id); !ok {
+		writeAPIError(w, fmt.Errorf("mkfs: %s: %w", id, mount.ErrIDNotFound))
+		return
+	}
+	err = commands.Mkfs(a.reg, mk)
+	if err == nil {
+		err = flushDirty()
+	}
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, formatRes{OK: true, ID: id, FS: mk.FS})
+}
+
+func defaultStr(s, d string) string {
+	if strings.TrimSpace(s) == "" {
+		return d
+	}
+	return s
+}
+
 func (a *App) handleReportMBR(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimSpace(r.URL.Query().Get("id"))
 	if id == "" {
@@ -268,8 +1059,7 @@ func (a *App) handleReportDisk(w http.ResponseWriter, r *http.Request) {
 	rep, err := reports.BuildDisk(a.reg, id)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -283,8 +1073,7 @@ func (a *App) handleReportInode(w http.ResponseWriter, r *http.Request) {
 	rep, err := reports.BuildInode(a.reg, id, ruta)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -303,8 +1092,7 @@ func (a *App) handleReportInodes(w http.ResponseWriter, r *http.Request) {
 	rep, err := reports.BuildInodes(a.reg, id, max)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -318,15 +1106,13 @@ func (a *App) handleReportBlock(w http.ResponseWriter, r *http.Request) {
 
 	id := strings.TrimSpace(r.URL.Query().Get("id"))
 	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "id requerido"})
+		writeJSONError(w, http.StatusBadRequest, "id requerido")
 		return
 	}
 
 	rep, err := reports.BuildBlock(a.reg, id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	_ = json.NewEncoder(w).Encode(rep)
@@ -338,13 +1124,13 @@ func (a *App) handleReportBmInode(w http.ResponseWriter, r *http.Request) {
 
 	id := strings.TrimSpace(r.URL.Query().Get("id"))
 	if id == "" {
-		http.Error(w, "id requerido", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "id requerido")
 		return
 	}
 
 	txt, err := reports.BuildBmInodeText(a.reg, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	_, _ = w.Write([]byte(txt))
@@ -356,13 +1142,13 @@ func (a *App) handleReportBmBlock(w http.ResponseWriter, r *http.Request) {
 
 	id := strings.TrimSpace(r.URL.Query().Get("id"))
 	if id == "" {
-		http.Error(w, "id requerido", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "id requerido")
 		return
 	}
 
 	txt, err := reports.BuildBmBlockText(a.reg, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	_, _ = w.Write([]byte(txt))
@@ -370,7 +1156,7 @@ func (a *App) handleReportBmBlock(w http.ResponseWriter, r *http.Request) {
 
 func (a *App) handleReportTree(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "solo GET", http.StatusMethodNotAllowed)
+		writeJSONError(w, http.StatusMethodNotAllowed, "solo GET")
 		return
 	}
 	id := strings.TrimSpace(r.URL.Query().Get("id"))
@@ -380,16 +1166,13 @@ func (a *App) handleReportTree(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if id == "" {
-		http.Error(w, `falta query ?id=`, http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, `falta query ?id=`)
 		return
 	}
 
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
 	rep, err := reports.BuildTree(a.reg, id)
 	if err != nil {
-		http.Error(w, "tree: "+err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "tree: "+err.Error())
 		return
 	}
 
@@ -402,7 +1185,7 @@ func (a *App) handleReportTree(w http.ResponseWriter, r *http.Request) {
 		html := reports.RenderHTMLTree(rep)
 		_, _ = io.WriteString(w, html)
 	default:
-		http.Error(w, "format debe ser json|html", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "format debe ser json|html")
 	}
 }
 
@@ -410,7 +1193,7 @@ func (a *App) handleReportSB(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	rep, err := reports.BuildSB(a.reg, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -419,6 +1202,20 @@ func (a *App) handleReportSB(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(rep)
 }
 
+// GET /api/reports/frag?id=XXXX
+func (a *App) handleReportFrag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	id := strings.TrimSpace(r.URL.Query().Get("id"))
+	rep, err := reports.BuildFrag(a.reg, id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	_ = json.NewEncoder(w).Encode(rep)
+}
+
 func (a *App) handleReportFile(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 
@@ -432,13 +1229,7 @@ func (a *App) handleReportFile(w http.ResponseWriter, r *http.Request) {
 		ruta = r.URL.Query().Get("path_file_ls")
 	}
 	if id == "" || ruta == "" {
-		http.Error(w, "rep file: se requieren id y ruta", http.StatusBadRequest)
-		return
-	}
-
-	data, err := reports.BuildFile(a.reg, id, ruta) // antes usabas pathFile
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "rep file: se requieren id y ruta")
 		return
 	}
 
@@ -449,17 +1240,37 @@ func (a *App) handleReportFile(w http.ResponseWriter, r *http.Request) {
 	if filepath.Ext(name) == "" {
 		name += ".txt"
 	}
+	setHeaders := func() {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+		w.Header().Set("Cache-Control", "no-store")
+	}
 
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
-	w.Header().Set("Cache-Control", "no-store")
+	// Con Range se sirve por rangos leyendo sólo los bloques pedidos;
+	// ServeContent responde 206/416 y anuncia Accept-Ranges.
+	if r.Header.Get("Range") != "" {
+		if rs, err := reports.OpenFileRange(a.reg, id, ruta); err == nil {
+			setHeaders()
+			http.ServeContent(w, r, name, time.Time{}, rs)
+			return
+		}
+	}
+
+	data, err := reports.BuildFile(a.reg, id, ruta) // antes usabas pathFile
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	setHeaders()
+	w.Header().Set("Accept-Ranges", "bytes")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(data)
 }
 
 func (a *App) handleReportJournaling(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "solo GET", http.StatusMethodNotAllowed)
+		writeJSONError(w, http.StatusMethodNotAllowed, "solo GET")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -496,21 +1307,48 @@ func writeJSON(w http.ResponseWriter, v any) {
 	_ = enc.Encode(v)
 }
 
-func writeJSONError(w http.ResponseWriter, code int, msg string) {
+// errorBody es el sobre de todas las respuestas de error de la API.
+type errorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// statusCodes da el código de error por defecto de cada estado HTTP.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusMethodNotAllowed:    "method_not_allowed",
+	http.StatusConflict:            "conflict",
+	http.StatusInternalServerError: "internal",
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = "error"
+	}
+	writeJSONErrorCode(w, status, code, msg)
+}
+
+// writeJSONErrorCode es writeJSONError con un código más preciso que el
+// del estado.
+func writeJSONErrorCode(w http.ResponseWriter, status int, code, msg string) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorBody{Error: msg, Code: code})
 }
 
 func (app *App) handleReportLS(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "solo GET", http.StatusMethodNotAllowed)
+		writeJSONError(w, http.StatusMethodNotAllowed, "solo GET")
 		return
 	}
 
 	id := strings.TrimSpace(r.URL.Query().Get("id"))
 	if id == "" {
-		http.Error(w, "rep ls: -id requerido", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "rep ls: -id requerido")
 		return
 	}
 	ruta := strings.TrimSpace(r.URL.Query().Get("ruta"))
@@ -520,7 +1358,7 @@ func (app *App) handleReportLS(w http.ResponseWriter, r *http.Request) {
 
 	rep, err := reports.BuildLS(app.reg, id, ruta)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -574,7 +1412,20 @@ func (a *App) ProcessLine(line string) string {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// code es el resultado de los comandos que lo devuelven; los demás lo
+	// dejan en -1 y sólo imprimen "Error: ...", que auditLine busca en la
+	// salida.
+	code := -1
+	start := time.Now()
+	before, _ := auth.Current()
+
 	stdout, stderr := captureOutput(func() {
+		// Write-back: las páginas sucias se escriben al terminar cada comando.
+		defer func() {
+			if err := diskio.FlushAll(); err != nil {
+				fmt.Println("Error: flush:", err)
+			}
+		}()
 
 		tokens := u.Tokeniza(line)
 		if len(tokens) == 0 {
@@ -591,6 +1442,9 @@ func (a *App) ProcessLine(line string) string {
 			unit := fs.String("unit", "m", "Unidad del tamaño (k/m).")
 			fit := fs.String("fit", "ff", "Tipo de ajuste (bf/ff/wf).")
 			path := fs.String("path", "", "Ruta del disco a crear.")
+			table := fs.String("table", "mbr", "Tabla de particiones (mbr/gpt).")
+			encrypt := fs.Bool("encrypt", false, "Cifra el disco (requiere -passphrase).")
+			pass := fs.String("passphrase", "", "Frase para derivar la clave de cifrado.")
 			if err := fs.Parse(args); err != nil {
 				fmt.Println("Error:", err)
 				return
@@ -604,10 +1458,20 @@ func (a *App) ProcessLine(line string) string {
 				fmt.Println("Error: el parámetro -size es obligatorio y debe ser positivo.")
 				return
 			}
-			if err := commands.ExecuteMkdisk(*size, *unit, *fit, *path); err != nil {
+			if *encrypt && *pass == "" {
+				fmt.Println("Error: -encrypt requiere -passphrase.")
+				return
+			}
+			if err := commands.ExecuteMkdisk(*size, *unit, *fit, *path, *table); err != nil {
 				fmt.Println("Error:", err)
 				return
 			}
+			if *encrypt {
+				if err := commands.ExecuteEncrypt(*path, *pass); err != nil {
+					fmt.Println("Error:", err)
+					return
+				}
+			}
 
 			fmt.Println("Disco creado exitosamente en:", *path)
 			_ = catalog.Add(*path)
@@ -646,6 +1510,9 @@ func (a *App) ProcessLine(line string) string {
 			fit := fs.String("fit", "wf", "Ajuste al CREAR (bf/ff/wf).")
 			del := fs.String("delete", "", "Elimina partición por nombre: fast|full.")
 			add := fs.Int64("add", 0, "Agrega(+) o quita(-) espacio a la partición.")
+			move := fs.Bool("move", false, "Reubica la partición -name en -start.")
+			start := fs.String("start", "auto", "Destino de -move: auto o byte absoluto.")
+			pass := fs.String("passphrase", "", "Frase del disco si está cifrado.")
 			if err := fs.Parse(args); err != nil {
 				fmt.Println("Error:", err)
 				return
@@ -654,6 +1521,26 @@ func (a *App) ProcessLine(line string) string {
 				fmt.Println("Error: -path es obligatorio.")
 				return
 			}
+			if err := commands.UnlockDisk("fdisk", *path, *pass); err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			// MOVE
+			if *move {
+				if strings.TrimSpace(*name) == "" {
+					fmt.Println("Error: -name es obligatorio para -move.")
+					return
+				}
+				if err := commands.ExecuteFdiskMove(a.reg, commands.FdiskOptions{
+					Path: *path, Name: *name, Start: *start,
+				}); err != nil {
+					fmt.Println("Error:", err)
+					return
+				}
+				_ = catalog.Add(*path)
+				_ = a.reg.RehydrateFromCatalog()
+				return
+			}
 			// DELETE
 			if strings.TrimSpace(*del) != "" {
 				if strings.TrimSpace(*name) == "" {
@@ -706,6 +1593,7 @@ func (a *App) ProcessLine(line string) string {
 			fs.SetOutput(io.Discard)
 			path := fs.String("path", "", "Ruta del disco (.mia)")
 			name := fs.String("name", "", "Nombre de la partición (primaria)")
+			pass := fs.String("passphrase", "", "Frase del disco si está cifrado")
 			if err := fs.Parse(args); err != nil {
 				fmt.Println("Error:", err)
 				return
@@ -714,6 +1602,10 @@ func (a *App) ProcessLine(line string) string {
 				fmt.Println("Error: -path y -name son obligatorios para mount.")
 				return
 			}
+			if err := commands.UnlockDisk("mount", *path, *pass); err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
 			if id, err := a.svc.Mount(*path, *name); err != nil {
 				fmt.Println("Error:", err)
 			} else {
@@ -792,82 +1684,77 @@ func (a *App) ProcessLine(line string) string {
 			return
 
 		case "mkfs":
-			fs := flag.NewFlagSet("mkfs", flag.ContinueOnError)
-			fs.SetOutput(io.Discard)
-			id := fs.String("id", "", "ID de partición montada (p.ej. 39A1)")
-			typ := fs.String("type", "full", "Tipo de formateo (solo 'full')")
-			fstype := fs.String("fs", "ext2", "Sistema de archivos: ext2|ext3 (default ext2)")
-			if err := fs.Parse(args); err != nil {
-				fmt.Println("Error:", err)
-				return
-			}
-			if strings.TrimSpace(*id) == "" {
-				fmt.Println("uso: mkfs -id=<ID> [-type=full] [-fs=ext2|ext3]")
-				return
-			}
-			if strings.ToLower(strings.TrimSpace(*typ)) != "full" {
-				fmt.Println("Aviso: solo se implementa -type=full; se usará full.")
-			}
-
-			switch strings.ToLower(strings.TrimSpace(*fstype)) {
-			case "ext3":
-				// nuevo formateador EXT3
-				if err := ext3.NewFormatter(a.reg).MkfsFull(*id); err != nil {
-					fmt.Println("Error:", err)
-					return
-				}
-				fmt.Println("mkfs: formateo EXT3 completado en", *id)
-			default:
-				// ext2 por defecto
-				if err := a.formatter.MkfsFull(*id); err != nil {
-					fmt.Println("Error:", err)
-					return
-				}
-				fmt.Println("mkfs: formateo EXT2 completado en", *id)
-			}
-
+			code = commands.CmdMkfs(a.reg, args)
 		case "login":
-			_ = commands.CmdLogin(a.reg, args)
+			code = commands.CmdLogin(a.reg, args)
 		case "logout":
-			_ = commands.CmdLogout(args)
+			code = commands.CmdLogout(args)
 		case "mkgrp":
-			_ = commands.CmdMkgrp(a.reg, args)
+			code = commands.CmdMkgrp(a.reg, args)
 		case "rmgrp":
-			_ = commands.CmdRmgrp(a.reg, args)
+			code = commands.CmdRmgrp(a.reg, args)
 		case "mkusr":
-			_ = commands.CmdMkusr(a.reg, args)
+			code = commands.CmdMkusr(a.reg, args)
 		case "rmusr":
-			_ = commands.CmdRmusr(a.reg, args)
+			code = commands.CmdRmusr(a.reg, args)
 		case "chgrp":
-			_ = commands.CmdChgrp(a.reg, args)
+			code = commands.CmdChgrp(a.reg, args)
 		case "mkfile":
-			_ = commands.CmdMkfile(a.reg, args)
+			code = commands.CmdMkfile(a.reg, args)
 		case "mkdir":
-			_ = commands.CmdMkdir(a.reg, args)
+			code = commands.CmdMkdir(a.reg, args)
 		case "cat":
-			_ = commands.CmdCat(a.reg, args)
+			code = commands.CmdCat(a.reg, args)
+		case "head":
+			code = commands.CmdHead(a.reg, args)
+		case "tail":
+			code = commands.CmdTail(a.reg, args)
+		case "append":
+			code = commands.CmdAppend(a.reg, args)
+		case "truncate":
+			code = commands.CmdTruncate(a.reg, args)
 		case "rep":
-			_ = commands.CmdRep(a.reg, args)
+			code = commands.CmdRep(a.reg, args)
 		case "remove":
-			_ = commands.CmdRemove(a.reg, args)
+			code = commands.CmdRemove(a.reg, args)
 		case "edit":
-			_ = commands.CmdEdit(a.reg, args)
+			code = commands.CmdEdit(a.reg, args)
 		case "rename":
-			_ = commands.CmdRename(a.reg, args)
+			code = commands.CmdRename(a.reg, args)
 		case "copy":
-			_ = commands.CmdCopy(a.reg, args)
+			code = commands.CmdCopy(a.reg, args)
 		case "move":
-			_ = commands.CmdMove(a.reg, args)
+			code = commands.CmdMove(a.reg, args)
 		case "find":
-			_ = commands.CmdFind(a.reg, args)
+			code = commands.CmdFind(a.reg, args)
 		case "chown":
-			_ = commands.CmdChown(a.reg, args)
+			code = commands.CmdChown(a.reg, args)
+		case "chattr":
+			code = commands.CmdChattr(a.reg, args)
 		case "recovery":
-			_ = commands.CmdRecovery(a.reg, args)
+			code = commands.CmdRecovery(a.reg, args)
 		case "loss":
-			_ = commands.CmdLoss(a.reg, args)
+			code = commands.CmdLoss(a.reg, args)
 		case "journaling":
-			_ = commands.CmdJournaling(a.reg, args)
+			code = commands.CmdJournaling(a.reg, args)
+		case "defrag":
+			code = commands.CmdDefrag(a.reg, args)
+		case "scrub":
+			code = commands.CmdScrub(a.reg, args)
+		case "sync", "flush":
+			code = commands.CmdSync(a.reg, args)
+		case "compact":
+			code = commands.CmdCompact(args)
+		case "encrypt":
+			code = commands.CmdEncrypt(args)
+		case "rescue":
+			var path string
+			if path, code = commands.CmdRescue(args); path != "" {
+				_ = catalog.Add(path)
+				_ = a.reg.RehydrateFromCatalog()
+			}
+		case "audit":
+			code = commands.CmdAudit(args)
 		case "chmod":
 			fs := flag.NewFlagSet("chmod", flag.ContinueOnError)
 			fs.SetOutput(io.Discard)
@@ -898,17 +1785,66 @@ func (a *App) ProcessLine(line string) string {
 
 		default:
 			fmt.Printf("Comando '%s' no reconocido.\n", command)
+			code = 1
 		}
 		// ======= FIN switch =======
 	})
 
+	out := stdout + "\n" + stderr
 	if strings.TrimSpace(stderr) == "" {
-		return stdout
+		out = stdout
+	} else if strings.TrimSpace(stdout) == "" {
+		out = stderr
 	}
-	if strings.TrimSpace(stdout) == "" {
-		return stderr
+	auditLine(line, out, code, start, before)
+	return out
+}
+
+// auditLine registra una línea ejecutada por ProcessLine. El usuario es el
+// de la sesión al terminar (o el de antes, si el comando fue logout).
+func auditLine(line, out string, code int, start time.Time, before *auth.Session) {
+	tokens := u.Tokeniza(line)
+	if len(tokens) == 0 {
+		return
+	}
+	args := u.NormalizaFlags(tokens[1:])
+	rec := audit.Record{
+		Time:       start,
+		Source:     audit.SourceCmd,
+		Command:    strings.ToLower(tokens[0]),
+		Args:       audit.RedactArgs(args),
+		OK:         code <= 0,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if s, ok := auth.Current(); ok {
+		rec.User, rec.ID = s.User, s.ID
+	} else if before != nil {
+		rec.User, rec.ID = before.User, before.ID
+	}
+	for _, a := range args {
+		if v, ok := strings.CutPrefix(a, "-id="); ok && strings.TrimSpace(v) != "" {
+			rec.ID = strings.TrimSpace(v)
+		}
+	}
+
+	// La salida sólo decide el resultado cuando el comando no dio código
+	// (podría ser el contenido de un archivo con "Error" en una línea).
+	if code != 0 {
+		for _, ln := range strings.Split(out, "\n") {
+			ln = strings.TrimSpace(ln)
+			low := strings.ToLower(ln)
+			if strings.HasPrefix(low, "error") || strings.HasPrefix(low, "uso:") || strings.HasSuffix(low, "no reconocido.") {
+				rec.OK, rec.Error = false, ln
+				break
+			}
+		}
+	}
+	if !rec.OK && rec.Error == "" {
+		rec.Error = fmt.Sprintf("código %d", code)
+	}
+	if err := audit.Append(rec); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 	}
-	return stdout + "\n" + stderr
 }
 
 // ---------------------- Handlers HTTP ----------------------
@@ -923,17 +1859,13 @@ type ExecRes struct {
 func (a *App) handleExec(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		if rec := recover(); rec != nil {
-			w.Header().Set("Content-Type", "application/json; charset=utf-8")
-			w.WriteHeader(http.StatusInternalServerError)
-			_ = json.NewEncoder(w).Encode(map[string]any{
-				"output": fmt.Sprintf("panic: %v", rec),
-			})
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("panic: %v", rec))
 		}
 	}()
 
 	var req ExecReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"output":"json inválido"}`, http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "exec: json inválido")
 		return
 	}
 	lines := strings.Split(req.Script, "\n")
@@ -958,34 +1890,269 @@ func (a *App) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // ---------------------- Arranques ----------------------
 
+// route une un handler con su descripción para /api/openapi.json.
+type route struct {
+	apidoc.Op
+	h http.HandlerFunc
+}
+
+var (
+	qID    = apidoc.Param{Name: "id", Desc: "ID montado", Required: true}
+	qRuta  = apidoc.Param{Name: "ruta", Desc: "ruta absoluta dentro de la partición", Required: true}
+	qPass  = apidoc.Param{Name: "passphrase", Desc: "frase del disco si está cifrado"}
+	report = func(path, id, summary string, h http.HandlerFunc, resp any, q ...apidoc.Param) route {
+		return route{apidoc.Op{Method: http.MethodGet, Path: path, ID: id, Tag: "reports", Summary: summary,
+			Query: append([]apidoc.Param{qID}, q...), Resp: resp}, h}
+	}
+)
+
+// routes es la tabla de la API: runHTTP registra cada entrada como
+// "MÉTODO ruta" y el documento OpenAPI se genera de ella.
+func (a *App) routes() []route {
+	fsMut := func(method, path, id, summary string, h http.HandlerFunc) route {
+		op := apidoc.Op{Method: method, Path: path, ID: id, Tag: "fs", Summary: summary, Resp: fsMutRes{}}
+		if method == http.MethodDelete {
+			op.Query = []apidoc.Param{qID, qRuta}
+		} else {
+			op.Body = fsMutReq{}
+		}
+		return route{op, h}
+	}
+	return []route{
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/exec", ID: "exec", Tag: "exec", Summary: "Ejecuta un script de comandos", Body: ExecReq{}, Resp: ExecRes{}}, a.handleExec},
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/health", ID: "health", Tag: "exec", Summary: "Responde ok si el servidor está vivo", RespRaw: "text/plain"}, a.handleHealth},
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/openapi.json", ID: "openAPI", Tag: "exec", Summary: "Este documento", RespRaw: "application/json"}, a.handleOpenAPI},
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/login", ID: "login", Tag: "auth", Summary: "Inicia sesión", Body: LoginReq{}, Resp: LoginRes{}}, a.handleLogin},
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/logout", ID: "logout", Tag: "auth", Summary: "Cierra la sesión", Resp: okRes{}}, a.handleLogout},
+
+		report("/api/reports/mbr", "reportMBR", "Tabla de particiones del disco del ID", a.handleReportMBR, reports.MBRReport{}),
+		report("/api/reports/disk", "reportDisk", "Mapa del disco del ID", a.handleReportDisk, reports.DiskReport{}),
+		report("/api/reports/inode", "reportInode", "Inodos de una ruta (o todos)", a.handleReportInode, reports.InodeReport{},
+			apidoc.Param{Name: "ruta", Desc: "ruta a inspeccionar; vacía = todos"}),
+		report("/api/reports/inodes", "reportInodes", "Tabla de inodos usados", a.handleReportInodes, reports.InodesReport{},
+			apidoc.Param{Name: "max", Desc: "máximo de inodos", Type: "integer"}),
+		report("/api/reports/block", "reportBlock", "Bloques usados", a.handleReportBlock, reports.BlockReport{}),
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/reports/bm_inode", ID: "reportBmInode", Tag: "reports", Summary: "Bitmap de inodos en texto",
+			Query: []apidoc.Param{qID}, RespRaw: "text/plain"}, a.handleReportBmInode},
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/reports/bm_block", ID: "reportBmBlock", Tag: "reports", Summary: "Bitmap de bloques en texto",
+			Query: []apidoc.Param{qID}, RespRaw: "text/plain"}, a.handleReportBmBlock},
+		report("/api/reports/tree", "reportTree", "Árbol de inodos y bloques", a.handleReportTree, reports.TreeReport{},
+			apidoc.Param{Name: "format", Desc: "json (defecto) | html"}),
+		report("/api/reports/sb", "reportSB", "Superbloque", a.handleReportSB, reports.SBReport{}),
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/reports/file", ID: "reportFile", Tag: "reports", Summary: "Contenido de un archivo (admite Range)",
+			Query: []apidoc.Param{qID, qRuta}, RespRaw: "text/plain"}, a.handleReportFile},
+		report("/api/reports/ls", "reportLS", "Listado de una carpeta", a.handleReportLS, reports.LSReport{},
+			apidoc.Param{Name: "ruta", Desc: "carpeta; / por defecto"}),
+		report("/api/reports/frag", "reportFrag", "Fragmentación", a.handleReportFrag, reports.FragReport{}),
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/reports/journaling", ID: "reportJournaling", Tag: "reports", Summary: "Journal de la partición de la sesión",
+			Query: []apidoc.Param{{Name: "id", Desc: "ID montado; por defecto el de la sesión"}}, Resp: []ext3.JournalRow{}}, a.handleReportJournaling},
+
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/mounts", ID: "listMounts", Tag: "mounts", Summary: "Particiones montadas", Resp: []mountDTO{}}, a.handleListMounts},
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/mounts", ID: "mount", Tag: "mounts", Summary: "Monta una partición", Body: mountReq{}, Resp: mountDTO{}, Status: http.StatusCreated}, a.handleMount},
+		{apidoc.Op{Method: http.MethodDelete, Path: "/api/mounts/{id}", ID: "unmount", Tag: "mounts", Summary: "Desmonta un ID", Resp: unmountRes{}}, a.handleUnmount},
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/mounts/{id}/format", ID: "format", Tag: "mounts", Summary: "Formatea (mkfs) un ID montado", Body: formatReq{}, Resp: formatRes{}}, a.handleFormat},
+
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/disks", ID: "listDisks", Tag: "disks", Summary: "Discos del catálogo con su tabla", Resp: []diskDTO{}}, a.handleListDisks},
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/disks", ID: "createDisk", Tag: "disks", Summary: "Crea un disco (mkdisk)", Body: diskCreateReq{}, Resp: reports.MBRReport{}, Status: http.StatusCreated}, a.handleCreateDisk},
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/disks/{path}", ID: "getDisk", Tag: "disks", Summary: "Mapa del disco; {path} es la ruta del .mia escapada",
+			Query: []apidoc.Param{qPass}, Resp: reports.DiskReport{}}, a.handleGetDisk},
+		{apidoc.Op{Method: http.MethodDelete, Path: "/api/disks/{path}", ID: "deleteDisk", Tag: "disks", Summary: "Elimina el disco (rmdisk)", Resp: diskDeleteRes{}}, a.handleDeleteDisk},
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/disks/{path}/partitions", ID: "listPartitions", Tag: "disks", Summary: "Tabla de particiones",
+			Query: []apidoc.Param{qPass}, Resp: reports.MBRReport{}}, a.handleListPartitions},
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/disks/{path}/partitions", ID: "createPartition", Tag: "disks", Summary: "Crea una partición", Body: partReq{}, Resp: reports.MBRReport{}, Status: http.StatusCreated}, a.handleCreatePartition},
+		{apidoc.Op{Method: http.MethodPatch, Path: "/api/disks/{path}/partitions/{name}", ID: "resizePartition", Tag: "disks", Summary: "Redimensiona (add) o reubica (start) una partición", Body: partReq{}, Resp: reports.MBRReport{}}, a.handleResizePartition},
+		{apidoc.Op{Method: http.MethodDelete, Path: "/api/disks/{path}/partitions/{name}", ID: "deletePartition", Tag: "disks", Summary: "Elimina una partición",
+			Query: []apidoc.Param{{Name: "delete", Desc: "fast (defecto) | full"}}, Resp: reports.MBRReport{}}, a.handleDeletePartition},
+
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/fs/find", ID: "fsFind", Tag: "fs", Summary: "Hijos directos de una carpeta",
+			Query: []apidoc.Param{qID, {Name: "ruta", Desc: "carpeta; / por defecto"}}, Resp: fsFindResp{}}, a.handleFSFind},
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/fs/ls", ID: "fsLS", Tag: "fs", Summary: "Listado con permisos de la sesión",
+			Query: []apidoc.Param{qID, {Name: "ruta", Desc: "carpeta; / por defecto"}}, Resp: reports.LSReport{}}, a.handleFSLS},
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/fs/file", ID: "downloadFile", Tag: "fs", Summary: "Descarga un archivo (admite Range)",
+			Query: []apidoc.Param{qID, qRuta}, RespRaw: "application/octet-stream"}, a.handleFSFile},
+		{apidoc.Op{Method: http.MethodPut, Path: "/api/fs/file", ID: "uploadFile", Tag: "fs", Summary: "Crea o reemplaza un archivo con el cuerpo (201 si es nuevo)",
+			Query: []apidoc.Param{qID, qRuta, {Name: "r", Desc: "crea carpetas padre", Type: "boolean"}}, BodyRaw: "application/octet-stream", Resp: fsWriteRes{}, Status: http.StatusCreated}, a.handleFSFile},
+		{apidoc.Op{Method: http.MethodPost, Path: "/api/fs/file", ID: "uploadFileForm", Tag: "fs", Summary: "Sube un archivo multipart (campo file); ruta con / final usa su nombre",
+			Query: []apidoc.Param{qID, qRuta, {Name: "r", Desc: "crea carpetas padre", Type: "boolean"}}, BodyRaw: "multipart/form-data", Resp: fsWriteRes{}, Status: http.StatusCreated}, a.handleFSFile},
+		fsMut(http.MethodPost, "/api/fs/mkdir", "fsMkdir", "Crea una carpeta (p = padres)", a.handleFSMkdir),
+		fsMut(http.MethodDelete, "/api/fs/node", "fsRemove", "Elimina un archivo o carpeta", a.handleFSNode),
+		fsMut(http.MethodPost, "/api/fs/rename", "fsRename", "Renombra (name)", a.handleFSRename),
+		fsMut(http.MethodPost, "/api/fs/move", "fsMove", "Mueve a destino", a.handleFSMove),
+		fsMut(http.MethodPost, "/api/fs/copy", "fsCopy", "Copia a destino", a.handleFSCopy),
+		fsMut(http.MethodPost, "/api/fs/chmod", "fsChmod", "Cambia permisos (ugo, r)", a.handleFSChmod),
+		fsMut(http.MethodPost, "/api/fs/chown", "fsChown", "Cambia dueño (usuario, r)", a.handleFSChown),
+
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/events", ID: "events", Tag: "events", Summary: "Stream (text/event-stream) de cambios de la partición: create, delete, rename, chmod, modify y journal",
+			Query: []apidoc.Param{qID}, RespRaw: "text/event-stream"}, a.handleEvents},
+
+		{apidoc.Op{Method: http.MethodGet, Path: "/api/audit", ID: "audit", Tag: "audit", Summary: "Registro de auditoría de comandos y peticiones (solo root)",
+			Query: []apidoc.Param{
+				{Name: "user", Desc: "usuario de la sesión"},
+				{Name: "id", Desc: "ID montado"},
+				{Name: "cmd", Desc: "prefijo del comando, p. ej. mkdir o POST /api/fs"},
+				{Name: "source", Desc: "cmd | http"},
+				{Name: "status", Desc: "ok | error"},
+				{Name: "since", Desc: "desde (RFC 3339 o AAAA-MM-DD)"},
+				{Name: "until", Desc: "hasta (RFC 3339 o AAAA-MM-DD)"},
+				{Name: "limit", Desc: "últimos N registros", Type: "integer"},
+			}, Resp: []audit.Record{}}, a.handleAudit},
+	}
+}
+
+func openAPISpec(rs []route) map[string]any {
+	ops := make([]apidoc.Op, len(rs))
+	for i, rt := range rs {
+		ops[i] = rt.Op
+	}
+	return apidoc.Spec("GoDisk API", "1", ops, errorBody{})
+}
+
+func (a *App) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, openAPISpec(a.routes()))
+}
+
 func runHTTP(address string) error {
 	app := NewApp()
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/exec", app.handleExec)
-	mux.HandleFunc("/api/health", app.handleHealth)
-	mux.HandleFunc("/api/reports/mbr", app.handleReportMBR)
-	mux.HandleFunc("/api/reports/disk", app.handleReportDisk)
-	mux.HandleFunc("/api/reports/inode", app.handleReportInode)
-	mux.HandleFunc("/api/reports/inodes", app.handleReportInodes)
-	mux.HandleFunc("/api/reports/block", app.handleReportBlock)
-	mux.HandleFunc("/api/reports/bm_inode", app.handleReportBmInode)
-	mux.HandleFunc("/api/reports/bm_block", app.handleReportBmBlock)
-	mux.HandleFunc("/api/reports/tree", app.handleReportTree)
-	mux.HandleFunc("/api/reports/sb", app.handleReportSB)
-	mux.HandleFunc("/api/reports/file", app.handleReportFile)
-	mux.HandleFunc("/api/reports/ls", app.handleReportLS)
-	mux.HandleFunc("/api/login", app.handleLogin)
-	mux.HandleFunc("/api/logout", app.handleLogout)
-	mux.HandleFunc("/api/mounts", app.handleListMounts)
-	mux.HandleFunc("/api/fs/find", app.handleFSFind)
-	mux.HandleFunc("/api/fs/ls", app.handleFSLS)
-	mux.HandleFunc("/api/reports/journaling", app.handleReportJournaling)
+
+	// paths sólo sirve para distinguir 404 de 405 en las rutas de /api/.
+	paths := http.NewServeMux()
+	allow := map[string][]string{}
+	for _, rt := range app.routes() {
+		mux.HandleFunc(rt.Method+" "+rt.Path, rt.h)
+		if _, ok := allow[rt.Path]; !ok {
+			paths.HandleFunc(rt.Path, func(http.ResponseWriter, *http.Request) {})
+		}
+		allow[rt.Path] = append(allow[rt.Path], rt.Method)
+	}
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		if _, pat := paths.Handler(r); pat != "" {
+			w.Header().Set("Allow", strings.Join(allow[pat], ", "))
+			writeJSONError(w, http.StatusMethodNotAllowed, "método "+r.Method+" no permitido en "+r.URL.Path)
+			return
+		}
+		writeJSONError(w, http.StatusNotFound, "ruta no encontrada: "+r.URL.Path)
+	})
+
+	// Con SIGINT/SIGTERM se cancelan los contextos de las peticiones (corta
+	// los streams de /api/events), se espera a las que siguen en curso y se
+	// escriben las páginas sucias antes de salir.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{
+		Addr:        address,
+		Handler:     auditHTTP(mux),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
 	fmt.Println("HTTP API escuchando en", address)
-	return http.ListenAndServe(address, mux)
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := srv.Shutdown(shutCtx)
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if ferr := flushDirty(); ferr != nil {
+		return ferr
+	}
+	fmt.Println("HTTP API detenida")
+	return err
+}
+
+// auditWriter guarda el estado de la respuesta y, si es un error, el
+// principio del cuerpo para sacar el mensaje del sobre.
+type auditWriter struct {
+	http.ResponseWriter
+	status int
+	errBuf bytes.Buffer
+}
+
+func (w *auditWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status >= 400 && w.errBuf.Len() < 1024 {
+		w.errBuf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush mantiene funcionando /api/events a través del envoltorio.
+func (w *auditWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *auditWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// auditHTTP registra cada petición (salvo /api/health) en el log de
+// auditoría. Los cuerpos no se guardan: pueden llevar contraseñas.
+func auditHTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		before, _ := auth.Current()
+		aw := &auditWriter{ResponseWriter: w}
+		next.ServeHTTP(aw, r)
+		if aw.status == 0 {
+			aw.status = http.StatusOK
+		}
+
+		rec := audit.Record{
+			Time:       start,
+			Source:     audit.SourceHTTP,
+			Command:    r.Method + " " + r.URL.Path,
+			Args:       audit.RedactQuery(r.URL.Query()),
+			OK:         aw.status < 400,
+			Status:     aw.status,
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if s, ok := auth.Current(); ok {
+			rec.User, rec.ID = s.User, s.ID
+		} else if before != nil {
+			rec.User, rec.ID = before.User, before.ID
+		}
+		if id := strings.TrimSpace(r.URL.Query().Get("id")); id != "" {
+			rec.ID = id
+		}
+		if !rec.OK {
+			var env errorBody
+			if json.Unmarshal(aw.errBuf.Bytes(), &env) == nil && env.Error != "" {
+				rec.Error = env.Error
+			} else {
+				rec.Error = http.StatusText(aw.status)
+			}
+		}
+		if err := audit.Append(rec); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	})
 }
 
 // ======== AUTH ENDPOINTS ========
 
+type okRes struct {
+	OK bool `json:"ok"`
+}
+
 type LoginReq struct {
 	// Aceptamos ambos esquemas de nombres para compatibilidad
 	User string `json:"user"` // preferido
@@ -1004,7 +2171,7 @@ type LoginRes struct {
 
 func (a *App) handleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "solo POST", http.StatusMethodNotAllowed)
+		writeJSONError(w, http.StatusMethodNotAllowed, "solo POST")
 		return
 	}
 	var req LoginReq
@@ -1061,7 +2228,7 @@ func (a *App) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 func (a *App) handleLogout(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "solo POST", http.StatusMethodNotAllowed)
+		writeJSONError(w, http.StatusMethodNotAllowed, "solo POST")
 		return
 	}
 	a.mu.Lock()
@@ -1075,7 +2242,7 @@ func (a *App) handleLogout(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "no se pudo cerrar sesión")
 		return
 	}
-	writeJSON(w, map[string]any{"ok": true})
+	writeJSON(w, okRes{OK: true})
 }
 
 func runCLI() {
@@ -1102,14 +2269,25 @@ func runCLI() {
 	if err := scanner.Err(); err != nil {
 		fmt.Fprintln(os.Stderr, "Error leyendo la entrada:", err)
 	}
+	if err := diskio.SyncAll(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error sincronizando discos:", err)
+	}
 }
 
 // ---------------------- main ----------------------
 
 func main() {
 	httpAddr := flag.String("http", "", "inicia API HTTP en esta dirección (ej.: ':8080')")
+	openapi := flag.Bool("openapi", false, "imprime el documento OpenAPI de la API HTTP y termina")
 	flag.Parse()
 
+	if *openapi {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(openAPISpec((&App{}).routes()))
+		return
+	}
+
 	if strings.TrimSpace(*httpAddr) != "" {
 		if err := runHTTP(*httpAddr); err != nil {
 			fmt.Fprintln(os.Stderr, "Error servidor HTTP:", err)
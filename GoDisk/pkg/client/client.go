@@ -0,0 +1,152 @@
+// Package client es un cliente tipado de la API HTTP de GoDisk.
+//
+// Los tipos y métodos de client_gen.go se generan desde openapi.json (que a
+// su vez sale de la tabla de rutas del servidor); este archivo tiene sólo el
+// transporte. La sesión vive en el servidor, así que basta con llamar Login
+// antes de las operaciones que la requieren.
+package client
+
+//go:generate sh -c "cd ../.. && go run . -openapi > pkg/client/openapi.json"
+//go:generate go run ../../internal/apidoc/clientgen -in openapi.json -out client_gen.go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Client struct {
+	BaseURL string // p. ej. http://localhost:8080
+	HTTP    *http.Client
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTP: http.DefaultClient}
+}
+
+// APIError es una respuesta de error de la API ({"error", "code"}).
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("godisk: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+func jsonBody(v any) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// do hace la petición y decodifica la respuesta en out: JSON, o los bytes
+// tal cual si out es *[]byte.
+func (c *Client) do(ctx context.Context, method, path string, q url.Values, body io.Reader, contentType string, out any) error {
+	res, err := c.send(ctx, method, path, q, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if raw, ok := out.(*[]byte); ok {
+		*raw, err = io.ReadAll(res.Body)
+		return err
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// stream hace la petición y devuelve el cuerpo sin leer; lo cierra quien
+// llama (o se corta al cancelar ctx).
+func (c *Client) stream(ctx context.Context, method, path string, q url.Values) (io.ReadCloser, error) {
+	res, err := c.send(ctx, method, path, q, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// send hace la petición y convierte las respuestas >= 400 en *APIError.
+func (c *Client) send(ctx context.Context, method, path string, q url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := c.BaseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 400 {
+		defer res.Body.Close()
+		apiErr := &APIError{Status: res.StatusCode}
+		var env ErrorBody
+		if raw, _ := io.ReadAll(res.Body); json.Unmarshal(raw, &env) == nil && env.Error != "" {
+			apiErr.Code, apiErr.Message = env.Code, env.Error
+		} else {
+			apiErr.Message = strings.TrimSpace(string(raw))
+		}
+		return nil, apiErr
+	}
+	return res, nil
+}
+
+// SSE es un mensaje de un stream text/event-stream (p. ej. el de Events).
+type SSE struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// ReadSSE lee el siguiente mensaje de br, saltando comentarios y latidos.
+func ReadSSE(br *bufio.Reader) (SSE, error) {
+	var m SSE
+	var data []string
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return m, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if len(data) > 0 || m.Event != "" {
+				m.Data = strings.Join(data, "\n")
+				return m, nil
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, val, _ := strings.Cut(line, ":")
+		val = strings.TrimPrefix(val, " ")
+		switch field {
+		case "id":
+			m.ID = val
+		case "event":
+			m.Event = val
+		case "data":
+			data = append(data, val)
+		}
+	}
+}
@@ -0,0 +1,1170 @@
+// Code generated by clientgen from openapi.json; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+type BlockItem struct {
+	Dir      *DirBlockView  `json:"dir,omitempty"`
+	File     *FileBlockView `json:"file,omitempty"`
+	Index    int32          `json:"index"`
+	Ptr      *PtrBlockView  `json:"ptr,omitempty"`
+	RefCount int64          `json:"refCount"`
+	Type     string         `json:"type"`
+}
+
+type BlockReport struct {
+	BlockSize int32       `json:"blockSize"`
+	Blocks    []BlockItem `json:"blocks"`
+	Count     int32       `json:"count"`
+	DiskPath  string      `json:"diskPath"`
+	ID        string      `json:"id"`
+	Kind      string      `json:"kind"`
+	Used      int64       `json:"used"`
+}
+
+type BlocksExpanded struct {
+	Direct         []int32            `json:"direct"`
+	DoubleIndirect *DoubleIndirectExp `json:"doubleIndirect,omitempty"`
+	Indirect       *IndirectExpanded  `json:"indirect,omitempty"`
+}
+
+type DirBlockView struct {
+	Entries []DirEntry `json:"entries"`
+}
+
+type DirEntry struct {
+	Inode int32  `json:"inode"`
+	Name  string `json:"name"`
+}
+
+type DiskCreateReq struct {
+	Encrypt    bool   `json:"encrypt"`
+	Fit        string `json:"fit"`
+	Passphrase string `json:"passphrase"`
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	Table      string `json:"table"`
+	Unit       string `json:"unit"`
+}
+
+type DiskDTO struct {
+	DiskPath  string     `json:"diskPath"`
+	Encrypted bool       `json:"encrypted"`
+	Error     string     `json:"error,omitempty"`
+	Exists    bool       `json:"exists"`
+	Mbr       *MBRReport `json:"mbr,omitempty"`
+}
+
+type DiskDeleteRes struct {
+	DiskPath string `json:"diskPath"`
+	OK       bool   `json:"ok"`
+	Purged   int64  `json:"purged"`
+}
+
+type DiskReport struct {
+	AllocatedBytes int64         `json:"allocatedBytes"`
+	DiskPath       string        `json:"diskPath"`
+	Encrypted      bool          `json:"encrypted"`
+	Extended       *ExtendedView `json:"extended,omitempty"`
+	Kind           string        `json:"kind"`
+	MbrBytes       int64         `json:"mbrBytes"`
+	Segments       []DiskSegment `json:"segments"`
+	SizeBytes      int64         `json:"sizeBytes"`
+	Table          string        `json:"table"`
+}
+
+type DiskSegment struct {
+	End     int64   `json:"end"`
+	Kind    string  `json:"kind"`
+	Label   string  `json:"label"`
+	Percent float64 `json:"percent"`
+	Size    int64   `json:"size"`
+	Start   int64   `json:"start"`
+}
+
+type DoubleIndirectExp struct {
+	Block  int32      `json:"block"`
+	Groups []PtrGroup `json:"groups"`
+}
+
+type ErrorBody struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+type ExecReq struct {
+	Script string `json:"script"`
+}
+
+type ExecRes struct {
+	Output string `json:"output"`
+}
+
+type ExtendedView struct {
+	Segments []DiskSegment `json:"segments"`
+	Size     int64         `json:"size"`
+	Start    int64         `json:"start"`
+}
+
+type FileBlockView struct {
+	Compressed bool   `json:"compressed,omitempty"`
+	Preview    string `json:"preview"`
+	Size       int64  `json:"size"`
+}
+
+type FormatReq struct {
+	Backup   bool   `json:"backup,omitempty"`
+	Bitmap   string `json:"bitmap"`
+	Checksum bool   `json:"checksum"`
+	Cow      bool   `json:"cow,omitempty"`
+	Fs       string `json:"fs"`
+}
+
+type FormatRes struct {
+	Fs string `json:"fs"`
+	ID string `json:"id"`
+	OK bool   `json:"ok"`
+}
+
+type FragItem struct {
+	Blocks     []int32 `json:"blocks"`
+	Extents    int64   `json:"extents"`
+	Fragmented bool    `json:"fragmented"`
+	Inode      int32   `json:"inode"`
+	Path       string  `json:"path"`
+	Size       int32   `json:"size"`
+	Type       string  `json:"type"`
+}
+
+type FragReport struct {
+	DiskPath          string     `json:"diskPath"`
+	Files             int64      `json:"files"`
+	Fit               string     `json:"fit"`
+	FragmentedFiles   int64      `json:"fragmentedFiles"`
+	FragmentedPercent float64    `json:"fragmentedPercent"`
+	FreeBlocks        int32      `json:"freeBlocks"`
+	FreeRuns          int64      `json:"freeRuns"`
+	ID                string     `json:"id"`
+	Items             []FragItem `json:"items"`
+	Kind              string     `json:"kind"`
+	LargestFreeRun    int32      `json:"largestFreeRun"`
+	Runs              []FragRun  `json:"runs"`
+}
+
+type FragRun struct {
+	Length int32 `json:"length"`
+	Start  int32 `json:"start"`
+}
+
+type FsFindResp struct {
+	Dirs  []string `json:"dirs"`
+	Files []string `json:"files"`
+	Ruta  string   `json:"ruta"`
+}
+
+type FsMutReq struct {
+	Destino string `json:"destino,omitempty"`
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	P       bool   `json:"p,omitempty"`
+	R       bool   `json:"r,omitempty"`
+	Ruta    string `json:"ruta"`
+	Ugo     string `json:"ugo,omitempty"`
+	Usuario string `json:"usuario,omitempty"`
+}
+
+type FsMutRes struct {
+	OK   bool   `json:"ok"`
+	Op   string `json:"op"`
+	Ruta string `json:"ruta"`
+}
+
+type FsWriteRes struct {
+	Ruta string `json:"ruta"`
+	Size int64  `json:"size"`
+}
+
+type GPTView struct {
+	BackupOffset  int64  `json:"backupOffset"`
+	DiskGuid      string `json:"diskGuid"`
+	Entries       int64  `json:"entries"`
+	EntriesCrc    string `json:"entriesCrc"`
+	EntrySize     int64  `json:"entrySize"`
+	FirstUsable   int64  `json:"firstUsable"`
+	HeaderCrc     string `json:"headerCrc"`
+	LastUsable    int64  `json:"lastUsable"`
+	PrimaryOffset int64  `json:"primaryOffset"`
+}
+
+type IndirectExpanded struct {
+	Block    int32   `json:"block"`
+	Pointers []int32 `json:"pointers"`
+}
+
+type InodeMini struct {
+	BlocksUsed int32  `json:"blocksUsed"`
+	Index      int32  `json:"index"`
+	RawType    int32  `json:"rawType"`
+	Size       int64  `json:"size"`
+	Type       string `json:"type"`
+}
+
+type InodeReport struct {
+	Atime      string  `json:"atime"`
+	Blocks     []int32 `json:"blocks"`
+	BlocksUsed int64   `json:"blocksUsed"`
+	Compressed bool    `json:"compressed"`
+	Ctime      string  `json:"ctime"`
+	DiskPath   string  `json:"diskPath"`
+	GID        int32   `json:"gid"`
+	ID         string  `json:"id"`
+	Index      int32   `json:"index"`
+	Kind       string  `json:"kind"`
+	Mtime      string  `json:"mtime"`
+	Perm       string  `json:"perm"`
+	PermRaw    []byte  `json:"permRaw"`
+	RawType    int32   `json:"rawType"`
+	Size       int32   `json:"size"`
+	StoredSize int32   `json:"storedSize"`
+	Type       string  `json:"type"`
+	UID        int32   `json:"uid"`
+}
+
+type InodesReport struct {
+	Count    int32       `json:"count"`
+	DiskPath string      `json:"diskPath"`
+	ID       string      `json:"id"`
+	Items    []InodeMini `json:"items"`
+	Kind     string      `json:"kind"`
+}
+
+type JournalRow struct {
+	Content   string `json:"content"`
+	Count     int32  `json:"count"`
+	Date      string `json:"date"`
+	GID       int32  `json:"gid,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	UID       int32  `json:"uid,omitempty"`
+	User      string `json:"user,omitempty"`
+}
+
+type LSItem struct {
+	Atime   string `json:"atime"`
+	Ctime   string `json:"ctime"`
+	GID     int32  `json:"gid"`
+	Group   string `json:"group"`
+	Inode   int32  `json:"inode"`
+	Mtime   string `json:"mtime"`
+	Name    string `json:"name"`
+	Owner   string `json:"owner"`
+	Perm    string `json:"perm"`
+	RawType int32  `json:"rawType"`
+	Size    int32  `json:"size"`
+	Type    string `json:"type"`
+	UID     int32  `json:"uid"`
+}
+
+type LSReport struct {
+	Dir      string   `json:"dir"`
+	DiskPath string   `json:"diskPath"`
+	ID       string   `json:"id"`
+	Items    []LSItem `json:"items"`
+	Kind     string   `json:"kind"`
+}
+
+type LoginReq struct {
+	ID   string `json:"id"`
+	Pass string `json:"pass"`
+	Pwd  string `json:"pwd"`
+	User string `json:"user"`
+	Usr  string `json:"usr"`
+}
+
+type LoginRes struct {
+	ID     string `json:"id"`
+	IsRoot bool   `json:"isRoot"`
+	OK     bool   `json:"ok"`
+	Output string `json:"output,omitempty"`
+	User   string `json:"user"`
+}
+
+type MBRPartReport struct {
+	Correlative int64  `json:"correlative,omitempty"`
+	Fit         string `json:"fit"`
+	ID          string `json:"id,omitempty"`
+	Index       int64  `json:"index"`
+	Name        string `json:"name"`
+	RawFit      int32  `json:"rawFit"`
+	RawStatus   int32  `json:"rawStatus"`
+	RawType     int32  `json:"rawType"`
+	Size        int64  `json:"size"`
+	Start       int64  `json:"start"`
+	Status      string `json:"status"`
+	Type        string `json:"type"`
+	Usable      bool   `json:"usable"`
+}
+
+type MBRReport struct {
+	Created    string          `json:"created"`
+	DiskPath   string          `json:"diskPath"`
+	Fit        string          `json:"fit"`
+	Gpt        *GPTView        `json:"gpt,omitempty"`
+	Kind       string          `json:"kind"`
+	Partitions []MBRPartReport `json:"partitions"`
+	RawFit     int32           `json:"rawFit"`
+	Signature  int64           `json:"signature"`
+	SizeBytes  int64           `json:"sizeBytes"`
+	Table      string          `json:"table"`
+}
+
+type MountDTO struct {
+	DiskPath string `json:"diskPath"`
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Size     int64  `json:"size"`
+	Start    int64  `json:"start"`
+}
+
+type MountReq struct {
+	Name       string `json:"name"`
+	Passphrase string `json:"passphrase"`
+	Path       string `json:"path"`
+}
+
+type OkRes struct {
+	OK bool `json:"ok"`
+}
+
+type PartReq struct {
+	Add        int64  `json:"add"`
+	Delete     string `json:"delete"`
+	Fit        string `json:"fit"`
+	Name       string `json:"name"`
+	Passphrase string `json:"passphrase"`
+	Size       int64  `json:"size"`
+	Start      string `json:"start"`
+	Type       string `json:"type"`
+	Unit       string `json:"unit"`
+}
+
+type PtrBlockView struct {
+	Pointers []int32 `json:"pointers"`
+}
+
+type PtrGroup struct {
+	Block    int32   `json:"block"`
+	Pointers []int32 `json:"pointers"`
+}
+
+type Record struct {
+	Args       []string  `json:"args,omitempty"`
+	Command    string    `json:"command"`
+	DurationMs float64   `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+	ID         string    `json:"id,omitempty"`
+	OK         bool      `json:"ok"`
+	Source     string    `json:"source"`
+	Status     int64     `json:"status,omitempty"`
+	Time       time.Time `json:"time"`
+	User       string    `json:"user,omitempty"`
+}
+
+type SBReport struct {
+	BitmapFormat     string      `json:"bitmapFormat"`
+	BitmapFreeBlocks int64       `json:"bitmapFreeBlocks"`
+	BitmapFreeInodes int64       `json:"bitmapFreeInodes"`
+	BitmapUsedBlocks int64       `json:"bitmapUsedBlocks"`
+	BitmapUsedInodes int64       `json:"bitmapUsedInodes"`
+	BlockSize        int32       `json:"blockSize"`
+	BlockStart       int64       `json:"blockStart"`
+	BlocksCount      int32       `json:"blocksCount"`
+	BmBlockStart     int64       `json:"bmBlockStart"`
+	BmInodeStart     int64       `json:"bmInodeStart"`
+	Checksums        bool        `json:"checksums"`
+	Copies           []SuperCopy `json:"copies"`
+	DiskPath         string      `json:"diskPath"`
+	FreeBlocks       int32       `json:"freeBlocks"`
+	FreeInodes       int32       `json:"freeInodes"`
+	ID               string      `json:"id"`
+	InodeSize        int32       `json:"inodeSize"`
+	InodeTableStart  int64       `json:"inodeTableStart"`
+	InodesCount      int32       `json:"inodesCount"`
+	JournalOwner     bool        `json:"journalOwner"`
+	Kind             string      `json:"kind"`
+	Refcounts        bool        `json:"refcounts"`
+	Source           string      `json:"source"`
+}
+
+type SuperCopy struct {
+	Index  int64 `json:"index"`
+	Offset int64 `json:"offset"`
+	OK     bool  `json:"ok"`
+}
+
+type TreeBlockCard struct {
+	Dir   *TreeDirData  `json:"dir,omitempty"`
+	File  *TreeFileData `json:"file,omitempty"`
+	Index int32         `json:"index"`
+	Type  string        `json:"type"`
+}
+
+type TreeDirData struct {
+	Entries []TreeDirEntry `json:"entries"`
+}
+
+type TreeDirEntry struct {
+	Inode int32  `json:"inode"`
+	Name  string `json:"name"`
+}
+
+type TreeEdge struct {
+	Child  int32  `json:"child"`
+	Name   string `json:"name"`
+	Parent int32  `json:"parent"`
+}
+
+type TreeFileData struct {
+	Preview string `json:"preview,omitempty"`
+	Size    int32  `json:"size"`
+}
+
+type TreeInode struct {
+	Blocks      BlocksExpanded  `json:"blocks"`
+	BlocksFlat  []int32         `json:"blocksFlat"`
+	DirectCards []TreeBlockCard `json:"directCards"`
+	GID         int32           `json:"gid"`
+	Index       int32           `json:"index"`
+	Perm        string          `json:"perm"`
+	RawType     int32           `json:"rawType"`
+	Size        int32           `json:"size"`
+	Type        string          `json:"type"`
+	UID         int32           `json:"uid"`
+}
+
+type TreeReport struct {
+	BlockSize  int32       `json:"blockSize"`
+	Blocks     int32       `json:"blocks"`
+	BlocksUsed []int32     `json:"blocksUsed"`
+	DiskPath   string      `json:"diskPath"`
+	Edges      []TreeEdge  `json:"edges"`
+	ID         string      `json:"id"`
+	Inodes     int32       `json:"inodes"`
+	Kind       string      `json:"kind"`
+	Nodes      []TreeInode `json:"nodes"`
+	UsedBlocks int64       `json:"usedBlocks"`
+	UsedInodes int64       `json:"usedInodes"`
+}
+
+type UnmountRes struct {
+	ID string `json:"id"`
+	OK bool   `json:"ok"`
+}
+
+// AuditQuery son los parámetros de query de Audit.
+type AuditQuery struct {
+	User   string // usuario de la sesión
+	ID     string // ID montado
+	Cmd    string // prefijo del comando, p. ej. mkdir o POST /api/fs
+	Source string // cmd | http
+	Status string // ok | error
+	Since  string // desde (RFC 3339 o AAAA-MM-DD)
+	Until  string // hasta (RFC 3339 o AAAA-MM-DD)
+	Limit  int64  // últimos N registros
+}
+
+// Audit: GET /api/audit
+// Registro de auditoría de comandos y peticiones (solo root).
+func (c *Client) Audit(ctx context.Context, q AuditQuery) ([]Record, error) {
+	var out []Record
+	v := url.Values{}
+	if q.User != "" {
+		v.Set("user", q.User)
+	}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Cmd != "" {
+		v.Set("cmd", q.Cmd)
+	}
+	if q.Source != "" {
+		v.Set("source", q.Source)
+	}
+	if q.Status != "" {
+		v.Set("status", q.Status)
+	}
+	if q.Since != "" {
+		v.Set("since", q.Since)
+	}
+	if q.Until != "" {
+		v.Set("until", q.Until)
+	}
+	if q.Limit != 0 {
+		v.Set("limit", fmt.Sprint(q.Limit))
+	}
+	err := c.do(ctx, "GET", "/api/audit", v, nil, "", &out)
+	return out, err
+}
+
+// ListDisks: GET /api/disks
+// Discos del catálogo con su tabla.
+func (c *Client) ListDisks(ctx context.Context) ([]DiskDTO, error) {
+	var out []DiskDTO
+	err := c.do(ctx, "GET", "/api/disks", nil, nil, "", &out)
+	return out, err
+}
+
+// CreateDisk: POST /api/disks
+// Crea un disco (mkdisk).
+func (c *Client) CreateDisk(ctx context.Context, body DiskCreateReq) (MBRReport, error) {
+	var out MBRReport
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/disks", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// GetDiskQuery son los parámetros de query de GetDisk.
+type GetDiskQuery struct {
+	Passphrase string // frase del disco si está cifrado
+}
+
+// GetDisk: GET /api/disks/{path}
+// Mapa del disco; {path} es la ruta del .mia escapada.
+func (c *Client) GetDisk(ctx context.Context, path string, q GetDiskQuery) (DiskReport, error) {
+	var out DiskReport
+	v := url.Values{}
+	if q.Passphrase != "" {
+		v.Set("passphrase", q.Passphrase)
+	}
+	err := c.do(ctx, "GET", "/api/disks/"+url.PathEscape(path), v, nil, "", &out)
+	return out, err
+}
+
+// DeleteDisk: DELETE /api/disks/{path}
+// Elimina el disco (rmdisk).
+func (c *Client) DeleteDisk(ctx context.Context, path string) (DiskDeleteRes, error) {
+	var out DiskDeleteRes
+	err := c.do(ctx, "DELETE", "/api/disks/"+url.PathEscape(path), nil, nil, "", &out)
+	return out, err
+}
+
+// ListPartitionsQuery son los parámetros de query de ListPartitions.
+type ListPartitionsQuery struct {
+	Passphrase string // frase del disco si está cifrado
+}
+
+// ListPartitions: GET /api/disks/{path}/partitions
+// Tabla de particiones.
+func (c *Client) ListPartitions(ctx context.Context, path string, q ListPartitionsQuery) (MBRReport, error) {
+	var out MBRReport
+	v := url.Values{}
+	if q.Passphrase != "" {
+		v.Set("passphrase", q.Passphrase)
+	}
+	err := c.do(ctx, "GET", "/api/disks/"+url.PathEscape(path)+"/partitions", v, nil, "", &out)
+	return out, err
+}
+
+// CreatePartition: POST /api/disks/{path}/partitions
+// Crea una partición.
+func (c *Client) CreatePartition(ctx context.Context, path string, body PartReq) (MBRReport, error) {
+	var out MBRReport
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/disks/"+url.PathEscape(path)+"/partitions", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// ResizePartition: PATCH /api/disks/{path}/partitions/{name}
+// Redimensiona (add) o reubica (start) una partición.
+func (c *Client) ResizePartition(ctx context.Context, path string, name string, body PartReq) (MBRReport, error) {
+	var out MBRReport
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "PATCH", "/api/disks/"+url.PathEscape(path)+"/partitions/"+url.PathEscape(name), nil, rd, "application/json", &out)
+	return out, err
+}
+
+// DeletePartitionQuery son los parámetros de query de DeletePartition.
+type DeletePartitionQuery struct {
+	Delete string // fast (defecto) | full
+}
+
+// DeletePartition: DELETE /api/disks/{path}/partitions/{name}
+// Elimina una partición.
+func (c *Client) DeletePartition(ctx context.Context, path string, name string, q DeletePartitionQuery) (MBRReport, error) {
+	var out MBRReport
+	v := url.Values{}
+	if q.Delete != "" {
+		v.Set("delete", q.Delete)
+	}
+	err := c.do(ctx, "DELETE", "/api/disks/"+url.PathEscape(path)+"/partitions/"+url.PathEscape(name), v, nil, "", &out)
+	return out, err
+}
+
+// EventsQuery son los parámetros de query de Events.
+type EventsQuery struct {
+	ID string // ID montado
+}
+
+// Events: GET /api/events
+// Stream (text/event-stream) de cambios de la partición: create, delete, rename, chmod, modify y journal.
+func (c *Client) Events(ctx context.Context, q EventsQuery) (io.ReadCloser, error) {
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	return c.stream(ctx, "GET", "/api/events", v)
+}
+
+// Exec: POST /api/exec
+// Ejecuta un script de comandos.
+func (c *Client) Exec(ctx context.Context, body ExecReq) (ExecRes, error) {
+	var out ExecRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/exec", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// FsChmod: POST /api/fs/chmod
+// Cambia permisos (ugo, r).
+func (c *Client) FsChmod(ctx context.Context, body FsMutReq) (FsMutRes, error) {
+	var out FsMutRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/fs/chmod", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// FsChown: POST /api/fs/chown
+// Cambia dueño (usuario, r).
+func (c *Client) FsChown(ctx context.Context, body FsMutReq) (FsMutRes, error) {
+	var out FsMutRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/fs/chown", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// FsCopy: POST /api/fs/copy
+// Copia a destino.
+func (c *Client) FsCopy(ctx context.Context, body FsMutReq) (FsMutRes, error) {
+	var out FsMutRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/fs/copy", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// DownloadFileQuery son los parámetros de query de DownloadFile.
+type DownloadFileQuery struct {
+	ID   string // ID montado
+	Ruta string // ruta absoluta dentro de la partición
+}
+
+// DownloadFile: GET /api/fs/file
+// Descarga un archivo (admite Range).
+func (c *Client) DownloadFile(ctx context.Context, q DownloadFileQuery) ([]byte, error) {
+	var out []byte
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	err := c.do(ctx, "GET", "/api/fs/file", v, nil, "", &out)
+	return out, err
+}
+
+// UploadFileFormQuery son los parámetros de query de UploadFileForm.
+type UploadFileFormQuery struct {
+	ID   string // ID montado
+	Ruta string // ruta absoluta dentro de la partición
+	R    bool   // crea carpetas padre
+}
+
+// UploadFileForm: POST /api/fs/file
+// Sube un archivo multipart (campo file); ruta con / final usa su nombre.
+func (c *Client) UploadFileForm(ctx context.Context, q UploadFileFormQuery, body io.Reader, contentType string) (FsWriteRes, error) {
+	var out FsWriteRes
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	if q.R {
+		v.Set("r", "true")
+	}
+	err := c.do(ctx, "POST", "/api/fs/file", v, body, contentType, &out)
+	return out, err
+}
+
+// UploadFileQuery son los parámetros de query de UploadFile.
+type UploadFileQuery struct {
+	ID   string // ID montado
+	Ruta string // ruta absoluta dentro de la partición
+	R    bool   // crea carpetas padre
+}
+
+// UploadFile: PUT /api/fs/file
+// Crea o reemplaza un archivo con el cuerpo (201 si es nuevo).
+func (c *Client) UploadFile(ctx context.Context, q UploadFileQuery, body io.Reader, contentType string) (FsWriteRes, error) {
+	var out FsWriteRes
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	if q.R {
+		v.Set("r", "true")
+	}
+	err := c.do(ctx, "PUT", "/api/fs/file", v, body, contentType, &out)
+	return out, err
+}
+
+// FsFindQuery son los parámetros de query de FsFind.
+type FsFindQuery struct {
+	ID   string // ID montado
+	Ruta string // carpeta; / por defecto
+}
+
+// FsFind: GET /api/fs/find
+// Hijos directos de una carpeta.
+func (c *Client) FsFind(ctx context.Context, q FsFindQuery) (FsFindResp, error) {
+	var out FsFindResp
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	err := c.do(ctx, "GET", "/api/fs/find", v, nil, "", &out)
+	return out, err
+}
+
+// FsLSQuery son los parámetros de query de FsLS.
+type FsLSQuery struct {
+	ID   string // ID montado
+	Ruta string // carpeta; / por defecto
+}
+
+// FsLS: GET /api/fs/ls
+// Listado con permisos de la sesión.
+func (c *Client) FsLS(ctx context.Context, q FsLSQuery) (LSReport, error) {
+	var out LSReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	err := c.do(ctx, "GET", "/api/fs/ls", v, nil, "", &out)
+	return out, err
+}
+
+// FsMkdir: POST /api/fs/mkdir
+// Crea una carpeta (p = padres).
+func (c *Client) FsMkdir(ctx context.Context, body FsMutReq) (FsMutRes, error) {
+	var out FsMutRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/fs/mkdir", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// FsMove: POST /api/fs/move
+// Mueve a destino.
+func (c *Client) FsMove(ctx context.Context, body FsMutReq) (FsMutRes, error) {
+	var out FsMutRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/fs/move", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// FsRemoveQuery son los parámetros de query de FsRemove.
+type FsRemoveQuery struct {
+	ID   string // ID montado
+	Ruta string // ruta absoluta dentro de la partición
+}
+
+// FsRemove: DELETE /api/fs/node
+// Elimina un archivo o carpeta.
+func (c *Client) FsRemove(ctx context.Context, q FsRemoveQuery) (FsMutRes, error) {
+	var out FsMutRes
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	err := c.do(ctx, "DELETE", "/api/fs/node", v, nil, "", &out)
+	return out, err
+}
+
+// FsRename: POST /api/fs/rename
+// Renombra (name).
+func (c *Client) FsRename(ctx context.Context, body FsMutReq) (FsMutRes, error) {
+	var out FsMutRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/fs/rename", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// Health: GET /api/health
+// Responde ok si el servidor está vivo.
+func (c *Client) Health(ctx context.Context) ([]byte, error) {
+	var out []byte
+	err := c.do(ctx, "GET", "/api/health", nil, nil, "", &out)
+	return out, err
+}
+
+// Login: POST /api/login
+// Inicia sesión.
+func (c *Client) Login(ctx context.Context, body LoginReq) (LoginRes, error) {
+	var out LoginRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/login", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// Logout: POST /api/logout
+// Cierra la sesión.
+func (c *Client) Logout(ctx context.Context) (OkRes, error) {
+	var out OkRes
+	err := c.do(ctx, "POST", "/api/logout", nil, nil, "", &out)
+	return out, err
+}
+
+// ListMounts: GET /api/mounts
+// Particiones montadas.
+func (c *Client) ListMounts(ctx context.Context) ([]MountDTO, error) {
+	var out []MountDTO
+	err := c.do(ctx, "GET", "/api/mounts", nil, nil, "", &out)
+	return out, err
+}
+
+// Mount: POST /api/mounts
+// Monta una partición.
+func (c *Client) Mount(ctx context.Context, body MountReq) (MountDTO, error) {
+	var out MountDTO
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/mounts", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// Unmount: DELETE /api/mounts/{id}
+// Desmonta un ID.
+func (c *Client) Unmount(ctx context.Context, id string) (UnmountRes, error) {
+	var out UnmountRes
+	err := c.do(ctx, "DELETE", "/api/mounts/"+url.PathEscape(id), nil, nil, "", &out)
+	return out, err
+}
+
+// Format: POST /api/mounts/{id}/format
+// Formatea (mkfs) un ID montado.
+func (c *Client) Format(ctx context.Context, id string, body FormatReq) (FormatRes, error) {
+	var out FormatRes
+	rd, err := jsonBody(body)
+	if err != nil {
+		return out, err
+	}
+	err = c.do(ctx, "POST", "/api/mounts/"+url.PathEscape(id)+"/format", nil, rd, "application/json", &out)
+	return out, err
+}
+
+// OpenAPI: GET /api/openapi.json
+// Este documento.
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	var out json.RawMessage
+	err := c.do(ctx, "GET", "/api/openapi.json", nil, nil, "", &out)
+	return out, err
+}
+
+// ReportBlockQuery son los parámetros de query de ReportBlock.
+type ReportBlockQuery struct {
+	ID string // ID montado
+}
+
+// ReportBlock: GET /api/reports/block
+// Bloques usados.
+func (c *Client) ReportBlock(ctx context.Context, q ReportBlockQuery) (BlockReport, error) {
+	var out BlockReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/block", v, nil, "", &out)
+	return out, err
+}
+
+// ReportBmBlockQuery son los parámetros de query de ReportBmBlock.
+type ReportBmBlockQuery struct {
+	ID string // ID montado
+}
+
+// ReportBmBlock: GET /api/reports/bm_block
+// Bitmap de bloques en texto.
+func (c *Client) ReportBmBlock(ctx context.Context, q ReportBmBlockQuery) ([]byte, error) {
+	var out []byte
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/bm_block", v, nil, "", &out)
+	return out, err
+}
+
+// ReportBmInodeQuery son los parámetros de query de ReportBmInode.
+type ReportBmInodeQuery struct {
+	ID string // ID montado
+}
+
+// ReportBmInode: GET /api/reports/bm_inode
+// Bitmap de inodos en texto.
+func (c *Client) ReportBmInode(ctx context.Context, q ReportBmInodeQuery) ([]byte, error) {
+	var out []byte
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/bm_inode", v, nil, "", &out)
+	return out, err
+}
+
+// ReportDiskQuery son los parámetros de query de ReportDisk.
+type ReportDiskQuery struct {
+	ID string // ID montado
+}
+
+// ReportDisk: GET /api/reports/disk
+// Mapa del disco del ID.
+func (c *Client) ReportDisk(ctx context.Context, q ReportDiskQuery) (DiskReport, error) {
+	var out DiskReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/disk", v, nil, "", &out)
+	return out, err
+}
+
+// ReportFileQuery son los parámetros de query de ReportFile.
+type ReportFileQuery struct {
+	ID   string // ID montado
+	Ruta string // ruta absoluta dentro de la partición
+}
+
+// ReportFile: GET /api/reports/file
+// Contenido de un archivo (admite Range).
+func (c *Client) ReportFile(ctx context.Context, q ReportFileQuery) ([]byte, error) {
+	var out []byte
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	err := c.do(ctx, "GET", "/api/reports/file", v, nil, "", &out)
+	return out, err
+}
+
+// ReportFragQuery son los parámetros de query de ReportFrag.
+type ReportFragQuery struct {
+	ID string // ID montado
+}
+
+// ReportFrag: GET /api/reports/frag
+// Fragmentación.
+func (c *Client) ReportFrag(ctx context.Context, q ReportFragQuery) (FragReport, error) {
+	var out FragReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/frag", v, nil, "", &out)
+	return out, err
+}
+
+// ReportInodeQuery son los parámetros de query de ReportInode.
+type ReportInodeQuery struct {
+	ID   string // ID montado
+	Ruta string // ruta a inspeccionar; vacía = todos
+}
+
+// ReportInode: GET /api/reports/inode
+// Inodos de una ruta (o todos).
+func (c *Client) ReportInode(ctx context.Context, q ReportInodeQuery) (InodeReport, error) {
+	var out InodeReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	err := c.do(ctx, "GET", "/api/reports/inode", v, nil, "", &out)
+	return out, err
+}
+
+// ReportInodesQuery son los parámetros de query de ReportInodes.
+type ReportInodesQuery struct {
+	ID  string // ID montado
+	Max int64  // máximo de inodos
+}
+
+// ReportInodes: GET /api/reports/inodes
+// Tabla de inodos usados.
+func (c *Client) ReportInodes(ctx context.Context, q ReportInodesQuery) (InodesReport, error) {
+	var out InodesReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Max != 0 {
+		v.Set("max", fmt.Sprint(q.Max))
+	}
+	err := c.do(ctx, "GET", "/api/reports/inodes", v, nil, "", &out)
+	return out, err
+}
+
+// ReportJournalingQuery son los parámetros de query de ReportJournaling.
+type ReportJournalingQuery struct {
+	ID string // ID montado; por defecto el de la sesión
+}
+
+// ReportJournaling: GET /api/reports/journaling
+// Journal de la partición de la sesión.
+func (c *Client) ReportJournaling(ctx context.Context, q ReportJournalingQuery) ([]JournalRow, error) {
+	var out []JournalRow
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/journaling", v, nil, "", &out)
+	return out, err
+}
+
+// ReportLSQuery son los parámetros de query de ReportLS.
+type ReportLSQuery struct {
+	ID   string // ID montado
+	Ruta string // carpeta; / por defecto
+}
+
+// ReportLS: GET /api/reports/ls
+// Listado de una carpeta.
+func (c *Client) ReportLS(ctx context.Context, q ReportLSQuery) (LSReport, error) {
+	var out LSReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Ruta != "" {
+		v.Set("ruta", q.Ruta)
+	}
+	err := c.do(ctx, "GET", "/api/reports/ls", v, nil, "", &out)
+	return out, err
+}
+
+// ReportMBRQuery son los parámetros de query de ReportMBR.
+type ReportMBRQuery struct {
+	ID string // ID montado
+}
+
+// ReportMBR: GET /api/reports/mbr
+// Tabla de particiones del disco del ID.
+func (c *Client) ReportMBR(ctx context.Context, q ReportMBRQuery) (MBRReport, error) {
+	var out MBRReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/mbr", v, nil, "", &out)
+	return out, err
+}
+
+// ReportSBQuery son los parámetros de query de ReportSB.
+type ReportSBQuery struct {
+	ID string // ID montado
+}
+
+// ReportSB: GET /api/reports/sb
+// Superbloque.
+func (c *Client) ReportSB(ctx context.Context, q ReportSBQuery) (SBReport, error) {
+	var out SBReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	err := c.do(ctx, "GET", "/api/reports/sb", v, nil, "", &out)
+	return out, err
+}
+
+// ReportTreeQuery son los parámetros de query de ReportTree.
+type ReportTreeQuery struct {
+	ID     string // ID montado
+	Format string // json (defecto) | html
+}
+
+// ReportTree: GET /api/reports/tree
+// Árbol de inodos y bloques.
+func (c *Client) ReportTree(ctx context.Context, q ReportTreeQuery) (TreeReport, error) {
+	var out TreeReport
+	v := url.Values{}
+	if q.ID != "" {
+		v.Set("id", q.ID)
+	}
+	if q.Format != "" {
+		v.Set("format", q.Format)
+	}
+	err := c.do(ctx, "GET", "/api/reports/tree", v, nil, "", &out)
+	return out, err
+}